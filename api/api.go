@@ -2,19 +2,30 @@ package api
 
 import (
 	"github.com/filecoin-project/venus-wallet/common"
+	"github.com/filecoin-project/venus-wallet/storage/wallet"
 	wallet_api "github.com/filecoin-project/venus/venus-shared/api/wallet"
 	"go.uber.org/fx"
 )
 
 type IFullAPI interface {
 	common.ICommon
-	wallet_api.ILocalWallet
+	common.IRecord
+	common.INamedToken
+	wallet.ILocalWallet
 	wallet_api.IWalletEvent
+	wallet.IApproval
+	wallet.IQuorum
+	wallet.IConfigReload
 }
 
 type FullAPI struct {
 	fx.In
 	common.ICommon
-	wallet_api.ILocalWallet
+	common.IRecord
+	common.INamedToken
+	wallet.ILocalWallet
 	wallet_api.IWalletEvent
+	wallet.IApproval
+	wallet.IQuorum
+	wallet.IConfigReload
 }
@@ -0,0 +1,234 @@
+package api
+
+import (
+	"context"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-jsonrpc/auth"
+	shared "github.com/filecoin-project/venus/venus-shared/api/wallet"
+	"github.com/filecoin-project/venus/venus-shared/types"
+
+	"github.com/filecoin-project/venus-wallet/storage"
+	"github.com/filecoin-project/venus-wallet/storage/wallet"
+)
+
+// the structs below follow the layout of the venus-shared proxy structs,
+// so that they can be used with `permission.PermissionProxy` and `jsonrpc.NewMergeClient`
+
+type IApprovalStruct struct {
+	Internal struct {
+		ApprovalApprove func(ctx context.Context, id string, comment string) error                                `perm:"admin"`
+		ApprovalGet     func(ctx context.Context, id string) (*storage.ApprovalRequest, error)                    `perm:"admin"`
+		ApprovalList    func(ctx context.Context, state storage.ApprovalState) ([]storage.ApprovalRequest, error) `perm:"admin"`
+		ApprovalReject  func(ctx context.Context, id string, comment string) error                                `perm:"admin"`
+	}
+}
+
+func (s *IApprovalStruct) ApprovalApprove(p0 context.Context, p1 string, p2 string) error {
+	return s.Internal.ApprovalApprove(p0, p1, p2)
+}
+func (s *IApprovalStruct) ApprovalGet(p0 context.Context, p1 string) (*storage.ApprovalRequest, error) {
+	return s.Internal.ApprovalGet(p0, p1)
+}
+func (s *IApprovalStruct) ApprovalList(p0 context.Context, p1 storage.ApprovalState) ([]storage.ApprovalRequest, error) {
+	return s.Internal.ApprovalList(p0, p1)
+}
+func (s *IApprovalStruct) ApprovalReject(p0 context.Context, p1 string, p2 string) error {
+	return s.Internal.ApprovalReject(p0, p1, p2)
+}
+
+type IQuorumStruct struct {
+	Internal struct {
+		QuorumAudit   func(ctx context.Context, id string) ([]storage.QuorumAudit, error)                   `perm:"admin"`
+		QuorumConfirm func(ctx context.Context, id string, comment string) error                            `perm:"admin"`
+		QuorumGet     func(ctx context.Context, id string) (*storage.QuorumRequest, error)                  `perm:"admin"`
+		QuorumList    func(ctx context.Context, state storage.QuorumState) ([]storage.QuorumRequest, error) `perm:"admin"`
+		QuorumReject  func(ctx context.Context, id string, comment string) error                            `perm:"admin"`
+	}
+}
+
+func (s *IQuorumStruct) QuorumAudit(p0 context.Context, p1 string) ([]storage.QuorumAudit, error) {
+	return s.Internal.QuorumAudit(p0, p1)
+}
+func (s *IQuorumStruct) QuorumConfirm(p0 context.Context, p1 string, p2 string) error {
+	return s.Internal.QuorumConfirm(p0, p1, p2)
+}
+func (s *IQuorumStruct) QuorumGet(p0 context.Context, p1 string) (*storage.QuorumRequest, error) {
+	return s.Internal.QuorumGet(p0, p1)
+}
+func (s *IQuorumStruct) QuorumList(p0 context.Context, p1 storage.QuorumState) ([]storage.QuorumRequest, error) {
+	return s.Internal.QuorumList(p0, p1)
+}
+func (s *IQuorumStruct) QuorumReject(p0 context.Context, p1 string, p2 string) error {
+	return s.Internal.QuorumReject(p0, p1, p2)
+}
+
+type IPolicyStruct struct {
+	Internal struct {
+		PolicyTest func(ctx context.Context, signer address.Address, toSign []byte, meta types.MsgMeta, caller *wallet.PolicyCaller) (*wallet.PolicyResult, error) `perm:"admin"`
+	}
+}
+
+func (s *IPolicyStruct) PolicyTest(p0 context.Context, p1 address.Address, p2 []byte, p3 types.MsgMeta, p4 *wallet.PolicyCaller) (*wallet.PolicyResult, error) {
+	return s.Internal.PolicyTest(p0, p1, p2, p3, p4)
+}
+
+type IKeyRoleStruct struct {
+	Internal struct {
+		WalletListMeta func(ctx context.Context) ([]storage.KeyMeta, error)                           `perm:"read"`
+		WalletSetRoles func(ctx context.Context, addr address.Address, roles []storage.KeyRole) error `perm:"admin"`
+	}
+}
+
+func (s *IKeyRoleStruct) WalletListMeta(p0 context.Context) ([]storage.KeyMeta, error) {
+	return s.Internal.WalletListMeta(p0)
+}
+func (s *IKeyRoleStruct) WalletSetRoles(p0 context.Context, p1 address.Address, p2 []storage.KeyRole) error {
+	return s.Internal.WalletSetRoles(p0, p1, p2)
+}
+
+type IKeyExportStruct struct {
+	Internal struct {
+		WalletImportWithOptions func(ctx context.Context, ki *types.KeyInfo, opts wallet.KeyOptions) (address.Address, error) `perm:"admin"`
+		WalletNewWithOptions    func(ctx context.Context, kt types.KeyType, opts wallet.KeyOptions) (address.Address, error)  `perm:"admin"`
+	}
+}
+
+func (s *IKeyExportStruct) WalletImportWithOptions(p0 context.Context, p1 *types.KeyInfo, p2 wallet.KeyOptions) (address.Address, error) {
+	return s.Internal.WalletImportWithOptions(p0, p1, p2)
+}
+func (s *IKeyExportStruct) WalletNewWithOptions(p0 context.Context, p1 types.KeyType, p2 wallet.KeyOptions) (address.Address, error) {
+	return s.Internal.WalletNewWithOptions(p0, p1, p2)
+}
+
+type IKeySuspendStruct struct {
+	Internal struct {
+		WalletDisable func(ctx context.Context, addr address.Address) error `perm:"admin"`
+		WalletEnable  func(ctx context.Context, addr address.Address) error `perm:"admin"`
+	}
+}
+
+func (s *IKeySuspendStruct) WalletDisable(p0 context.Context, p1 address.Address) error {
+	return s.Internal.WalletDisable(p0, p1)
+}
+func (s *IKeySuspendStruct) WalletEnable(p0 context.Context, p1 address.Address) error {
+	return s.Internal.WalletEnable(p0, p1)
+}
+
+type IPanicStruct struct {
+	Internal struct {
+		WalletPanicList func(ctx context.Context) ([]storage.PanicEvent, error)           `perm:"admin"`
+		WalletPanicLock func(ctx context.Context, reason string, revokeTokens bool) error `perm:"admin"`
+	}
+}
+
+func (s *IPanicStruct) WalletPanicList(p0 context.Context) ([]storage.PanicEvent, error) {
+	return s.Internal.WalletPanicList(p0)
+}
+func (s *IPanicStruct) WalletPanicLock(p0 context.Context, p1 string, p2 bool) error {
+	return s.Internal.WalletPanicLock(p0, p1, p2)
+}
+
+type IEventsStruct struct {
+	Internal struct {
+		WalletSubscribeEvents func(ctx context.Context, filter *wallet.EventFilter) (<-chan wallet.WalletEvent, error) `perm:"read"`
+	}
+}
+
+func (s *IEventsStruct) WalletSubscribeEvents(p0 context.Context, p1 *wallet.EventFilter) (<-chan wallet.WalletEvent, error) {
+	return s.Internal.WalletSubscribeEvents(p0, p1)
+}
+
+type ITOTPStruct struct {
+	Internal struct {
+		TOTPConfirm func(ctx context.Context, code string) error                               `perm:"admin"`
+		TOTPDisable func(ctx context.Context, password string) error                           `perm:"admin"`
+		TOTPEnroll  func(ctx context.Context, password string) (*wallet.TOTPEnrollment, error) `perm:"admin"`
+		TOTPStatus  func(ctx context.Context) (*wallet.TOTPStatus, error)                      `perm:"read"`
+	}
+}
+
+func (s *ITOTPStruct) TOTPConfirm(p0 context.Context, p1 string) error {
+	return s.Internal.TOTPConfirm(p0, p1)
+}
+func (s *ITOTPStruct) TOTPDisable(p0 context.Context, p1 string) error {
+	return s.Internal.TOTPDisable(p0, p1)
+}
+func (s *ITOTPStruct) TOTPEnroll(p0 context.Context, p1 string) (*wallet.TOTPEnrollment, error) {
+	return s.Internal.TOTPEnroll(p0, p1)
+}
+func (s *ITOTPStruct) TOTPStatus(p0 context.Context) (*wallet.TOTPStatus, error) {
+	return s.Internal.TOTPStatus(p0)
+}
+
+type IConfigReloadStruct struct {
+	Internal struct {
+		ConfigReload       func(ctx context.Context) (*wallet.ReloadResult, error) `perm:"admin"`
+		ConfigReloadResult func(ctx context.Context) (*wallet.ReloadResult, error) `perm:"admin"`
+	}
+}
+
+func (s *IConfigReloadStruct) ConfigReload(p0 context.Context) (*wallet.ReloadResult, error) {
+	return s.Internal.ConfigReload(p0)
+}
+func (s *IConfigReloadStruct) ConfigReloadResult(p0 context.Context) (*wallet.ReloadResult, error) {
+	return s.Internal.ConfigReloadResult(p0)
+}
+
+type IRecordStruct struct {
+	Internal struct {
+		RecordQuery      func(ctx context.Context, params *storage.RecordQueryParams) ([]storage.SignRecord, error)            `perm:"read"`
+		RecordVerify     func(ctx context.Context, auditKey address.Address) (*storage.RecordVerifyResult, error)              `perm:"read"`
+		RecordExport     func(ctx context.Context, params *storage.RecordQueryParams) (<-chan storage.RecordExportItem, error) `perm:"read"`
+		RecordStats      func(ctx context.Context, params *storage.RecordStatsParams) (*storage.RecordStats, error)            `perm:"read"`
+		RecordAdminQuery func(ctx context.Context, params *storage.AdminQueryParams) ([]storage.AdminRecord, error)            `perm:"read"`
+	}
+}
+
+func (s *IRecordStruct) RecordQuery(p0 context.Context, p1 *storage.RecordQueryParams) ([]storage.SignRecord, error) {
+	return s.Internal.RecordQuery(p0, p1)
+}
+
+func (s *IRecordStruct) RecordExport(p0 context.Context, p1 *storage.RecordQueryParams) (<-chan storage.RecordExportItem, error) {
+	return s.Internal.RecordExport(p0, p1)
+}
+
+func (s *IRecordStruct) RecordStats(p0 context.Context, p1 *storage.RecordStatsParams) (*storage.RecordStats, error) {
+	return s.Internal.RecordStats(p0, p1)
+}
+
+func (s *IRecordStruct) RecordAdminQuery(p0 context.Context, p1 *storage.AdminQueryParams) ([]storage.AdminRecord, error) {
+	return s.Internal.RecordAdminQuery(p0, p1)
+}
+
+func (s *IRecordStruct) RecordVerify(p0 context.Context, p1 address.Address) (*storage.RecordVerifyResult, error) {
+	return s.Internal.RecordVerify(p0, p1)
+}
+
+type INamedTokenStruct struct {
+	Internal struct {
+		AuthNewNamed func(ctx context.Context, name string, perms []auth.Permission) ([]byte, error) `perm:"admin"`
+	}
+}
+
+func (s *INamedTokenStruct) AuthNewNamed(p0 context.Context, p1 string, p2 []auth.Permission) ([]byte, error) {
+	return s.Internal.AuthNewNamed(p0, p1, p2)
+}
+
+type FullAPIStruct struct {
+	shared.IFullAPIStruct
+	IPolicyStruct
+	IKeyRoleStruct
+	IKeySuspendStruct
+	IKeyExportStruct
+	IPanicStruct
+	ITOTPStruct
+	IApprovalStruct
+	IQuorumStruct
+	IConfigReloadStruct
+	IRecordStruct
+	INamedTokenStruct
+	IEventsStruct
+}
+
+var _ IFullAPI = &FullAPIStruct{}
@@ -7,6 +7,8 @@ import (
 	"github.com/filecoin-project/go-jsonrpc"
 	apiutil "github.com/filecoin-project/venus/venus-shared/api"
 	api "github.com/filecoin-project/venus/venus-shared/api/wallet"
+
+	localapi "github.com/filecoin-project/venus-wallet/api"
 )
 
 // NewWalletRPC RPCClient returns an RPC client connected to a node
@@ -36,8 +38,8 @@ func NewCommonRPC(ctx context.Context, addr string, requestHeader http.Header) (
 }
 
 // NewFullNodeRPC creates a new httpparse jsonrpc remotecli.
-func NewFullNodeRPC(ctx context.Context, addr string, requestHeader http.Header) (api.IFullAPI, jsonrpc.ClientCloser, error) {
-	var res api.IFullAPIStruct
+func NewFullNodeRPC(ctx context.Context, addr string, requestHeader http.Header) (localapi.IFullAPI, jsonrpc.ClientCloser, error) {
+	var res localapi.FullAPIStruct
 	closer, err := jsonrpc.NewMergeClient(ctx, addr, "Filecoin", apiutil.GetInternalStructs(&res), requestHeader)
 
 	return &res, closer, err
@@ -35,6 +35,7 @@ const (
 	PstoreAddSelfKeysKey = invoke(iota)
 	ExtractApiKey
 	SetNet
+	StartRecordCheckpoint
 	_nInvokes // keep this last
 )
 
@@ -68,16 +69,44 @@ func WalletOpt(repo filemgr.Repo, walletPwd string) Option {
 		Override(new(storage.KeyMiddleware), storage.NewKeyMiddleware),
 		Override(new(storage.KeyStore), sqlite.NewKeyStore),
 		Override(new(*config.SignRecorderConfig), c.SignRecorder),
-		Override(new(storage.IRecorder), sqlite.NewSqliteRecorder),
+		Override(new(storage.IRecorder), sqlite.NewRecorder),
+		Override(new(*wallet.RecordQueue), wallet.NewRecordQueue),
 		Override(new(wallet.GetPwdFunc), func() wallet.GetPwdFunc {
 			return func() string {
 				return walletPwd
 			}
 		}),
-		Override(new(wallet.ISignMsgFilter), func() wallet.ISignMsgFilter {
-			return wallet.NewSignFilter(c.SignFilter)
+		Override(new(*config.DealPolicyConfig), c.DealPolicy),
+		Override(new(*wallet.DealPolicy), wallet.NewDealPolicy),
+		Override(new(*config.BlindSignConfig), c.BlindSign),
+		Override(new(*wallet.BlindSignPolicy), wallet.NewBlindSignPolicy),
+		Override(new(*config.SignFilter), c.SignFilter),
+		Override(new(*wallet.SignFilter), wallet.NewSignFilter),
+		Override(new(*config.SignPluginConfig), c.SignPlugin),
+		Override(new(*wallet.SignPlugin), wallet.NewSignPlugin),
+		Override(new(storage.IKeyMetaStore), sqlite.NewKeyMetaStore),
+		Override(new(*wallet.RolePolicy), wallet.NewRolePolicy),
+		Override(new(storage.IPanicStore), sqlite.NewPanicStore),
+		Override(new(storage.ITOTPStore), sqlite.NewTOTPStore),
+		Override(new(*config.KeyExportConfig), c.KeyExport),
+		Override(new(wallet.ISignMsgFilter), func(rolePolicy *wallet.RolePolicy, dealPolicy *wallet.DealPolicy, blindSign *wallet.BlindSignPolicy, signFilter *wallet.SignFilter, signPlugin *wallet.SignPlugin) wallet.ISignMsgFilter {
+			return wallet.FilterChain{rolePolicy, blindSign, dealPolicy, signFilter, signPlugin}
 		}),
-		Override(new(wallet_api.ILocalWallet), wallet.NewWallet),
+		Override(new(*config.ApprovalConfig), c.Approval),
+		Override(new(storage.IApprovalStore), sqlite.NewApprovalStore),
+		Override(new(*wallet.ApprovalQueue), wallet.NewApprovalQueue),
+		Override(new(wallet.IApproval), From(new(*wallet.ApprovalQueue))),
+		Override(new(*config.QuorumConfig), c.Quorum),
+		Override(new(storage.IQuorumStore), sqlite.NewQuorumStore),
+		Override(new(*wallet.QuorumQueue), wallet.NewQuorumQueue),
+		Override(new(wallet.IQuorum), From(new(*wallet.QuorumQueue))),
+		Override(new(*config.RateLimitConfig), c.RateLimit),
+		Override(new(*wallet.RateLimiter), wallet.NewRateLimiter),
+		Override(new(*wallet.ConfigReloader), wallet.NewConfigReloader),
+		Override(new(wallet.IConfigReload), From(new(*wallet.ConfigReloader))),
+		Override(new(wallet.ILocalWallet), wallet.NewWallet),
+		Override(new(wallet_api.ILocalWallet), func(w wallet.ILocalWallet) wallet_api.ILocalWallet { return w }),
+		Override(StartRecordCheckpoint, wallet.StartRecordCheckpoint),
 
 		Override(new(types.IWalletHandler), From(new(wallet_api.ILocalWallet))),
 		Override(new(*config.APIRegisterHubConfig), c.APIRegisterHub),
@@ -90,6 +119,8 @@ func CommonOpt(alg *jwt.HMACSHA) Option {
 	return Options(
 		Override(new(*jwt.HMACSHA), alg),
 		Override(new(common.ICommon), From(new(common.Common))),
+		Override(new(common.IRecord), From(new(common.Common))),
+		Override(new(common.INamedToken), From(new(common.Common))),
 	)
 }
 
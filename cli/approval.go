@@ -0,0 +1,160 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/filecoin-project/venus-wallet/cli/helper"
+	"github.com/filecoin-project/venus-wallet/errcode"
+	"github.com/filecoin-project/venus-wallet/storage"
+)
+
+var approvalCmd = &cli.Command{
+	Name:  "approval",
+	Usage: "manage the sign requests waiting for approval",
+	Subcommands: []*cli.Command{
+		approvalList,
+		approvalShow,
+		approvalApprove,
+		approvalReject,
+	},
+}
+
+var approvalList = &cli.Command{
+	Name:  "list",
+	Usage: "list approval requests",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  "state",
+			Usage: "filter by state, one of: pending, approved, rejected, expired",
+			Value: string(storage.ApprovalPending),
+		},
+		&cli.BoolFlag{
+			Name:  "all",
+			Usage: "list requests in all states",
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		api, closer, err := helper.GetFullAPI(cctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+		ctx := helper.ReqContext(cctx)
+
+		state := storage.ApprovalState(cctx.String("state"))
+		if cctx.Bool("all") {
+			state = ""
+		}
+		reqs, err := api.ApprovalList(ctx, state)
+		if err != nil {
+			return err
+		}
+
+		w := helper.NewTabWriter(cctx.App.Writer)
+		fmt.Fprintln(w, "ID\tSIGNER\tTYPE\tSTATE\tCREATED\tDEADLINE\tREASON")
+		for _, r := range reqs {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", r.ID, r.Signer, r.Type, r.State,
+				r.CreateAt.Format("2006-01-02 15:04:05"), r.Deadline.Format("2006-01-02 15:04:05"), r.Reason)
+		}
+		return w.Flush()
+	},
+}
+
+var approvalShow = &cli.Command{
+	Name:      "show",
+	Usage:     "show an approval request with the decoded message",
+	ArgsUsage: "<id>",
+	Action: func(cctx *cli.Context) error {
+		if cctx.NArg() != 1 {
+			return helper.ShowHelp(cctx, errcode.ErrParameterMismatch)
+		}
+		api, closer, err := helper.GetFullAPI(cctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+		ctx := helper.ReqContext(cctx)
+
+		req, err := api.ApprovalGet(ctx, cctx.Args().First())
+		if err != nil {
+			return err
+		}
+		detail, err := GetDetailInJsonRawMessage(&storage.SignRecord{
+			Type:   req.Type,
+			Signer: req.Signer,
+			RawMsg: req.RawMsg,
+		})
+		if err != nil {
+			return err
+		}
+
+		return helper.PrintJSON(struct {
+			storage.ApprovalRequest
+			Detail json.RawMessage
+		}{
+			ApprovalRequest: *req,
+			Detail:          detail,
+		})
+	},
+}
+
+var approvalApprove = &cli.Command{
+	Name:      "approve",
+	Usage:     "approve a pending sign request",
+	ArgsUsage: "<id>",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  "comment",
+			Usage: "comment saved with the decision",
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		if cctx.NArg() != 1 {
+			return helper.ShowHelp(cctx, errcode.ErrParameterMismatch)
+		}
+		api, closer, err := helper.GetFullAPI(cctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+		ctx := helper.ReqContext(cctx)
+
+		if err := api.ApprovalApprove(ctx, cctx.Args().First(), cctx.String("comment")); err != nil {
+			return err
+		}
+		fmt.Println("approved")
+		return nil
+	},
+}
+
+var approvalReject = &cli.Command{
+	Name:      "reject",
+	Usage:     "reject a pending sign request",
+	ArgsUsage: "<id>",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  "comment",
+			Usage: "comment saved with the decision",
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		if cctx.NArg() != 1 {
+			return helper.ShowHelp(cctx, errcode.ErrParameterMismatch)
+		}
+		api, closer, err := helper.GetFullAPI(cctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+		ctx := helper.ReqContext(cctx)
+
+		if err := api.ApprovalReject(ctx, cctx.Args().First(), cctx.String("comment")); err != nil {
+			return err
+		}
+		fmt.Println("rejected")
+		return nil
+	},
+}
@@ -29,6 +29,10 @@ var authApiInfoToken = &cli.Command{
 			Name:  "perm",
 			Usage: "permission to assign to the token, one of: read, write, sign, admin",
 		},
+		&cli.StringFlag{
+			Name:  "name",
+			Usage: "name of the service using the token, kept in the sign records",
+		},
 	},
 
 	Action: func(cctx *cli.Context) error {
@@ -76,9 +80,22 @@ var authApiInfoToken = &cli.Command{
 		}
 
 		// slice on [:idx] so for example: 'sign' gives you [read, write, sign]
-		token, err := api.AuthNew(ctx, allPermissions[:idx])
-		if err != nil {
-			return err
+		var token []byte
+		if cctx.IsSet("name") {
+			fullAPI, closer, err := helper.GetFullAPI(cctx)
+			if err != nil {
+				return err
+			}
+			defer closer()
+			token, err = fullAPI.AuthNewNamed(ctx, cctx.String("name"), allPermissions[:idx])
+			if err != nil {
+				return err
+			}
+		} else {
+			token, err = api.AuthNew(ctx, allPermissions[:idx])
+			if err != nil {
+				return err
+			}
 		}
 
 		apiInfo, err := helper.GetAPIInfo(cctx)
@@ -13,10 +13,20 @@ var Commands = []*cli.Command{
 	walletImport,
 	walletSign,
 	walletDel,
+	walletSetRoles,
+	walletDisable,
+	walletEnable,
 	walletSetPassword,
 	walletUnlock,
 	walletLock,
 	walletLockState,
+	panicCmd,
+	eventsCmd,
+	totpCmd,
 	supportCmds,
 	recordCmd,
+	approvalCmd,
+	quorumCmd,
+	policyCmd,
+	configCmd,
 }
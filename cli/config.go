@@ -0,0 +1,80 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/filecoin-project/venus-wallet/cli/helper"
+	"github.com/filecoin-project/venus-wallet/storage/wallet"
+)
+
+var configCmd = &cli.Command{
+	Name:  "config",
+	Usage: "manage the config of the running wallet",
+	Subcommands: []*cli.Command{
+		configReload,
+		configReloadStatus,
+	},
+}
+
+var configReload = &cli.Command{
+	Name:  "reload",
+	Usage: "reload the sign filter, policies and recorder retention from the config file",
+	Description: `The running wallet also reloads on SIGHUP and when the config file changes.
+   Nothing is applied if any section fails to validate.`,
+	Action: func(cctx *cli.Context) error {
+		api, closer, err := helper.GetFullAPI(cctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+		ctx := helper.ReqContext(cctx)
+
+		res, err := api.ConfigReload(ctx)
+		if err != nil {
+			return err
+		}
+		printReloadResult(cctx, res)
+		return nil
+	},
+}
+
+var configReloadStatus = &cli.Command{
+	Name:  "reload-status",
+	Usage: "show the result of the last config reload",
+	Action: func(cctx *cli.Context) error {
+		api, closer, err := helper.GetFullAPI(cctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+		ctx := helper.ReqContext(cctx)
+
+		res, err := api.ConfigReloadResult(ctx)
+		if err != nil {
+			return err
+		}
+		if res == nil {
+			fmt.Fprintln(cctx.App.Writer, "config has not been reloaded")
+			return nil
+		}
+		printReloadResult(cctx, res)
+		return nil
+	},
+}
+
+func printReloadResult(cctx *cli.Context, res *wallet.ReloadResult) {
+	w := cctx.App.Writer
+	fmt.Fprintf(w, "time: %s\n", res.Time.Format("2006-01-02 15:04:05"))
+	fmt.Fprintf(w, "trigger: %s\n", res.Trigger)
+	fmt.Fprintf(w, "success: %t\n", res.Success)
+	if res.Err != "" {
+		fmt.Fprintf(w, "error: %s\n", res.Err)
+	}
+	fmt.Fprintf(w, "changed: %s\n", strings.Join(res.Changed, ", "))
+	if len(res.RestartRequired) > 0 {
+		fmt.Fprintf(w, "restart required: %s\n", strings.Join(res.RestartRequired, ", "))
+	}
+}
@@ -0,0 +1,90 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/urfave/cli/v2"
+
+	"github.com/filecoin-project/venus-wallet/cli/helper"
+	"github.com/filecoin-project/venus-wallet/storage/wallet"
+)
+
+var eventsCmd = &cli.Command{
+	Name:  "events",
+	Usage: "follow the wallet events as they happen, until interrupted",
+	Flags: []cli.Flag{
+		&cli.StringSliceFlag{
+			Name:  "address",
+			Usage: "only the events of the addresses, lock and unlock are always shown",
+		},
+		&cli.StringSliceFlag{
+			Name:  "type",
+			Usage: fmt.Sprintf("only the events of the types, of: %v", wallet.EventTypes),
+		},
+		&cli.BoolFlag{
+			Name:  "json",
+			Usage: "print an event per line in json",
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		filter := &wallet.EventFilter{}
+		for _, s := range cctx.StringSlice("address") {
+			addr, err := address.NewFromString(s)
+			if err != nil {
+				return fmt.Errorf("parse address %s: %w", s, err)
+			}
+			filter.Addresses = append(filter.Addresses, addr)
+		}
+		for _, t := range cctx.StringSlice("type") {
+			filter.Types = append(filter.Types, wallet.EventType(t))
+		}
+
+		api, closer, err := helper.GetFullAPIStream(cctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+		ctx := helper.ReqContext(cctx)
+
+		events, err := api.WalletSubscribeEvents(ctx, filter)
+		if err != nil {
+			return err
+		}
+		enc := json.NewEncoder(cctx.App.Writer)
+		for ev := range events {
+			if cctx.Bool("json") {
+				if err := enc.Encode(ev); err != nil {
+					return err
+				}
+				continue
+			}
+			_, _ = fmt.Fprintln(cctx.App.Writer, eventString(&ev))
+		}
+		return nil
+	},
+}
+
+func eventString(ev *wallet.WalletEvent) string {
+	parts := []string{ev.Time.Format(time.RFC3339), string(ev.Type)}
+	if ev.Address != address.Undef {
+		parts = append(parts, ev.Address.String())
+	}
+	if ev.MsgType != "" {
+		parts = append(parts, string(ev.MsgType))
+	}
+	if ev.Summary != "" {
+		parts = append(parts, ev.Summary)
+	}
+	parts = append(parts, "by "+callerString(ev.Caller))
+	if ev.Err != "" {
+		parts = append(parts, "err: "+ev.Err)
+	}
+	if ev.Missed > 0 {
+		parts = append(parts, fmt.Sprintf("(%d events missed before)", ev.Missed))
+	}
+	return strings.Join(parts, "  ")
+}
@@ -9,6 +9,7 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"text/tabwriter"
 	"time"
@@ -19,6 +20,7 @@ import (
 	"github.com/filecoin-project/venus-wallet/api/remotecli/httpparse"
 	"github.com/filecoin-project/venus-wallet/common"
 	"github.com/filecoin-project/venus-wallet/filemgr"
+	"github.com/filecoin-project/venus-wallet/middleware"
 	"github.com/howeyc/gopass"
 	logging "github.com/ipfs/go-log/v2"
 	"github.com/mitchellh/go-homedir"
@@ -64,9 +66,12 @@ func GetAPIInfo(ctx *cli.Context) (httpparse.APIInfo, error) {
 		return httpparse.APIInfo{}, fmt.Errorf("could not get api endpoint: %w", err)
 	}
 
-	token, err := r.APIToken()
-	if err != nil {
-		log.Warnf("Couldn't load CLI token, capabilities may be limited: %v", err)
+	// commands acting as another admin, like the quorum confirmation, take the token as a flag
+	token := []byte(ctx.String("token"))
+	if len(token) == 0 {
+		if token, err = r.APIToken(); err != nil {
+			log.Warnf("Couldn't load CLI token, capabilities may be limited: %v", err)
+		}
 	}
 
 	return httpparse.APIInfo{
@@ -127,6 +132,49 @@ func GetFullAPI(ctx *cli.Context) (api.IFullAPI, jsonrpc.ClientCloser, error) {
 	return remotecli.NewFullNodeRPC(ctx.Context, addr, headers)
 }
 
+// GetFullAPIStream connects over websocket, which the methods returning channels need
+func GetFullAPIStream(ctx *cli.Context) (api.IFullAPI, jsonrpc.ClientCloser, error) {
+	addr, headers, err := GetRawAPI(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	switch {
+	case strings.HasPrefix(addr, "http://"):
+		addr = "ws://" + strings.TrimPrefix(addr, "http://")
+	case strings.HasPrefix(addr, "https://"):
+		addr = "wss://" + strings.TrimPrefix(addr, "https://")
+	}
+	return remotecli.NewFullNodeRPC(ctx.Context, addr, headers)
+}
+
+// GetFullAPIWithOTP prompts for the one-time code when the wallet has totp enrolled, the code is sent in a header
+func GetFullAPIWithOTP(ctx *cli.Context) (api.IFullAPI, jsonrpc.ClientCloser, error) {
+	addr, headers, err := GetRawAPI(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	full, closer, err := remotecli.NewFullNodeRPC(ctx.Context, addr, headers)
+	if err != nil {
+		return nil, nil, err
+	}
+	status, err := full.TOTPStatus(ctx.Context)
+	if err != nil {
+		closer()
+		return nil, nil, err
+	}
+	if !status.Enrolled {
+		return full, closer, nil
+	}
+	closer()
+
+	code, err := gopass.GetPasswdPrompt("One-time code (or recovery code):", false, os.Stdin, os.Stdout)
+	if err != nil {
+		return nil, nil, err
+	}
+	headers.Set(middleware.OTPHeader, strings.TrimSpace(string(code)))
+	return remotecli.NewFullNodeRPC(ctx.Context, addr, headers)
+}
+
 func GetFullAPIWithPWD(ctx *cli.Context) (api.IFullAPI, jsonrpc.ClientCloser, error) {
 	addr, headers, err := GetRawAPI(ctx)
 	if err != nil {
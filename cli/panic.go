@@ -0,0 +1,75 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/filecoin-project/venus-wallet/cli/helper"
+)
+
+var panicCmd = &cli.Command{
+	Name:  "panic",
+	Usage: "emergency lock of the wallet, unlock with the password to recover",
+	Subcommands: []*cli.Command{
+		panicLock,
+		panicList,
+	},
+}
+
+var panicLock = &cli.Command{
+	Name:  "lock",
+	Usage: "lock the wallet at once without the password, purge the decrypted keys and remove them from the gateway",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  "reason",
+			Usage: "the reason kept with the panic event",
+		},
+		&cli.BoolFlag{
+			Name:  "revoke-tokens",
+			Usage: "revoke all the tokens without admin permission created so far",
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		api, closer, err := helper.GetFullAPI(cctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+		ctx := helper.ReqContext(cctx)
+
+		if err := api.WalletPanicLock(ctx, cctx.String("reason"), cctx.Bool("revoke-tokens")); err != nil {
+			return err
+		}
+		fmt.Println("wallet panic locked, run `unlock` with the password to recover")
+		return nil
+	},
+}
+
+var panicList = &cli.Command{
+	Name:  "list",
+	Usage: "list the panic locks",
+	Action: func(cctx *cli.Context) error {
+		api, closer, err := helper.GetFullAPI(cctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+		ctx := helper.ReqContext(cctx)
+
+		events, err := api.WalletPanicList(ctx)
+		if err != nil {
+			return err
+		}
+		w := helper.NewTabWriter(cctx.App.Writer)
+		fmt.Fprintln(w, "ID\tTIME\tCALLER\tREVOKE TOKENS\tREASON")
+		for _, e := range events {
+			caller := callerString(e.Caller)
+			if e.Caller == nil {
+				caller = "signal"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%t\t%s\n", e.ID, e.CreateAt.Format("2006-01-02 15:04:05"), caller, e.RevokeTokens, e.Reason)
+		}
+		return w.Flush()
+	},
+}
@@ -0,0 +1,183 @@
+package cli
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/venus/venus-shared/types"
+	"github.com/urfave/cli/v2"
+
+	"github.com/filecoin-project/venus-wallet/cli/helper"
+	"github.com/filecoin-project/venus-wallet/errcode"
+	"github.com/filecoin-project/venus-wallet/storage/wallet"
+)
+
+var policyCmd = &cli.Command{
+	Name:  "policy",
+	Usage: "inspect the sign policies",
+	Subcommands: []*cli.Command{
+		policyTest,
+	},
+}
+
+var policyTest = &cli.Command{
+	Name:  "test",
+	Usage: "run a message through the sign policies without signing it",
+	Description: `The message is either a hex string, or a chain message in json read from a file.
+
+   For chain messages (--msg-type message) the hex string is the cbor encoded message,
+   and the signer defaults to the sender of the message.
+
+   eg) policy test --json msg.json
+       policy test --signer f1xxx --msg-type dealproposal <hex>
+       policy test --json msg.json --expect allow
+       policy test --json msg.json --as-token-id <id from the sign records>`,
+	ArgsUsage: "[hexMessage]",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  "signer",
+			Usage: "address to sign with",
+		},
+		&cli.StringFlag{
+			Name:  "msg-type",
+			Value: string(types.MTChainMsg),
+		},
+		&cli.StringFlag{
+			Name:  "extra",
+			Usage: "hex encoded MsgMeta.Extra, not for chain messages which are given as the argument or --json",
+		},
+		&cli.StringFlag{
+			Name:  "json",
+			Usage: "file of the chain message in json, '-' for stdin",
+		},
+		&cli.StringFlag{
+			Name:  "as-token",
+			Usage: "run the message as sent with the jwt token, for the rate limits of the token",
+		},
+		&cli.StringFlag{
+			Name:  "as-token-id",
+			Usage: "run the message as sent with the token of the id, as shown by the sign records",
+		},
+		&cli.StringFlag{
+			Name:  "as-name",
+			Usage: "the token name the sign plugin is given",
+		},
+		&cli.StringFlag{
+			Name:  "expect",
+			Usage: "exit with error if the decision is not the expected one: allow, reject, approval",
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		meta := types.MsgMeta{Type: types.MsgType(cctx.String("msg-type"))}
+		var (
+			toSign []byte
+			signer address.Address
+			err    error
+		)
+		if cctx.IsSet("signer") {
+			if signer, err = address.NewFromString(cctx.String("signer")); err != nil {
+				return err
+			}
+		}
+
+		switch {
+		case cctx.IsSet("json"):
+			if meta.Type != types.MTChainMsg {
+				return fmt.Errorf("--json only supports msg type %s", types.MTChainMsg)
+			}
+			var data []byte
+			if cctx.String("json") == "-" {
+				data, err = io.ReadAll(os.Stdin)
+			} else {
+				data, err = os.ReadFile(cctx.String("json"))
+			}
+			if err != nil {
+				return err
+			}
+			var msg types.Message
+			if err := json.Unmarshal(data, &msg); err != nil {
+				return fmt.Errorf("parse message: %w", err)
+			}
+			if meta.Extra, err = msg.Serialize(); err != nil {
+				return err
+			}
+		case cctx.Args().Present():
+			data, err := hex.DecodeString(strings.TrimSpace(cctx.Args().First()))
+			if err != nil {
+				return err
+			}
+			if meta.Type == types.MTChainMsg {
+				meta.Extra = data
+			} else {
+				toSign = data
+			}
+		default:
+			return helper.ShowHelp(cctx, errcode.ErrParameterMismatch)
+		}
+
+		if meta.Type == types.MTChainMsg {
+			if cctx.IsSet("extra") {
+				return fmt.Errorf("--extra doesn't apply to %s, the message is the extra", types.MTChainMsg)
+			}
+			msg, err := types.DecodeMessage(meta.Extra)
+			if err != nil {
+				return fmt.Errorf("decode message: %w", err)
+			}
+			toSign = msg.Cid().Bytes()
+			if signer == address.Undef {
+				signer = msg.From
+			}
+		} else if cctx.IsSet("extra") {
+			if meta.Extra, err = hex.DecodeString(cctx.String("extra")); err != nil {
+				return err
+			}
+		}
+		if signer == address.Undef {
+			return fmt.Errorf("--signer is required for msg type %s", meta.Type)
+		}
+
+		api, closer, err := helper.GetFullAPI(cctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+		ctx := helper.ReqContext(cctx)
+
+		var caller *wallet.PolicyCaller
+		if cctx.IsSet("as-token") || cctx.IsSet("as-token-id") || cctx.IsSet("as-name") {
+			caller = &wallet.PolicyCaller{
+				Token:   cctx.String("as-token"),
+				TokenID: cctx.String("as-token-id"),
+				Name:    cctx.String("as-name"),
+			}
+		}
+		res, err := api.PolicyTest(ctx, signer, toSign, meta, caller)
+		if err != nil {
+			return err
+		}
+
+		w := helper.NewTabWriter(cctx.App.Writer)
+		fmt.Fprintln(w, "CHECK\tMATCHED\tDETAIL")
+		for _, c := range res.Checks {
+			matched := fmt.Sprint(c.Matched)
+			if c.NotEvaluated {
+				matched = "not evaluated"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\n", c.Name, matched, c.Detail)
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+		fmt.Fprintf(cctx.App.Writer, "decision: %s\n", res.Decision)
+
+		if cctx.IsSet("expect") && wallet.PolicyDecision(cctx.String("expect")) != res.Decision {
+			return fmt.Errorf("expect decision %s, got %s", cctx.String("expect"), res.Decision)
+		}
+		return nil
+	},
+}
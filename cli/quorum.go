@@ -0,0 +1,157 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/filecoin-project/venus-wallet/cli/helper"
+	"github.com/filecoin-project/venus-wallet/errcode"
+	"github.com/filecoin-project/venus-wallet/storage"
+)
+
+var quorumCmd = &cli.Command{
+	Name:  "quorum",
+	Usage: "manage the export, delete and set password operations waiting for a second admin",
+	Subcommands: []*cli.Command{
+		quorumList,
+		quorumConfirm,
+		quorumReject,
+		quorumAudit,
+	},
+}
+
+// tokenFlag the token of the second admin, the token of the repo is the one of the requester in most setups
+var tokenFlag = &cli.StringFlag{
+	Name:  "token",
+	Usage: "admin token to decide with, to confirm it must be a token listed in Quorum.Approvers other than the requester, created before the request",
+}
+
+var quorumList = &cli.Command{
+	Name:  "list",
+	Usage: "list quorum requests",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  "state",
+			Usage: "filter by state, one of: pending, confirmed, rejected, expired",
+			Value: string(storage.QuorumPending),
+		},
+		&cli.BoolFlag{
+			Name:  "all",
+			Usage: "list requests in all states",
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		api, closer, err := helper.GetFullAPI(cctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+		ctx := helper.ReqContext(cctx)
+
+		state := storage.QuorumState(cctx.String("state"))
+		if cctx.Bool("all") {
+			state = ""
+		}
+		reqs, err := api.QuorumList(ctx, state)
+		if err != nil {
+			return err
+		}
+
+		w := helper.NewTabWriter(cctx.App.Writer)
+		fmt.Fprintln(w, "ID\tOP\tTARGET\tSTATE\tREQUESTER\tCONFIRMER\tCREATED\tDEADLINE")
+		for _, r := range reqs {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n", r.ID, r.Op, r.Target, r.State,
+				callerString(r.Requester), callerString(r.Confirmer),
+				r.CreateAt.Format("2006-01-02 15:04:05"), r.Deadline.Format("2006-01-02 15:04:05"))
+		}
+		return w.Flush()
+	},
+}
+
+var quorumConfirm = &cli.Command{
+	Name:      "confirm",
+	Usage:     "confirm a pending operation as the second admin",
+	ArgsUsage: "<id>",
+	Flags: []cli.Flag{
+		tokenFlag,
+		&cli.StringFlag{
+			Name:  "comment",
+			Usage: "comment saved with the decision",
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		if cctx.NArg() != 1 {
+			return helper.ShowHelp(cctx, errcode.ErrParameterMismatch)
+		}
+		api, closer, err := helper.GetFullAPI(cctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+		ctx := helper.ReqContext(cctx)
+
+		if err := api.QuorumConfirm(ctx, cctx.Args().First(), cctx.String("comment")); err != nil {
+			return err
+		}
+		fmt.Println("confirmed")
+		return nil
+	},
+}
+
+var quorumReject = &cli.Command{
+	Name:      "reject",
+	Usage:     "reject a pending operation",
+	ArgsUsage: "<id>",
+	Flags: []cli.Flag{
+		tokenFlag,
+		&cli.StringFlag{
+			Name:  "comment",
+			Usage: "comment saved with the decision",
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		if cctx.NArg() != 1 {
+			return helper.ShowHelp(cctx, errcode.ErrParameterMismatch)
+		}
+		api, closer, err := helper.GetFullAPI(cctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+		ctx := helper.ReqContext(cctx)
+
+		if err := api.QuorumReject(ctx, cctx.Args().First(), cctx.String("comment")); err != nil {
+			return err
+		}
+		fmt.Println("rejected")
+		return nil
+	},
+}
+
+var quorumAudit = &cli.Command{
+	Name:      "audit",
+	Usage:     "show the audit trail of a quorum request, of all requests without id",
+	ArgsUsage: "[id]",
+	Action: func(cctx *cli.Context) error {
+		api, closer, err := helper.GetFullAPI(cctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+		ctx := helper.ReqContext(cctx)
+
+		audits, err := api.QuorumAudit(ctx, cctx.Args().First())
+		if err != nil {
+			return err
+		}
+
+		w := helper.NewTabWriter(cctx.App.Writer)
+		fmt.Fprintln(w, "TIME\tREQUEST\tACTION\tCALLER\tCOMMENT\tERROR")
+		for _, a := range audits {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", a.CreateAt.Format("2006-01-02 15:04:05"), a.RequestID,
+				a.Action, callerString(a.Caller), a.Comment, a.Err)
+		}
+		return w.Flush()
+	},
+}
@@ -8,7 +8,11 @@ import (
 	"time"
 
 	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
 	"github.com/filecoin-project/venus-wallet/cli/helper"
+	"github.com/filecoin-project/venus-wallet/crypto"
+	"github.com/filecoin-project/venus-wallet/middleware"
+	"github.com/filecoin-project/venus-wallet/storage"
 	"github.com/filecoin-project/venus/venus-shared/types"
 	wallet_types "github.com/filecoin-project/venus/venus-shared/types/wallet"
 	"github.com/urfave/cli/v2"
@@ -19,48 +23,72 @@ var recordCmd = &cli.Command{
 	Usage: "manipulate sign record",
 	Subcommands: []*cli.Command{
 		recordList,
+		recordExport,
+		recordStats,
+		recordAdmin,
+		recordMigrate,
+		recordVerify,
 	},
 }
 
-var recordList = &cli.Command{
-	Name:  "query",
-	Usage: "query sign record",
+var recordVerify = &cli.Command{
+	Name:  "verify",
+	Usage: "check that no sign record was deleted, reordered or modified",
 	Flags: []cli.Flag{
 		&cli.StringFlag{
-			Name:  "address",
-			Usage: "address to query",
-		},
-		&cli.StringFlag{
-			Name:  "type",
-			Usage: "sign type to query",
-		},
-		&cli.TimestampFlag{
-			Name:     "from",
-			Aliases:  []string{"after", "f"},
-			Usage:    "from time to query",
-			Timezone: time.Local,
-			Layout:   "2006-1-2-15:04:05",
-		},
-		&cli.TimestampFlag{
-			Name:     "to",
-			Aliases:  []string{"before"},
-			Timezone: time.Local,
-			Usage:    "to time to query",
-			Layout:   "2006-1-2-15:04:05",
-		},
-		&cli.IntFlag{
-			Name:  "limit",
-			Usage: "limit to query",
+			Name:  "audit-key",
+			Usage: "address expected to sign the checkpoints, the configured audit key by default",
 		},
+	},
+	Action: func(cctx *cli.Context) error {
+		api, closer, err := helper.GetFullAPI(cctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+		ctx := helper.ReqContext(cctx)
+
+		auditKey := address.Undef
+		if cctx.IsSet("audit-key") {
+			auditKey, err = address.NewFromString(cctx.String("audit-key"))
+			if err != nil {
+				return fmt.Errorf("parse audit key: %w", err)
+			}
+		}
+		res, err := api.RecordVerify(ctx, auditKey)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("records checked: %d (seq %d to %d)\n", res.Records, res.FirstSeq, res.LastSeq)
+		if res.Unchained > 0 {
+			fmt.Printf("records written before the chain, not checked: %d\n", res.Unchained)
+		}
+		fmt.Printf("signed checkpoints: %d", res.Checkpoints)
+		if res.Checkpoints > 0 {
+			fmt.Printf(", records after seq %d are not covered by a checkpoint", res.LastCheckpoint)
+		}
+		fmt.Println()
+		if len(res.Problems) == 0 {
+			fmt.Println("the chain is intact")
+			return nil
+		}
+		for _, p := range res.Problems {
+			fmt.Println(p)
+		}
+		return fmt.Errorf("%d problems found", len(res.Problems))
+	},
+}
+
+var recordList = &cli.Command{
+	Name:  "query",
+	Usage: "query sign record",
+	Flags: append([]cli.Flag{
 		&cli.IntFlag{
 			Name:    "offset",
 			Aliases: []string{"skip"},
 			Usage:   "offset to query",
 		},
-		&cli.BoolFlag{
-			Name:  "error",
-			Usage: "query error record",
-		},
 		&cli.StringFlag{
 			Name:  "id",
 			Usage: "query record by id",
@@ -70,9 +98,9 @@ var recordList = &cli.Command{
 			Usage:   "verbose output",
 			Aliases: []string{"v"},
 		},
-	},
+	}, append(recordFilterFlags, chainMsgFilterFlags...)...),
 	Action: func(cctx *cli.Context) error {
-		api, closer, err := helper.GetAPI(cctx)
+		api, closer, err := helper.GetFullAPI(cctx)
 		if err != nil {
 			return err
 		}
@@ -80,54 +108,19 @@ var recordList = &cli.Command{
 
 		ctx := helper.ReqContext(cctx)
 
-		QueryParams := types.QuerySignRecordParams{}
-
-		if cctx.IsSet("address") {
-			addrStr := cctx.String("address")
-			addr, err := address.NewFromString(addrStr)
-			if err != nil {
-				return fmt.Errorf("parse address %s : %w", addrStr, err)
-			}
-			QueryParams.Signer = addr
-		}
-
-		if cctx.IsSet("type") {
-			t := types.MsgType(cctx.String("type"))
-			_, ok := wallet_types.SupportedMsgTypes[t]
-			if !ok {
-
-				fmt.Println("supported types:")
-				for k := range wallet_types.SupportedMsgTypes {
-					fmt.Println(k)
-				}
-				return fmt.Errorf("unsupported type %s", t)
-			}
-			QueryParams.Type = t
-		}
-		if cctx.IsSet("from") {
-			from := cctx.Timestamp("from")
-			QueryParams.After = *from
-		}
-		if cctx.IsSet("to") {
-			to := cctx.Timestamp("to")
-			QueryParams.Before = *to
-		}
-		if cctx.IsSet("limit") {
-			limit := cctx.Int("limit")
-			QueryParams.Limit = limit
+		QueryParams, err := recordQueryParams(cctx)
+		if err != nil {
+			return err
 		}
 		if cctx.IsSet("offset") {
 			offset := cctx.Int("offset")
 			QueryParams.Skip = offset
 		}
-		if cctx.IsSet("error") {
-			QueryParams.IsError = cctx.Bool("error")
-		}
 		if cctx.IsSet("id") {
 			QueryParams.ID = cctx.String("id")
 		}
 
-		records, err := api.ListSignedRecord(ctx, &QueryParams)
+		records, err := api.RecordQuery(ctx, QueryParams)
 		if err != nil {
 			return fmt.Errorf("query sign record: %w", err)
 		}
@@ -135,8 +128,10 @@ var recordList = &cli.Command{
 		if cctx.Bool("verbose") {
 			output := make([]interface{}, len(records))
 			type temp struct {
-				types.SignRecord
+				storage.SignRecord
 				Detail json.RawMessage
+				// Verified the signature is valid for the signer and the signed bytes
+				Verified bool
 			}
 
 			for i, r := range records {
@@ -147,6 +142,7 @@ var recordList = &cli.Command{
 				output[i] = temp{
 					SignRecord: r,
 					Detail:     detail,
+					Verified:   r.Signature != nil && crypto.Verify(r.Signature, r.Signer, r.ToSign) == nil,
 				}
 			}
 
@@ -154,13 +150,18 @@ var recordList = &cli.Command{
 		} else {
 			// output in table format
 			w := helper.NewTabWriter(cctx.App.Writer)
-			fmt.Fprintln(w, "SIGNER\tTYPE\tTIME\tERROR")
+			fmt.Fprintln(w, "SIGNER\tTYPE\tTIME\tCALLER\tDURATION\tCID\tSUMMARY\tERROR")
 			for _, r := range records {
 				errStr := "no error"
-				if r.Err != nil {
-					errStr = r.Err.Error()
+				if r.Err != "" {
+					errStr = r.Err
+				}
+				msgCID := r.MsgCID
+				if msgCID == "" {
+					msgCID = "-"
 				}
-				fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", r.Signer, r.Type, r.CreateAt, errStr)
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n", r.Signer, r.Type, r.CreateAt, callerString(r.Caller),
+					r.Duration, msgCID, r.Summary, errStr)
 			}
 			w.Flush()
 		}
@@ -169,7 +170,165 @@ var recordList = &cli.Command{
 	},
 }
 
-func GetDetailInJsonRawMessage(r *types.SignRecord) (json.RawMessage, error) {
+// recordFilterFlags the flags selecting the records, see recordQueryParams
+var recordFilterFlags = []cli.Flag{
+	&cli.StringFlag{
+		Name:  "address",
+		Usage: "address to query",
+	},
+	&cli.StringFlag{
+		Name:  "type",
+		Usage: "sign type to query",
+	},
+	&cli.TimestampFlag{
+		Name:     "from",
+		Aliases:  []string{"after", "f"},
+		Usage:    "from time to query",
+		Timezone: time.Local,
+		Layout:   "2006-1-2-15:04:05",
+	},
+	&cli.TimestampFlag{
+		Name:     "to",
+		Aliases:  []string{"before"},
+		Timezone: time.Local,
+		Usage:    "to time to query",
+		Layout:   "2006-1-2-15:04:05",
+	},
+	&cli.DurationFlag{
+		Name:  "since",
+		Usage: "query records of the last duration, eg. 168h, overrides --from",
+	},
+	&cli.IntFlag{
+		Name:  "limit",
+		Usage: "limit to query",
+	},
+	&cli.BoolFlag{
+		Name:  "error",
+		Usage: "query error record",
+	},
+}
+
+// chainMsgFilterFlags the flags selecting the chain messages by their decoded fields, see recordQueryParams
+var chainMsgFilterFlags = []cli.Flag{
+	&cli.StringFlag{
+		Name:  "msg-to",
+		Usage: "query chain messages sent to the address",
+	},
+	&cli.Uint64Flag{
+		Name:  "method",
+		Usage: "query chain messages calling the method number",
+	},
+	&cli.Uint64Flag{
+		Name:  "nonce",
+		Usage: "query chain messages with the nonce",
+	},
+	&cli.StringFlag{
+		Name:  "min-value",
+		Usage: "query chain messages sending at least the value, eg. 1.5FIL",
+	},
+	&cli.StringFlag{
+		Name:  "max-value",
+		Usage: "query chain messages sending at most the value, eg. 10FIL",
+	},
+	&cli.StringFlag{
+		Name:  "cid",
+		Usage: "query the chain message by cid",
+	},
+}
+
+func recordQueryParams(cctx *cli.Context) (*storage.RecordQueryParams, error) {
+	QueryParams := &storage.RecordQueryParams{}
+
+	if cctx.IsSet("address") {
+		addrStr := cctx.String("address")
+		addr, err := address.NewFromString(addrStr)
+		if err != nil {
+			return nil, fmt.Errorf("parse address %s : %w", addrStr, err)
+		}
+		QueryParams.Signer = addr
+	}
+
+	if cctx.IsSet("type") {
+		t := types.MsgType(cctx.String("type"))
+		_, ok := wallet_types.SupportedMsgTypes[t]
+		if !ok {
+
+			fmt.Println("supported types:")
+			for k := range wallet_types.SupportedMsgTypes {
+				fmt.Println(k)
+			}
+			return nil, fmt.Errorf("unsupported type %s", t)
+		}
+		QueryParams.Type = t
+	}
+	if cctx.IsSet("from") {
+		from := cctx.Timestamp("from")
+		QueryParams.After = *from
+	}
+	if cctx.IsSet("since") {
+		QueryParams.After = time.Now().Add(-cctx.Duration("since"))
+	}
+	if cctx.IsSet("to") {
+		to := cctx.Timestamp("to")
+		QueryParams.Before = *to
+	}
+	if cctx.IsSet("limit") {
+		limit := cctx.Int("limit")
+		QueryParams.Limit = limit
+	}
+	if cctx.IsSet("error") {
+		QueryParams.IsError = cctx.Bool("error")
+	}
+
+	if cctx.IsSet("msg-to") {
+		addr, err := address.NewFromString(cctx.String("msg-to"))
+		if err != nil {
+			return nil, fmt.Errorf("parse msg-to %s : %w", cctx.String("msg-to"), err)
+		}
+		QueryParams.To = addr
+	}
+	if cctx.IsSet("method") {
+		method := abi.MethodNum(cctx.Uint64("method"))
+		QueryParams.Method = &method
+	}
+	if cctx.IsSet("nonce") {
+		nonce := cctx.Uint64("nonce")
+		QueryParams.Nonce = &nonce
+	}
+	for name, v := range map[string]**abi.TokenAmount{"min-value": &QueryParams.MinValue, "max-value": &QueryParams.MaxValue} {
+		if !cctx.IsSet(name) {
+			continue
+		}
+		value, err := types.ParseFIL(cctx.String(name))
+		if err != nil {
+			return nil, fmt.Errorf("parse %s: %w", name, err)
+		}
+		amount := abi.TokenAmount(value)
+		*v = &amount
+	}
+	if cctx.IsSet("cid") {
+		QueryParams.MsgCID = cctx.String("cid")
+	}
+	return QueryParams, nil
+}
+
+// callerString a short description of the caller: the token name or id, or the gateway
+func callerString(caller *middleware.Caller) string {
+	switch {
+	case caller == nil:
+		return "-"
+	case caller.Gateway != "":
+		return "gateway " + caller.Gateway
+	case caller.Name != "":
+		return caller.Name
+	case caller.TokenID != "":
+		return "token " + caller.TokenID[:8]
+	default:
+		return caller.RemoteAddr
+	}
+}
+
+func GetDetailInJsonRawMessage(r *storage.SignRecord) (json.RawMessage, error) {
 	t, ok := wallet_types.SupportedMsgTypes[r.Type]
 	if !ok {
 		return nil, fmt.Errorf("unsupported type %s", r.Type)
@@ -0,0 +1,122 @@
+package cli
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/filecoin-project/venus-wallet/cli/helper"
+	"github.com/filecoin-project/venus-wallet/storage"
+)
+
+var recordAdmin = &cli.Command{
+	Name:  "admin",
+	Usage: "query the records of the administrative operations: key creation, import, export and deletion, password, lock, tokens and support accounts",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  "op",
+			Usage: fmt.Sprintf("operation to query, one of: %v", storage.AdminOps),
+		},
+		&cli.StringFlag{
+			Name:  "target",
+			Usage: "address, token name or account the operation is about",
+		},
+		&cli.TimestampFlag{
+			Name:     "from",
+			Aliases:  []string{"after", "f"},
+			Usage:    "from time to query",
+			Timezone: time.Local,
+			Layout:   "2006-1-2-15:04:05",
+		},
+		&cli.TimestampFlag{
+			Name:     "to",
+			Aliases:  []string{"before"},
+			Usage:    "to time to query",
+			Timezone: time.Local,
+			Layout:   "2006-1-2-15:04:05",
+		},
+		&cli.DurationFlag{
+			Name:  "since",
+			Usage: "query records of the last duration, eg. 168h, overrides --from",
+		},
+		&cli.BoolFlag{
+			Name:  "error",
+			Usage: "query the failed operations",
+		},
+		&cli.IntFlag{
+			Name:  "limit",
+			Usage: "limit to query",
+		},
+		&cli.IntFlag{
+			Name:    "offset",
+			Aliases: []string{"skip"},
+			Usage:   "offset to query",
+		},
+		&cli.BoolFlag{
+			Name:  "json",
+			Usage: "output in json",
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		params := &storage.AdminQueryParams{
+			Op:      storage.AdminOp(cctx.String("op")),
+			Target:  cctx.String("target"),
+			IsError: cctx.Bool("error"),
+			Limit:   cctx.Int("limit"),
+			Skip:    cctx.Int("offset"),
+		}
+		if params.Op != "" && !isAdminOp(params.Op) {
+			return fmt.Errorf("unsupported op %s, supported: %v", params.Op, storage.AdminOps)
+		}
+		if cctx.IsSet("from") {
+			params.After = *cctx.Timestamp("from")
+		}
+		if cctx.IsSet("since") {
+			params.After = time.Now().Add(-cctx.Duration("since"))
+		}
+		if cctx.IsSet("to") {
+			params.Before = *cctx.Timestamp("to")
+		}
+
+		api, closer, err := helper.GetFullAPI(cctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+		ctx := helper.ReqContext(cctx)
+
+		records, err := api.RecordAdminQuery(ctx, params)
+		if err != nil {
+			return fmt.Errorf("query admin record: %w", err)
+		}
+		if cctx.Bool("json") {
+			return helper.PrintJSON(records)
+		}
+
+		w := helper.NewTabWriter(cctx.App.Writer)
+		fmt.Fprintln(w, "TIME\tOP\tTARGET\tCALLER\tDETAIL\tERROR")
+		for _, r := range records {
+			errStr := "no error"
+			if r.Err != "" {
+				errStr = r.Err
+			}
+			target := r.Target
+			if target == "" {
+				target = "-"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", r.CreateAt.Format(time.DateTime), r.Op, target,
+				callerString(r.Caller), r.Detail, errStr)
+		}
+		return w.Flush()
+	},
+}
+
+func isAdminOp(op storage.AdminOp) bool {
+	for _, o := range storage.AdminOps {
+		if o == op {
+			return true
+		}
+	}
+	return false
+}
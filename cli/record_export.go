@@ -0,0 +1,187 @@
+package cli
+
+import (
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/filecoin-project/venus-wallet/cli/helper"
+	"github.com/filecoin-project/venus-wallet/storage"
+)
+
+var recordExport = &cli.Command{
+	Name:  "export",
+	Usage: "export sign records with the decoded messages, from the oldest one",
+	Flags: append([]cli.Flag{
+		&cli.StringFlag{
+			Name:  "format",
+			Usage: "output format, one of: csv, jsonl",
+			Value: "jsonl",
+		},
+		&cli.StringFlag{
+			Name:    "output",
+			Aliases: []string{"o"},
+			Usage:   "output file, stdout if not set",
+		},
+	}, append(recordFilterFlags, chainMsgFilterFlags...)...),
+	Action: func(cctx *cli.Context) error {
+		params, err := recordQueryParams(cctx)
+		if err != nil {
+			return err
+		}
+
+		var out io.Writer = cctx.App.Writer
+		if cctx.IsSet("output") {
+			f, err := os.Create(cctx.String("output"))
+			if err != nil {
+				return err
+			}
+			defer f.Close() //nolint:errcheck
+			out = f
+		}
+		var w recordWriter
+		switch cctx.String("format") {
+		case "csv":
+			w = newCSVRecordWriter(out)
+		case "jsonl":
+			w = &jsonlRecordWriter{enc: json.NewEncoder(out)}
+		default:
+			return fmt.Errorf("unsupported format %s", cctx.String("format"))
+		}
+
+		api, closer, err := helper.GetFullAPIStream(cctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+		ctx := helper.ReqContext(cctx)
+
+		items, err := api.RecordExport(ctx, params)
+		if err != nil {
+			return fmt.Errorf("export sign record: %w", err)
+		}
+		count, done := 0, false
+		for item := range items {
+			if item.Err != "" {
+				return fmt.Errorf("export sign record: %s", item.Err)
+			}
+			if item.Done {
+				done = true
+				continue
+			}
+			if item.Record == nil {
+				continue
+			}
+			if err := w.Write(item.Record); err != nil {
+				return err
+			}
+			count++
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+		if !done {
+			return fmt.Errorf("export interrupted after %d records", count)
+		}
+		_, _ = fmt.Fprintf(cctx.App.ErrWriter, "%d records exported\n", count)
+		return nil
+	},
+}
+
+type recordWriter interface {
+	Write(r *storage.SignRecord) error
+	Flush() error
+}
+
+// recordDetail the decoded message, null if it can't be decoded
+func recordDetail(r *storage.SignRecord) json.RawMessage {
+	detail, err := GetDetailInJsonRawMessage(r)
+	if err != nil {
+		return json.RawMessage("null")
+	}
+	return detail
+}
+
+type jsonlRecordWriter struct {
+	enc *json.Encoder
+}
+
+func (w *jsonlRecordWriter) Write(r *storage.SignRecord) error {
+	return w.enc.Encode(struct {
+		*storage.SignRecord
+		Detail json.RawMessage
+	}{
+		SignRecord: r,
+		Detail:     recordDetail(r),
+	})
+}
+
+func (w *jsonlRecordWriter) Flush() error {
+	return nil
+}
+
+var csvRecordHeader = []string{"id", "time", "signer", "type", "caller", "duration_ms", "msg_cid",
+	"summary", "error", "signature", "to_sign", "seq", "hash", "detail"}
+
+type csvRecordWriter struct {
+	w      *csv.Writer
+	header bool
+}
+
+func newCSVRecordWriter(out io.Writer) *csvRecordWriter {
+	return &csvRecordWriter{w: csv.NewWriter(out)}
+}
+
+func (w *csvRecordWriter) Write(r *storage.SignRecord) error {
+	if !w.header {
+		if err := w.w.Write(csvRecordHeader); err != nil {
+			return err
+		}
+		w.header = true
+	}
+	signature := ""
+	if r.Signature != nil {
+		signature = hex.EncodeToString(append([]byte{byte(r.Signature.Type)}, r.Signature.Data...))
+	}
+	caller := ""
+	if r.Caller != nil {
+		caller = callerString(r.Caller)
+	}
+	err := w.w.Write([]string{
+		r.ID,
+		r.CreateAt.Format(time.RFC3339Nano),
+		r.Signer.String(),
+		string(r.Type),
+		caller,
+		strconv.FormatInt(r.Duration.Milliseconds(), 10),
+		r.MsgCID,
+		r.Summary,
+		r.Err,
+		signature,
+		hex.EncodeToString(r.ToSign),
+		strconv.FormatUint(r.Seq, 10),
+		hex.EncodeToString(r.Hash),
+		string(recordDetail(r)),
+	})
+	return err
+}
+
+func (w *csvRecordWriter) Flush() error {
+	if !w.header {
+		if err := w.w.Write(csvRecordHeader); err != nil {
+			return err
+		}
+	}
+	w.w.Flush()
+	if err := w.w.Error(); err != nil {
+		return fmt.Errorf("write csv: %w", err)
+	}
+	return nil
+}
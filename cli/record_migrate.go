@@ -0,0 +1,56 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/mitchellh/go-homedir"
+	"github.com/urfave/cli/v2"
+
+	"github.com/filecoin-project/venus-wallet/cli/helper"
+	"github.com/filecoin-project/venus-wallet/filemgr"
+	"github.com/filecoin-project/venus-wallet/storage/sqlite"
+)
+
+var recordMigrate = &cli.Command{
+	Name:  "migrate",
+	Usage: "move the records from the keystore database to the database of SignRecorder.DB, the wallet must be stopped",
+	Action: func(cctx *cli.Context) error {
+		// records written while moving would be lost
+		if _, _, err := helper.GetRawAPI(cctx); err == nil {
+			return errors.New("the wallet is running, stop it first")
+		}
+
+		path, err := homedir.Expand(cctx.String("repo"))
+		if err != nil {
+			return err
+		}
+		repo, err := filemgr.NewFS(path, nil)
+		if err != nil {
+			return fmt.Errorf("open repo %s: %w", path, err)
+		}
+		cnf := repo.Config()
+		if cnf.SignRecorder == nil || cnf.SignRecorder.DB == nil || cnf.SignRecorder.DB.Conn == "" {
+			return errors.New("SignRecorder.DB is not set in the config")
+		}
+		if cnf.SignRecorder.DB.Conn == cnf.DB.Conn {
+			return errors.New("SignRecorder.DB is the keystore database")
+		}
+
+		keystore, err := sqlite.NewDB(cnf.DB)
+		if err != nil {
+			return err
+		}
+		recordDB, err := sqlite.OpenRecordDB(cnf.SignRecorder.DB)
+		if err != nil {
+			return err
+		}
+		moved, err := sqlite.MigrateRecords(keystore, recordDB)
+		if err != nil {
+			return fmt.Errorf("migrate records: %w", err)
+		}
+		fmt.Printf("moved to %s: %d sign records, %d checkpoints, %d admin records\n", cnf.SignRecorder.DB.Conn,
+			moved.SignRecords, moved.Checkpoints, moved.AdminRecords)
+		return nil
+	},
+}
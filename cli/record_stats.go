@@ -0,0 +1,83 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/filecoin-project/venus/venus-shared/types"
+	"github.com/urfave/cli/v2"
+
+	"github.com/filecoin-project/venus-wallet/cli/helper"
+	"github.com/filecoin-project/venus-wallet/storage"
+)
+
+var recordStats = &cli.Command{
+	Name:  "stats",
+	Usage: "count sign records by signer, type, error and time",
+	Flags: append([]cli.Flag{
+		&cli.StringFlag{
+			Name:  "bucket",
+			Usage: "count the records per time bucket, one of: hour, day",
+		},
+		&cli.IntFlag{
+			Name:  "top-errors",
+			Usage: "number of distinct errors shown",
+			Value: 20,
+		},
+		&cli.BoolFlag{
+			Name:  "json",
+			Usage: "output in json",
+		},
+	}, recordFilterFlags...),
+	Action: func(cctx *cli.Context) error {
+		query, err := recordQueryParams(cctx)
+		if err != nil {
+			return err
+		}
+		api, closer, err := helper.GetFullAPI(cctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+		ctx := helper.ReqContext(cctx)
+
+		stats, err := api.RecordStats(ctx, &storage.RecordStatsParams{
+			Signer:    query.Signer,
+			Type:      query.Type,
+			After:     query.After,
+			Before:    query.Before,
+			Bucket:    storage.StatsBucket(cctx.String("bucket")),
+			TopErrors: cctx.Int("top-errors"),
+		})
+		if err != nil {
+			return err
+		}
+		if cctx.Bool("json") {
+			return helper.PrintJSON(stats)
+		}
+
+		w := helper.NewTabWriter(cctx.App.Writer)
+		fmt.Fprintf(w, "total: %d\terrors: %d\n", stats.Total, stats.Errors)
+		printCounts := func(title string, counts []storage.RecordCount) {
+			fmt.Fprintf(w, "\n%s\tCOUNT\tERRORS\n", title)
+			for _, c := range counts {
+				fmt.Fprintf(w, "%s\t%d\t%d\n", c.Key, c.Count, c.Errors)
+			}
+		}
+		printCounts("SIGNER", stats.BySigner)
+		printCounts("TYPE", stats.ByType)
+		printCounts("ERROR", stats.ByError)
+		if len(stats.Buckets) > 0 {
+			fmt.Fprintf(w, "\nFROM\tCOUNT\tERRORS\n")
+			for _, b := range stats.Buckets {
+				fmt.Fprintf(w, "%s\t%d\t%d\n", b.Start.Local().Format("2006-01-02 15:04"), b.Count, b.Errors)
+			}
+		}
+		if len(stats.ChainMsg) > 0 {
+			fmt.Fprintf(w, "\nSIGNER\tMESSAGES\tVALUE\tMAX FEE\n")
+			for _, s := range stats.ChainMsg {
+				fmt.Fprintf(w, "%s\t%d\t%s\t%s\n", s.Signer, s.Count, types.FIL(s.Value), types.FIL(s.MaxFee))
+			}
+		}
+		return w.Flush()
+	},
+}
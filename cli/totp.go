@@ -0,0 +1,132 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/howeyc/gopass"
+	"github.com/urfave/cli/v2"
+
+	"github.com/filecoin-project/venus-wallet/cli/helper"
+	"github.com/filecoin-project/venus-wallet/errcode"
+)
+
+var totpCmd = &cli.Command{
+	Name:  "totp",
+	Usage: "manage the one-time codes required by unlock, export, del and set-password",
+	Subcommands: []*cli.Command{
+		totpEnroll,
+		totpConfirm,
+		totpDisable,
+		totpStatus,
+	},
+}
+
+var totpEnroll = &cli.Command{
+	Name:  "enroll",
+	Usage: "generate the totp secret and the recovery codes, activate them with `totp confirm`",
+	Action: func(cctx *cli.Context) error {
+		pw, err := gopass.GetPasswdPrompt("Password:", true, os.Stdin, os.Stdout)
+		if err != nil {
+			return err
+		}
+
+		api, closer, err := helper.GetFullAPI(cctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+		ctx := helper.ReqContext(cctx)
+
+		enrollment, err := api.TOTPEnroll(ctx, string(pw))
+		if err != nil {
+			return err
+		}
+		fmt.Printf("secret: %s\n", enrollment.Secret)
+		fmt.Printf("uri:    %s\n", enrollment.URI)
+		fmt.Println("recovery codes, each works once, keep them offline:")
+		for _, code := range enrollment.RecoveryCodes {
+			fmt.Printf("  %s\n", code)
+		}
+		fmt.Println("add the secret to the authenticator app, then run `totp confirm <code>`")
+		return nil
+	},
+}
+
+var totpConfirm = &cli.Command{
+	Name:      "confirm",
+	Usage:     "activate the enrollment with a code from the authenticator app",
+	ArgsUsage: "<code>",
+	Action: func(cctx *cli.Context) error {
+		if cctx.NArg() != 1 {
+			return helper.ShowHelp(cctx, errcode.ErrParameterMismatch)
+		}
+		api, closer, err := helper.GetFullAPI(cctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+		ctx := helper.ReqContext(cctx)
+
+		if err := api.TOTPConfirm(ctx, cctx.Args().First()); err != nil {
+			return err
+		}
+		fmt.Println("totp enrolled")
+		return nil
+	},
+}
+
+var totpDisable = &cli.Command{
+	Name:  "disable",
+	Usage: "remove the second factor",
+	Action: func(cctx *cli.Context) error {
+		pw, err := gopass.GetPasswdPrompt("Password:", true, os.Stdin, os.Stdout)
+		if err != nil {
+			return err
+		}
+
+		api, closer, err := helper.GetFullAPIWithOTP(cctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+		ctx := helper.ReqContext(cctx)
+
+		if err := api.TOTPDisable(ctx, string(pw)); err != nil {
+			return err
+		}
+		fmt.Println("totp disabled")
+		return nil
+	},
+}
+
+var totpStatus = &cli.Command{
+	Name:  "status",
+	Usage: "show whether the one-time code is required",
+	Action: func(cctx *cli.Context) error {
+		api, closer, err := helper.GetFullAPI(cctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+		ctx := helper.ReqContext(cctx)
+
+		status, err := api.TOTPStatus(ctx)
+		if err != nil {
+			return err
+		}
+		switch {
+		case status.Enrolled:
+			fmt.Printf("enrolled, %d recovery codes left\n", status.RecoveryCodesLeft)
+			if time.Now().Before(status.LockedUntil) {
+				fmt.Printf("locked out after too many invalid codes until %s\n", status.LockedUntil.Format(time.RFC3339))
+			}
+		case status.Pending:
+			fmt.Println("pending, run `totp confirm <code>`")
+		default:
+			fmt.Println("not enrolled")
+		}
+		return nil
+	},
+}
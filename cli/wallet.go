@@ -13,6 +13,8 @@ import (
 	"github.com/filecoin-project/go-address"
 	"github.com/filecoin-project/venus-wallet/cli/helper"
 	"github.com/filecoin-project/venus-wallet/errcode"
+	"github.com/filecoin-project/venus-wallet/storage"
+	"github.com/filecoin-project/venus-wallet/storage/wallet"
 	"github.com/filecoin-project/venus/venus-shared/types"
 	"github.com/howeyc/gopass"
 	"github.com/urfave/cli/v2"
@@ -23,12 +25,6 @@ var walletSetPassword = &cli.Command{
 	Aliases: []string{"setpwd"},
 	Usage:   "Store a credential for a keystore file",
 	Action: func(cctx *cli.Context) error {
-		api, closer, err := helper.GetFullAPI(cctx)
-		if err != nil {
-			return err
-		}
-		defer closer()
-
 		pw, err := gopass.GetPasswdPrompt("Password:", true, os.Stdin, os.Stdout)
 		if err != nil {
 			return err
@@ -41,6 +37,12 @@ var walletSetPassword = &cli.Command{
 			return errors.New("the input passwords are inconsistent")
 		}
 
+		api, closer, err := helper.GetFullAPIWithOTP(cctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+
 		ctx := helper.ReqContext(cctx)
 		err = api.SetPassword(ctx, string(pw2))
 		if err != nil {
@@ -55,16 +57,16 @@ var walletUnlock = &cli.Command{
 	Name:  "unlock",
 	Usage: "Unlock the wallet private key, so that it can be used for signing",
 	Action: func(cctx *cli.Context) error {
-		api, closer, err := helper.GetFullAPI(cctx)
+		pw, err := gopass.GetPasswdPrompt("Password:", true, os.Stdin, os.Stdout)
 		if err != nil {
 			return err
 		}
-		defer closer()
 
-		pw, err := gopass.GetPasswdPrompt("Password:", true, os.Stdin, os.Stdout)
+		api, closer, err := helper.GetFullAPIWithOTP(cctx)
 		if err != nil {
 			return err
 		}
+		defer closer()
 
 		ctx := helper.ReqContext(cctx)
 		err = api.Unlock(ctx, string(pw))
@@ -125,6 +127,9 @@ var walletNew = &cli.Command{
 	Name:      "new",
 	Usage:     "Generate a new key of the given type",
 	ArgsUsage: "[bls|secp256k1|delegated (default secp256k1)]",
+	Flags: []cli.Flag{
+		noExportFlag,
+	},
 	Action: func(cctx *cli.Context) error {
 		t := types.KeyType(cctx.Args().First())
 		if t == "" {
@@ -136,7 +141,7 @@ var walletNew = &cli.Command{
 		}
 		ctx := helper.ReqContext(cctx)
 		defer closer()
-		nk, err := api.WalletNew(ctx, t)
+		nk, err := api.WalletNewWithOptions(ctx, t, wallet.KeyOptions{NoExport: cctx.Bool("no-export")})
 		if err != nil {
 			return err
 		}
@@ -145,6 +150,11 @@ var walletNew = &cli.Command{
 	},
 }
 
+var noExportFlag = &cli.BoolFlag{
+	Name:  "no-export",
+	Usage: "the key can never be exported, this can't be undone",
+}
+
 var walletList = &cli.Command{
 	Name:    "list",
 	Aliases: []string{"ls"},
@@ -156,14 +166,115 @@ var walletList = &cli.Command{
 		}
 		defer closer()
 		ctx := helper.ReqContext(cctx)
-		addrs, err := api.WalletList(ctx)
+		metas, err := api.WalletListMeta(ctx)
 		if err != nil {
 			return err
 		}
 
-		for _, addr := range addrs {
-			fmt.Println(addr.String())
+		w := helper.NewTabWriter(cctx.App.Writer)
+		for _, meta := range metas {
+			fields := []string{meta.Address.String()}
+			if len(meta.Roles) != 0 {
+				roles := make([]string, 0, len(meta.Roles))
+				for _, role := range meta.Roles {
+					roles = append(roles, string(role))
+				}
+				fields = append(fields, "roles: "+strings.Join(roles, ","))
+			}
+			if meta.Disabled {
+				fields = append(fields, "disabled")
+			}
+			if meta.NoExport {
+				fields = append(fields, "no-export")
+			}
+			fmt.Fprintln(w, strings.Join(fields, "\t"))
 		}
+		return w.Flush()
+	},
+}
+
+var walletDisable = &cli.Command{
+	Name:      "disable",
+	Usage:     "Suspend signing with a key, the key is kept in the keystore",
+	ArgsUsage: "<address>",
+	Action: func(cctx *cli.Context) error {
+		return setKeyDisabled(cctx, true)
+	},
+}
+
+var walletEnable = &cli.Command{
+	Name:      "enable",
+	Usage:     "Resume signing with a disabled key",
+	ArgsUsage: "<address>",
+	Action: func(cctx *cli.Context) error {
+		return setKeyDisabled(cctx, false)
+	},
+}
+
+func setKeyDisabled(cctx *cli.Context, disabled bool) error {
+	if cctx.NArg() != 1 {
+		return helper.ShowHelp(cctx, errcode.ErrParameterMismatch)
+	}
+	addr, err := address.NewFromString(cctx.Args().First())
+	if err != nil {
+		return err
+	}
+
+	api, closer, err := helper.GetFullAPI(cctx)
+	if err != nil {
+		return err
+	}
+	defer closer()
+
+	ctx := helper.ReqContext(cctx)
+	if disabled {
+		err = api.WalletDisable(ctx, addr)
+	} else {
+		err = api.WalletEnable(ctx, addr)
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Println("success")
+	return nil
+}
+
+var walletSetRoles = &cli.Command{
+	Name:  "set-roles",
+	Usage: "Restrict the message types a key may sign by its roles",
+	Description: fmt.Sprintf(`Roles: %v. A key without roles may sign any message type.
+
+   eg) set-roles f3xxx worker
+       set-roles f3xxx        (clear the roles)`, storage.KeyRoles),
+	ArgsUsage: "<address> [role...]",
+	Action: func(cctx *cli.Context) error {
+		if !cctx.Args().Present() {
+			return helper.ShowHelp(cctx, errcode.ErrParameterMismatch)
+		}
+		addr, err := address.NewFromString(cctx.Args().First())
+		if err != nil {
+			return err
+		}
+		roles := make([]storage.KeyRole, 0, cctx.NArg()-1)
+		for _, s := range cctx.Args().Tail() {
+			role, err := storage.ParseKeyRole(s)
+			if err != nil {
+				return err
+			}
+			roles = append(roles, role)
+		}
+
+		api, closer, err := helper.GetFullAPI(cctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		ctx := helper.ReqContext(cctx)
+		if err := api.WalletSetRoles(ctx, addr, roles); err != nil {
+			return err
+		}
+		fmt.Println("success")
 		return nil
 	},
 }
@@ -180,16 +291,16 @@ var walletExport = &cli.Command{
 		if err != nil {
 			return err
 		}
-		api, closer, err := helper.GetFullAPI(cctx)
+		pw, err := gopass.GetPasswdPrompt("Password:", true, os.Stdin, os.Stdout)
 		if err != nil {
 			return err
 		}
-		defer closer()
 
-		pw, err := gopass.GetPasswdPrompt("Password:", true, os.Stdin, os.Stdout)
+		api, closer, err := helper.GetFullAPIWithOTP(cctx)
 		if err != nil {
 			return err
 		}
+		defer closer()
 
 		ctx := helper.ReqContext(cctx)
 		if err := api.VerifyPassword(ctx, string(pw)); err != nil {
@@ -219,6 +330,7 @@ var walletImport = &cli.Command{
 			Usage: "specify input format for key",
 			Value: "hex-venus",
 		},
+		noExportFlag,
 	},
 	Action: func(cctx *cli.Context) error {
 		var inpdata []byte
@@ -288,7 +400,7 @@ var walletImport = &cli.Command{
 		}
 		defer closer()
 		ctx := helper.ReqContext(cctx)
-		addr, err := api.WalletImport(ctx, &ki)
+		addr, err := api.WalletImportWithOptions(ctx, &ki, wallet.KeyOptions{NoExport: cctx.Bool("no-export")})
 		if err != nil {
 			return err
 		}
@@ -368,16 +480,16 @@ var walletDel = &cli.Command{
 			return err
 		}
 
-		api, closer, err := helper.GetFullAPI(cctx)
+		pw, err := gopass.GetPasswdPrompt("Password:", true, os.Stdin, os.Stdout)
 		if err != nil {
 			return err
 		}
-		defer closer()
 
-		pw, err := gopass.GetPasswdPrompt("Password:", true, os.Stdin, os.Stdout)
+		api, closer, err := helper.GetFullAPIWithOTP(cctx)
 		if err != nil {
 			return err
 		}
+		defer closer()
 
 		ctx := helper.ReqContext(cctx)
 		if err := api.VerifyPassword(ctx, string(pw)); err != nil {
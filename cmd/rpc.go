@@ -18,8 +18,8 @@ import (
 	"github.com/filecoin-project/venus-wallet/api"
 	"github.com/filecoin-project/venus-wallet/api/remotecli/httpparse"
 	"github.com/filecoin-project/venus-wallet/build"
+	"github.com/filecoin-project/venus-wallet/middleware"
 	"github.com/filecoin-project/venus/venus-shared/api/permission"
-	shared "github.com/filecoin-project/venus/venus-shared/api/wallet"
 	logging "github.com/ipfs/go-log/v2"
 	"github.com/multiformats/go-multiaddr"
 	manet "github.com/multiformats/go-multiaddr/net"
@@ -75,12 +75,23 @@ func ServeRPC(a api.IFullAPI, stop build.StopFunc, addr string, sigChan chan os.
 	}()
 	signal.Notify(sigChan, syscall.SIGTERM, syscall.SIGINT)
 
+	// SIGUSR1 panic locks the wallet, the tokens are kept so that the operator can still unlock remotely
+	panicChan := make(chan os.Signal, 1)
+	signal.Notify(panicChan, syscall.SIGUSR1)
+	go func() {
+		for range panicChan {
+			if err := a.WalletPanicLock(context.Background(), "SIGUSR1", false); err != nil {
+				log.Errorf("panic lock: %s", err)
+			}
+		}
+	}()
+
 	log.Infof("start rpc server at [%s] ...", addr)
 	return srv.Serve(manet.NetListener(lst))
 }
 
 func permissionedFullAPI(a api.IFullAPI) api.IFullAPI {
-	var out shared.IFullAPIStruct
+	var out api.FullAPIStruct
 	permission.PermissionProxy(a, &out)
 	return &out
 }
@@ -94,6 +105,7 @@ type Handler struct {
 // JWT verify
 func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
+	caller := &middleware.Caller{RemoteAddr: r.RemoteAddr}
 	token := r.Header.Get(httpparse.ServiceToken)
 	if token == "" {
 		token = r.FormValue("token")
@@ -124,6 +136,14 @@ func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		// todo venus-auth 中定义的 permKey(=2) 和 go-jsonrpc 库中 permCtxKey(0) 不一致, 且 CtxWithPerm 函数参数和 Verify 的返回值不一致, 应考虑一致性, 还有如果把 permCtxKey 等统一用 venus-auth中的话, 是不是 go-jsonrpc 可以用 filecoin 官方的,而不再自己维护?
 		ctx = core.CtxWithPerms(ctx, allow)
 		//ctx = auth.WithPerm(ctx, allow)
+		caller.TokenID = middleware.TokenID(token)
+		caller.Name = middleware.TokenName(token)
+		caller.IssuedAt = middleware.TokenIssuedAt(token)
+		caller.Perms = allow
+	}
+	ctx = middleware.WithCaller(ctx, caller)
+	if code := r.Header.Get(middleware.OTPHeader); code != "" {
+		ctx = middleware.WithOTP(ctx, code)
 	}
 
 	h.Next(w, r.WithContext(ctx))
@@ -2,31 +2,76 @@ package common
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
+	"time"
 
+	"github.com/filecoin-project/go-address"
 	"github.com/filecoin-project/go-jsonrpc/auth"
 	"github.com/filecoin-project/venus-wallet/version"
 	api "github.com/filecoin-project/venus/venus-shared/api/wallet"
 	"github.com/filecoin-project/venus/venus-shared/types"
 	"github.com/gbrlsnchs/jwt/v3"
+	"github.com/ipfs-force-community/sophon-auth/core"
 	logging "github.com/ipfs/go-log/v2"
 	"go.uber.org/fx"
 
+	"github.com/filecoin-project/venus-wallet/config"
+	"github.com/filecoin-project/venus-wallet/middleware"
 	"github.com/filecoin-project/venus-wallet/storage"
 )
 
+var log = logging.Logger("common")
+
 type ICommon = api.ICommon
 
-var _ ICommon = &Common{}
+// IRecord the sign records with the fields the shared api lacks
+type IRecord interface {
+	// RecordQuery query the sign records
+	RecordQuery(ctx context.Context, params *storage.RecordQueryParams) ([]storage.SignRecord, error)
+	// RecordVerify checks the hash chain of the records and the checkpoints signed by the audit key,
+	// the configured audit key is used if auditKey is undef
+	RecordVerify(ctx context.Context, auditKey address.Address) (*storage.RecordVerifyResult, error)
+	// RecordExport streams the records matching the params from the oldest one, the channel is closed at the end.
+	// Limit caps the number of records, Skip and ID are ignored.
+	RecordExport(ctx context.Context, params *storage.RecordQueryParams) (<-chan storage.RecordExportItem, error)
+	// RecordStats counts the records by signer, type, error and time, and sums the value and fee of the chain messages
+	RecordStats(ctx context.Context, params *storage.RecordStatsParams) (*storage.RecordStats, error)
+	// RecordAdminQuery query the records of the administrative operations: key creation, import, export and deletion,
+	// password, lock and unlock, token creation and support accounts
+	RecordAdminQuery(ctx context.Context, params *storage.AdminQueryParams) ([]storage.AdminRecord, error)
+}
+
+// INamedToken tokens created with a name, so that the sign records tell which service asked for a signature
+type INamedToken interface {
+	// AuthNewNamed creates a token with the name and permissions
+	AuthNewNamed(ctx context.Context, name string, perms []auth.Permission) ([]byte, error)
+}
+
+var (
+	_ ICommon     = &Common{}
+	_ IRecord     = &Common{}
+	_ INamedToken = &Common{}
+)
 
 type Common struct {
 	fx.In
 	APISecret *jwt.HMACSHA
 	Recorder  storage.IRecorder
+	// RecorderCfg gives the audit key
+	RecorderCfg *config.SignRecorderConfig `optional:"true"`
+	// Panics gives the time the tokens were revoked by a panic lock
+	Panics storage.IPanicStore `optional:"true"`
 }
 
+var ErrTokenRevoked = errors.New("token revoked by panic lock")
+
 type jwtPayload struct {
 	Allow []string
+	Name  string `json:",omitempty"`
+	// IssuedAt unix seconds, tokens created before the field was added have none
+	IssuedAt int64 `json:",omitempty"`
 }
 
 func (a *Common) AuthVerify(ctx context.Context, token string) ([]auth.Permission, error) {
@@ -34,14 +79,68 @@ func (a *Common) AuthVerify(ctx context.Context, token string) ([]auth.Permissio
 	if _, err := jwt.Verify([]byte(token), a.APISecret, &payload); err != nil {
 		return nil, fmt.Errorf("JWT Verification failed: %w", err)
 	}
+	if err := a.checkRevoked(&payload); err != nil {
+		return nil, err
+	}
 	return payload.Allow, nil
 }
 
+// checkRevoked rejects the tokens without admin permission created up to the last revocation,
+// admin tokens are kept so that the operator can unlock the wallet
+func (a *Common) checkRevoked(payload *jwtPayload) error {
+	if a.Panics == nil {
+		return nil
+	}
+	for _, perm := range payload.Allow {
+		if perm == core.PermAdmin {
+			return nil
+		}
+	}
+	revokeBefore, err := a.Panics.RevokeBefore()
+	if err != nil {
+		return fmt.Errorf("check token revocation: %w", err)
+	}
+	if !revokeBefore.IsZero() && payload.IssuedAt <= revokeBefore.Unix() {
+		return ErrTokenRevoked
+	}
+	return nil
+}
+
 func (a *Common) AuthNew(ctx context.Context, perms []auth.Permission) ([]byte, error) {
 	p := jwtPayload{
-		Allow: perms, // TODO: consider checking validity
+		Allow:    perms, // TODO: consider checking validity
+		IssuedAt: time.Now().Unix(),
+	}
+	token, err := jwt.Sign(&p, a.APISecret)
+	a.auditToken(ctx, &p, token, err)
+	return token, err
+}
+
+func (a *Common) AuthNewNamed(ctx context.Context, name string, perms []auth.Permission) ([]byte, error) {
+	p := jwtPayload{
+		Allow:    perms,
+		Name:     name,
+		IssuedAt: time.Now().Unix(),
+	}
+	token, err := jwt.Sign(&p, a.APISecret)
+	a.auditToken(ctx, &p, token, err)
+	return token, err
+}
+
+// auditToken records the token creation, with the id the sign records of the token carry, never the token itself
+func (a *Common) auditToken(ctx context.Context, p *jwtPayload, token []byte, signErr error) {
+	perms := make([]string, 0, len(p.Allow))
+	for _, perm := range p.Allow {
+		perms = append(perms, string(perm))
+	}
+	detail := "perms " + strings.Join(perms, ",")
+	if signErr == nil {
+		detail += " token " + middleware.TokenID(string(token))
+	}
+	record := storage.NewAdminRecord(ctx, storage.AdminTokenNew, p.Name, detail, signErr)
+	if err := a.Recorder.RecordAdmin(record); err != nil {
+		log.Errorf("record token creation failed: %v", err)
 	}
-	return jwt.Sign(&p, a.APISecret)
 }
 
 func (a *Common) Version(context.Context) (types.Version, error) {
@@ -60,5 +159,63 @@ func (a *Common) LogSetLevel(ctx context.Context, subsystem, level string) error
 }
 
 func (a *Common) ListSignedRecord(ctx context.Context, param *types.QuerySignRecordParams) ([]types.SignRecord, error) {
-	return a.Recorder.QueryRecord(param)
+	records, err := a.Recorder.QueryRecord(&storage.RecordQueryParams{QueryParams: *param})
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]types.SignRecord, 0, len(records))
+	for i := range records {
+		ret = append(ret, records[i].ToShared())
+	}
+	return ret, nil
+}
+
+func (a *Common) RecordQuery(ctx context.Context, params *storage.RecordQueryParams) ([]storage.SignRecord, error) {
+	return a.Recorder.QueryRecord(params)
+}
+
+func (a *Common) RecordExport(ctx context.Context, params *storage.RecordQueryParams) (<-chan storage.RecordExportItem, error) {
+	out := make(chan storage.RecordExportItem, 64)
+	go func() {
+		defer close(out)
+		err := a.Recorder.IterateRecord(params, func(record *storage.SignRecord) error {
+			select {
+			case out <- storage.RecordExportItem{Record: record}:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		})
+		if ctx.Err() != nil {
+			return
+		}
+		last := storage.RecordExportItem{Done: true}
+		if err != nil {
+			last = storage.RecordExportItem{Err: err.Error()}
+		}
+		select {
+		case out <- last:
+		case <-ctx.Done():
+		}
+	}()
+	return out, nil
+}
+
+func (a *Common) RecordStats(ctx context.Context, params *storage.RecordStatsParams) (*storage.RecordStats, error) {
+	return a.Recorder.RecordStats(params)
+}
+
+func (a *Common) RecordAdminQuery(ctx context.Context, params *storage.AdminQueryParams) ([]storage.AdminRecord, error) {
+	return a.Recorder.QueryAdminRecord(params)
+}
+
+func (a *Common) RecordVerify(ctx context.Context, auditKey address.Address) (*storage.RecordVerifyResult, error) {
+	if auditKey == address.Undef && a.RecorderCfg != nil && a.RecorderCfg.AuditKey != "" {
+		var err error
+		auditKey, err = address.NewFromString(a.RecorderCfg.AuditKey)
+		if err != nil {
+			return nil, fmt.Errorf("parse audit key: %w", err)
+		}
+	}
+	return a.Recorder.VerifyChain(auditKey)
 }
@@ -4,16 +4,22 @@ package common
 import (
 	"context"
 	"encoding/hex"
+	"fmt"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/filecoin-project/go-jsonrpc/auth"
 	"github.com/filecoin-project/venus-wallet/filemgr"
+	"github.com/filecoin-project/venus-wallet/middleware"
 	"github.com/filecoin-project/venus-wallet/storage"
+	walletsqlite "github.com/filecoin-project/venus-wallet/storage/sqlite"
 	"github.com/gbrlsnchs/jwt/v3"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/fx"
 	"go.uber.org/fx/fxtest"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
 )
 
 func TestCommon_AuthVerify(t *testing.T) {
@@ -28,7 +34,7 @@ func TestCommon_AuthVerify(t *testing.T) {
 
 	app := fxtest.New(t,
 		fx.Provide(func() *jwt.HMACSHA { return jwt.NewHS256(sec) }),
-		fx.Provide(func() storage.IRecorder { return nil }),
+		fx.Provide(func() storage.IRecorder { return &walletsqlite.RecorderStub{} }),
 		fx.Populate(&c),
 	)
 	defer app.RequireStart().RequireStop()
@@ -56,6 +62,15 @@ func TestCommon_AuthVerify(t *testing.T) {
 
 	tests["valid-token-verify"] = validTokenCase
 
+	namedTokenCase := &testCase{want: []auth.Permission{"read", "write", "sign"}, wantErr: false}
+	token, err = c.AuthNewNamed(ctx, "messager", namedTokenCase.want)
+	require.NoError(t, err)
+	namedTokenCase.args.token = string(token)
+	require.Equal(t, "messager", middleware.TokenName(string(token)))
+	require.InDelta(t, time.Now().Unix(), middleware.TokenIssuedAt(string(token)), 5)
+
+	tests["named-token-verify"] = namedTokenCase
+
 	for tName, tt := range tests {
 		t.Run(tName, func(t *testing.T) {
 			got, err := c.AuthVerify(ctx, tt.args.token)
@@ -71,3 +86,65 @@ func TestCommon_AuthVerify(t *testing.T) {
 		})
 	}
 }
+
+func TestCommon_AuthVerifyRevoked(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	db, err := gorm.Open(sqlite.Open(fmt.Sprintf("file:%s?mode=memory&cache=shared", t.Name())), &gorm.Config{})
+	require.NoError(t, err)
+	panics, err := walletsqlite.NewPanicStore(db)
+	require.NoError(t, err)
+
+	cng, err := filemgr.RandJWTConfig()
+	require.NoError(t, err)
+	sec, err := hex.DecodeString(cng.Secret)
+	require.NoError(t, err)
+	c := Common{APISecret: jwt.NewHS256(sec), Recorder: &walletsqlite.RecorderStub{}, Panics: panics}
+
+	admin, err := c.AuthNew(ctx, []auth.Permission{"admin", "sign", "write", "read"})
+	require.NoError(t, err)
+	signer, err := c.AuthNewNamed(ctx, "messager", []auth.Permission{"sign", "write", "read"})
+	require.NoError(t, err)
+	_, err = c.AuthVerify(ctx, string(signer))
+	require.NoError(t, err)
+
+	require.NoError(t, panics.PutPanicEvent(&storage.PanicEvent{ID: "1", CreateAt: time.Now(), RevokeTokens: true}))
+
+	_, err = c.AuthVerify(ctx, string(signer))
+	require.ErrorIs(t, err, ErrTokenRevoked)
+	_, err = c.AuthVerify(ctx, string(admin))
+	require.NoError(t, err)
+
+	// tokens created after the revocation are accepted
+	time.Sleep(time.Second)
+	signer, err = c.AuthNewNamed(ctx, "messager", []auth.Permission{"sign", "write", "read"})
+	require.NoError(t, err)
+	_, err = c.AuthVerify(ctx, string(signer))
+	require.NoError(t, err)
+}
+
+func TestCommon_AuthNewRecorded(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(fmt.Sprintf("file:%s?mode=memory&cache=shared", t.Name())), &gorm.Config{})
+	require.NoError(t, err)
+	recorder, err := walletsqlite.NewSqliteRecorder(db, nil)
+	require.NoError(t, err)
+
+	cng, err := filemgr.RandJWTConfig()
+	require.NoError(t, err)
+	sec, err := hex.DecodeString(cng.Secret)
+	require.NoError(t, err)
+	c := Common{APISecret: jwt.NewHS256(sec), Recorder: recorder}
+
+	caller := &middleware.Caller{Name: "admin", RemoteAddr: "127.0.0.1:5000"}
+	token, err := c.AuthNewNamed(middleware.WithCaller(context.Background(), caller), "messager", []auth.Permission{"read", "sign"})
+	require.NoError(t, err)
+
+	records, err := recorder.QueryAdminRecord(&storage.AdminQueryParams{Op: storage.AdminTokenNew})
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	require.Equal(t, "messager", records[0].Target)
+	require.Equal(t, caller, records[0].Caller)
+	require.Equal(t, "perms read,sign token "+middleware.TokenID(string(token)), records[0].Detail)
+	require.Empty(t, records[0].Err)
+}
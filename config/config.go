@@ -10,6 +10,13 @@ type Config struct {
 	SignFilter     *SignFilter           `json:"SignFilter"`
 	APIRegisterHub *APIRegisterHubConfig `json:"WalletEvent"`
 	SignRecorder   *SignRecorderConfig   `json:"SignRecorder"`
+	Approval       *ApprovalConfig       `json:"Approval"`
+	RateLimit      *RateLimitConfig      `json:"RateLimit"`
+	DealPolicy     *DealPolicyConfig     `json:"DealPolicy"`
+	BlindSign      *BlindSignConfig      `json:"BlindSign"`
+	SignPlugin     *SignPluginConfig     `json:"SignPlugin"`
+	Quorum         *QuorumConfig         `json:"Quorum"`
+	KeyExport      *KeyExportConfig      `json:"KeyExport"`
 }
 
 type APIRegisterHubConfig struct {
@@ -56,7 +63,142 @@ type SignFilter struct {
 	Expr string `json:"expr"`
 }
 
+// SignPluginConfig a long-running external process checking the sign requests over json-rpc
+type SignPluginConfig struct {
+	Enable bool `json:"enable"`
+	// Endpoint path of the unix socket, or "tcp://127.0.0.1:port"
+	Endpoint string `json:"endpoint"`
+	// Timeout for one check, including the connect, eg. "5s"
+	Timeout string `json:"timeout"`
+	// FailOpen allow signing when the plugin is unreachable, times out or fails,
+	// by default such requests are rejected. An explicit rejection always fails the request.
+	FailOpen bool `json:"failOpen"`
+}
+
 type SignRecorderConfig struct {
-	Enable       bool   `json:"enable"`
+	Enable bool `json:"enable"`
+	// KeepDuration how long the records are kept, eg. "168h"
 	KeepDuration string `json:"keepDuration"`
+	// KeepDurations retention per MsgType overriding KeepDuration, eg. {"block": "87600h", "providerDealState": "24h"}
+	KeepDurations map[string]string `json:"keepDurations"`
+	// Archive the expired records are written to compressed files before being deleted
+	Archive *RecordArchiveConfig `json:"archive"`
+	// AuditKey a wallet address dedicated to signing the checkpoints of the record chain, no checkpoint if empty.
+	// It shouldn't be used for anything else, nor be listed in BlindSign.Addresses.
+	AuditKey string `json:"auditKey"`
+	// CheckpointInterval how often the chain head is signed with the audit key, eg. "1h"
+	CheckpointInterval string `json:"checkpointInterval"`
+	// DB a database apart from the keystore for the records, eg. {conn = "<repo>/sign_record.sqlite", type = "sqlite"}
+	// or {conn = "user:password@tcp(127.0.0.1:3306)/wallet", type = "mysql"}, the keystore database if empty.
+	// The mysql user needs the TRIGGER privilege. Run "record migrate" with the wallet stopped to move the existing
+	// records to it, the records written by the older versions can only be moved to sqlite.
+	DB *DBConfig `json:"db"`
+	// Queue the sign records are written in batches in the background
+	Queue *RecordQueueConfig `json:"queue"`
+}
+
+// RecordQueueConfig the queue of the sign records waiting to be written, the records left are written on shutdown
+type RecordQueueConfig struct {
+	// Size the records the queue holds, 1024 if zero
+	Size int `json:"size"`
+	// BatchSize the records written in one transaction, 100 if zero
+	BatchSize int `json:"batchSize"`
+	// FlushInterval how long a record waits for the batch to fill up, eg. "500ms"
+	FlushInterval string `json:"flushInterval"`
+	// DropWhenFull drop the records when the queue is full, counted by the sign/record_dropped metric,
+	// by default signing waits for room in the queue, so that no record is lost
+	DropWhenFull bool `json:"dropWhenFull"`
+}
+
+// RecordArchiveConfig the expired sign records are appended to gzip compressed JSON lines files,
+// one file per day of the records, eg. "sign_record-2006-01-02.jsonl.gz"
+type RecordArchiveConfig struct {
+	Enable bool `json:"enable"`
+	// Path directory of the archive files, may be an external mount, "<repo>/record_archive" if empty
+	Path string `json:"path"`
+}
+
+// ApprovalConfig chain messages matching any of the rules wait for an approver instead of being signed directly
+type ApprovalConfig struct {
+	Enable bool `json:"enable"`
+	// Timeout how long a sign request waits for a decision, eg. "10m"
+	Timeout string `json:"timeout"`
+	// MinValue messages transferring at least this amount of FIL, eg. "100"
+	MinValue string `json:"minValue"`
+	// Signers messages sent from these addresses, eg. owner addresses
+	Signers []string `json:"signers"`
+	// Methods messages calling these methods, eg. 23 for miner ChangeOwnerAddress
+	Methods []uint64 `json:"methods"`
+}
+
+// QuorumConfig key revealing or destructive operations wait for a second admin to confirm them
+type QuorumConfig struct {
+	Enable bool `json:"enable"`
+	// Timeout how long an operation waits for the confirmation, eg. "10m"
+	Timeout string `json:"timeout"`
+	// Operations the operations requiring a confirmation: "export", "delete", "set_password", all of them if empty
+	Operations []string `json:"operations"`
+	// Approvers the ids of the tokens allowed to confirm, the hex sha256 of the token as shown by "record admin"
+	// for its creation. The token must be older than the request
+	Approvers []string `json:"approvers"`
+}
+
+// KeyExportConfig only read at startup, so that it can't be changed through the api
+type KeyExportConfig struct {
+	// Disable forbids WalletExport for all the keys
+	Disable bool `json:"disable"`
+}
+
+// RateLimitConfig token bucket limits on WalletSign
+type RateLimitConfig struct {
+	Enable bool            `json:"enable"`
+	Rules  []RateLimitRule `json:"rules"`
+}
+
+type RateLimitRule struct {
+	// Signer only limit requests signed by this address, all addresses if empty
+	Signer string `json:"signer"`
+	// Token only limit requests sent with this jwt token, all tokens if empty
+	Token string `json:"token"`
+	// MsgType only limit this type of message, eg. "message", all types if empty
+	MsgType string `json:"msgType"`
+	// Scope how requests share buckets: "rule" one bucket for the rule (default),
+	// "signer" one bucket per signer address, "token" one bucket per jwt token
+	Scope string `json:"scope"`
+	// Limit requests allowed in every Interval, eg. Limit = 10 and Interval = "1m"
+	Limit    int    `json:"limit"`
+	Interval string `json:"interval"`
+}
+
+// DealPolicyConfig bounds checked on the storage deal proposals before signing, empty items are not checked
+type DealPolicyConfig struct {
+	Enable bool `json:"enable"`
+	// MaxPricePerEpoch max storage price per epoch in FIL, eg. "0.0000001"
+	MaxPricePerEpoch string `json:"maxPricePerEpoch"`
+	// MaxProviderCollateral max provider collateral in FIL
+	MaxProviderCollateral string `json:"maxProviderCollateral"`
+	// MaxClientCollateral max client collateral in FIL
+	MaxClientCollateral string `json:"maxClientCollateral"`
+	// MinDuration min deal duration in epochs
+	MinDuration int64 `json:"minDuration"`
+	// MaxDuration max deal duration in epochs
+	MaxDuration int64 `json:"maxDuration"`
+	// MinPieceSize min padded piece size, eg. "256B"
+	MinPieceSize string `json:"minPieceSize"`
+	// MaxPieceSize max padded piece size, eg. "32GiB"
+	MaxPieceSize string `json:"maxPieceSize"`
+	// VerifiedDeal "require" only verified deals, "forbid" no verified deals, any if empty
+	VerifiedDeal string `json:"verifiedDeal"`
+	// AllowedProviders provider ids allowed to make deals with, eg. "f01000", any if empty
+	AllowedProviders []string `json:"allowedProviders"`
+}
+
+// BlindSignConfig signing of arbitrary bytes, which is requested with MsgType "unknown"
+type BlindSignConfig struct {
+	// Addresses allowed to sign arbitrary bytes, no address is allowed by default.
+	// Note that block producing (ComputeVRF) signs with "unknown", so miner worker addresses need to be listed.
+	Addresses []string `json:"addresses"`
+	// DomainPrefix prepended to the bytes before signing, so that the signature can never be a valid
+	// signature of a filecoin message, eg. "\x19Filecoin Signed Message:\n". Not applied if empty.
+	DomainPrefix string `json:"domainPrefix"`
 }
@@ -91,9 +91,8 @@ func (fsr *FsRepo) checkConfig(op *OverrideParams) error {
 			cnf.APIRegisterHub.SupportAccounts = op.SupportAccounts
 		}
 	}
-	if cnf.SignFilter == nil {
-		cnf.SignFilter = &config.SignFilter{}
-	}
+	fillSignConfig(cnf)
+	fsr.fillRecorderConfig(cnf)
 
 	if reset {
 		err = config.CoverConfig(fsr.configPath(), cnf)
@@ -105,6 +104,82 @@ func (fsr *FsRepo) checkConfig(op *OverrideParams) error {
 	return nil
 }
 
+// fillSignConfig fills the defaults of the sections checked on reload
+func fillSignConfig(cnf *config.Config) {
+	if cnf.KeyExport == nil {
+		cnf.KeyExport = &config.KeyExportConfig{}
+	}
+	if cnf.SignFilter == nil {
+		cnf.SignFilter = &config.SignFilter{}
+	}
+	if cnf.Approval == nil {
+		cnf.Approval = &config.ApprovalConfig{
+			Timeout: "10m",
+			Signers: []string{},
+			Methods: []uint64{},
+		}
+	}
+	if cnf.DealPolicy == nil {
+		cnf.DealPolicy = &config.DealPolicyConfig{
+			AllowedProviders: []string{},
+		}
+	}
+	if cnf.BlindSign == nil {
+		cnf.BlindSign = &config.BlindSignConfig{
+			Addresses: []string{},
+		}
+	}
+	if cnf.RateLimit == nil {
+		cnf.RateLimit = &config.RateLimitConfig{
+			Rules: []config.RateLimitRule{},
+		}
+	}
+	if cnf.Quorum == nil {
+		cnf.Quorum = &config.QuorumConfig{
+			Timeout:    "10m",
+			Operations: []string{},
+			Approvers:  []string{},
+		}
+	}
+	if cnf.SignPlugin == nil {
+		cnf.SignPlugin = &config.SignPluginConfig{
+			Timeout: "5s",
+		}
+	}
+}
+
+// ReadConfig reads the config file again, without touching the config in use
+func (fsr *FsRepo) ReadConfig() (*config.Config, error) {
+	cnf, err := fsr.loadConfig()
+	if err != nil {
+		return nil, err
+	}
+	fillSignConfig(cnf)
+	fsr.fillRecorderConfig(cnf)
+	return cnf, nil
+}
+
+// fillRecorderConfig the archive goes to the repo if no path is given
+func (fsr *FsRepo) fillRecorderConfig(cnf *config.Config) {
+	if cnf.SignRecorder != nil && cnf.SignRecorder.Archive != nil && cnf.SignRecorder.Archive.Path == "" {
+		cnf.SignRecorder.Archive.Path = filepath.Join(fsr.path, recordArchiveDir)
+	}
+}
+
+// UpdateConfig changes the config in use, it doesn't write the config file.
+// The update is applied to a copy which then replaces the config in use.
+func (fsr *FsRepo) UpdateConfig(update func(cnf *config.Config)) {
+	fsr.lk.Lock()
+	defer fsr.lk.Unlock()
+	cnf := *fsr.cnf
+	update(&cnf)
+	fsr.cnf = &cnf
+}
+
+func (fsr *FsRepo) ConfigPath() string {
+	return fsr.configPath()
+}
+
 func (fsr *FsRepo) configExist() (bool, error) {
 	_, err := os.Stat(fsr.configPath())
 	if err == nil {
@@ -128,6 +203,8 @@ func (fsr *FsRepo) configPath() string {
 }
 
 func (fsr *FsRepo) AppendSupportAccount(newAccount string) error {
+	fsr.lk.Lock()
+	defer fsr.lk.Unlock()
 	fsr.cnf.APIRegisterHub.SupportAccounts = append(fsr.cnf.APIRegisterHub.SupportAccounts, newAccount)
 	return config.CoverConfig(fsr.configPath(), fsr.cnf)
 }
@@ -5,4 +5,6 @@ type systemKeyword = string
 const (
 	skConfig systemKeyword = "config.toml"
 	dbName   systemKeyword = "keystore.sqlit"
+	// recordArchiveDir the default directory of the sign record archive
+	recordArchiveDir systemKeyword = "record_archive"
 )
@@ -8,6 +8,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 
 	"github.com/filecoin-project/venus-wallet/config"
 	"github.com/filecoin-project/venus-wallet/crypto/aes"
@@ -26,6 +27,7 @@ var (
 // FsRepo is struct for repo, use NewFS to create
 type FsRepo struct {
 	path string
+	lk   sync.Mutex
 	cnf  *config.Config
 }
 
@@ -92,6 +94,8 @@ func (fsr *FsRepo) exists() (bool, error) {
 }
 
 func (fsr *FsRepo) Config() *config.Config {
+	fsr.lk.Lock()
+	defer fsr.lk.Unlock()
 	return fsr.cnf
 }
 
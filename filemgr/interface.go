@@ -20,5 +20,14 @@ type Repo interface {
 
 	Config() *config.Config
 
+	// ReadConfig reads the config file again, without touching the config in use
+	ReadConfig() (*config.Config, error)
+
+	// UpdateConfig changes the config in use, it doesn't write the config file.
+	// The sections are shared by pointer with the components, update replaces them and never changes them in place.
+	UpdateConfig(update func(cnf *config.Config))
+
+	ConfigPath() string
+
 	AppendSupportAccount(newAccount string) error
 }
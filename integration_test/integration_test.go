@@ -7,8 +7,10 @@ import (
 	"fmt"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
 	"github.com/filecoin-project/venus/venus-shared/types"
 
 	"github.com/filecoin-project/go-jsonrpc"
@@ -16,6 +18,8 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/filecoin-project/venus-wallet/cli/helper"
+	"github.com/filecoin-project/venus-wallet/storage"
+	"github.com/filecoin-project/venus-wallet/storage/wallet"
 
 	"github.com/urfave/cli/v2"
 )
@@ -138,3 +142,77 @@ func testWalletAddress(t *testing.T) {
 	require.NoError(t, err)
 	require.False(t, has)
 }
+
+func TestRecordExport(t *testing.T) {
+	ctx := context.TODO()
+	if err := client.SetPassword(ctx, defaultWalletPwd); err != nil {
+		require.Contains(t, err.Error(), "already")
+	}
+
+	addr, err := client.WalletNew(ctx, types.KTSecp256k1)
+	require.NoError(t, err)
+	msg := &types.Message{From: addr, To: addr, Value: abi.NewTokenAmount(0)}
+	extra, err := msg.Serialize()
+	require.NoError(t, err)
+	_, err = client.WalletSign(ctx, addr, msg.Cid().Bytes(), types.MsgMeta{Type: types.MTChainMsg, Extra: extra})
+	require.NoError(t, err)
+
+	// the channels need the websocket connection
+	flags := flag.NewFlagSet("", flag.PanicOnError)
+	flags.String("repo", inst.repoDir, "")
+	stream, closer, err := helper.GetFullAPIStream(cli.NewContext(nil, flags, nil))
+	require.NoError(t, err)
+	defer closer()
+
+	require.Eventually(t, func() bool {
+		items, err := stream.RecordExport(ctx, &storage.RecordQueryParams{QueryParams: storage.QueryParams{Signer: addr}})
+		require.NoError(t, err)
+		var records []*storage.SignRecord
+		done := false
+		for item := range items {
+			require.Empty(t, item.Err)
+			if item.Done {
+				done = true
+			} else {
+				records = append(records, item.Record)
+			}
+		}
+		require.True(t, done)
+		return len(records) == 1 && records[0].MsgCID == msg.Cid().String()
+	}, 5*time.Second, 100*time.Millisecond)
+}
+
+func TestSubscribeEvents(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	if err := client.SetPassword(ctx, defaultWalletPwd); err != nil {
+		require.Contains(t, err.Error(), "already")
+	}
+
+	flags := flag.NewFlagSet("", flag.PanicOnError)
+	flags.String("repo", inst.repoDir, "")
+	stream, closer, err := helper.GetFullAPIStream(cli.NewContext(nil, flags, nil))
+	require.NoError(t, err)
+	defer closer()
+
+	events, err := stream.WalletSubscribeEvents(ctx, &wallet.EventFilter{Types: []wallet.EventType{wallet.EventKeyAdd, wallet.EventSignSuccess}})
+	require.NoError(t, err)
+
+	addr, err := client.WalletNew(ctx, types.KTSecp256k1)
+	require.NoError(t, err)
+	msg := &types.Message{From: addr, To: addr, Value: abi.NewTokenAmount(0)}
+	extra, err := msg.Serialize()
+	require.NoError(t, err)
+	_, err = client.WalletSign(ctx, addr, msg.Cid().Bytes(), types.MsgMeta{Type: types.MTChainMsg, Extra: extra})
+	require.NoError(t, err)
+
+	for _, want := range []wallet.EventType{wallet.EventKeyAdd, wallet.EventSignSuccess} {
+		select {
+		case ev := <-events:
+			require.Equal(t, want, ev.Type)
+			require.Equal(t, addr, ev.Address)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("no %s event", want)
+		}
+	}
+}
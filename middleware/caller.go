@@ -0,0 +1,74 @@
+package middleware
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+)
+
+type callerKey struct{}
+
+// Caller identifies the client which sends a request
+type Caller struct {
+	// TokenID hex encoded sha256 of the jwt token, so that the token itself is never kept around
+	TokenID string
+	// Name the name the token was created with, empty for unnamed tokens
+	Name string
+	// IssuedAt unix seconds the token was created at, 0 for the tokens which don't tell
+	IssuedAt int64 `json:",omitempty"`
+	Perms    []string
+	// RemoteAddr the address of the http client
+	RemoteAddr string
+	// Gateway url of the gateway the request arrived from, for requests over wallet_event
+	Gateway string
+	// SupportAccounts the accounts the wallet serves through the gateway
+	SupportAccounts []string
+}
+
+func TokenID(token string) string {
+	h := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(h[:])
+}
+
+type tokenClaims struct {
+	Name     string
+	IssuedAt int64
+}
+
+// claims of a jwt token, the token must have been verified already
+func claims(token string) tokenClaims {
+	var payload tokenClaims
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return payload
+	}
+	data, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return payload
+	}
+	_ = json.Unmarshal(data, &payload)
+	return payload
+}
+
+// TokenName returns the name claim of a jwt token, the token must have been verified already
+func TokenName(token string) string {
+	return claims(token).Name
+}
+
+// TokenIssuedAt returns the unix seconds the jwt token was created at, 0 if it doesn't tell
+func TokenIssuedAt(token string) int64 {
+	return claims(token).IssuedAt
+}
+
+func WithCaller(ctx context.Context, caller *Caller) context.Context {
+	return context.WithValue(ctx, callerKey{}, caller)
+}
+
+// CallerFromContext returns the caller of the request, nil for internal calls
+func CallerFromContext(ctx context.Context) *Caller {
+	caller, _ := ctx.Value(callerKey{}).(*Caller)
+	return caller
+}
@@ -11,11 +11,16 @@ import (
 var (
 	Version, _ = tag.NewKey("version")
 	Commit, _  = tag.NewKey("commit")
+	Signer, _  = tag.NewKey("signer")
+	MsgType, _ = tag.NewKey("msg_type")
 )
 
 var (
-	VenusInfo       = stats.Int64("info", "Arbitrary counter to tag venus info to", stats.UnitDimensionless)
-	ChainNodeHeight = stats.Int64("chain/node_height", "Current Height of the node", stats.UnitDimensionless)
+	VenusInfo        = stats.Int64("info", "Arbitrary counter to tag venus info to", stats.UnitDimensionless)
+	ChainNodeHeight  = stats.Int64("chain/node_height", "Current Height of the node", stats.UnitDimensionless)
+	SignRateLimited  = stats.Int64("sign/rate_limited", "Counter of sign requests rejected by rate limit", stats.UnitDimensionless)
+	SignVerifyFailed = stats.Int64("sign/verify_failed", "Counter of produced signatures failing verification", stats.UnitDimensionless)
+	RecordDropped    = stats.Int64("sign/record_dropped", "Counter of sign records dropped because the record queue is full", stats.UnitDimensionless)
 )
 
 var (
@@ -30,10 +35,28 @@ var (
 		Measure:     ChainNodeHeight,
 		Aggregation: view.LastValue(),
 	}
+	SignRateLimitedView = &view.View{
+		Measure:     SignRateLimited,
+		Aggregation: view.Count(),
+		TagKeys:     []tag.Key{Signer, MsgType},
+	}
+	SignVerifyFailedView = &view.View{
+		Measure:     SignVerifyFailed,
+		Aggregation: view.Count(),
+		TagKeys:     []tag.Key{Signer, MsgType},
+	}
+	RecordDroppedView = &view.View{
+		Measure:     RecordDropped,
+		Aggregation: view.Count(),
+		TagKeys:     []tag.Key{Signer, MsgType},
+	}
 )
 
 // DefaultViews is an array of OpenCensus views for metric gathering purposes
 var DefaultViews = append([]*view.View{
 	InfoView,
 	ChainNodeHeightView,
+	SignRateLimitedView,
+	SignVerifyFailedView,
+	RecordDroppedView,
 }, rpcmetrics.DefaultViews...)
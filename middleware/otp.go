@@ -0,0 +1,18 @@
+package middleware
+
+import "context"
+
+// OTPHeader the http header carrying the one-time code, the apis needing it keep their signatures
+const OTPHeader = "X-Wallet-OTP"
+
+type otpKey struct{}
+
+func WithOTP(ctx context.Context, code string) context.Context {
+	return context.WithValue(ctx, otpKey{}, code)
+}
+
+// OTPFromContext returns the one-time code sent with the request, empty if there is none
+func OTPFromContext(ctx context.Context) string {
+	code, _ := ctx.Value(otpKey{}).(string)
+	return code
+}
@@ -0,0 +1,81 @@
+package storage
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/filecoin-project/venus-wallet/middleware"
+)
+
+// AdminOp an administrative operation, recorded apart from the sign records
+type AdminOp string
+
+const (
+	AdminWalletNew      AdminOp = "wallet_new"
+	AdminWalletImport   AdminOp = "wallet_import"
+	AdminWalletExport   AdminOp = "wallet_export"
+	AdminWalletDelete   AdminOp = "wallet_delete"
+	AdminSetPassword    AdminOp = "set_password"
+	AdminUnlock         AdminOp = "unlock"
+	AdminLock           AdminOp = "lock"
+	AdminTokenNew       AdminOp = "token_new"
+	AdminSupportAccount AdminOp = "support_account_add"
+	AdminPanicLock      AdminOp = "panic_lock"
+	AdminPanicUnlock    AdminOp = "panic_unlock"
+)
+
+// AdminOps the operations recorded
+var AdminOps = []AdminOp{AdminWalletNew, AdminWalletImport, AdminWalletExport, AdminWalletDelete,
+	AdminSetPassword, AdminUnlock, AdminLock, AdminTokenNew, AdminSupportAccount, AdminPanicLock, AdminPanicUnlock}
+
+// AdminRecord the record of an administrative operation, who did it, on what, and whether it succeeded
+type AdminRecord struct {
+	ID       string
+	CreateAt time.Time
+	Op       AdminOp
+	// Target the address, token name or account the operation is about, empty for the wallet wide ones
+	Target string
+	// Detail what else the operation is about, eg. the permissions of a token
+	Detail string
+	Caller *middleware.Caller
+	// Err empty if the operation succeeded
+	Err string
+}
+
+// NewAdminRecord the record of an operation of the caller of ctx
+func NewAdminRecord(ctx context.Context, op AdminOp, target, detail string, err error) *AdminRecord {
+	record := &AdminRecord{
+		ID:       uuid.New().String(),
+		CreateAt: time.Now(),
+		Op:       op,
+		Target:   target,
+		Detail:   detail,
+		Caller:   middleware.CallerFromContext(ctx),
+	}
+	if err != nil {
+		record.Err = err.Error()
+	}
+	return record
+}
+
+// AdminQueryParams selects the admin records, empty fields select all
+type AdminQueryParams struct {
+	ID      string
+	Op      AdminOp
+	Target  string
+	After   time.Time
+	Before  time.Time
+	IsError bool
+	Skip    int
+	Limit   int
+}
+
+// IAdminRecorder the records of the administrative operations, they are kept apart from the
+// sign records and not removed by the retention
+type IAdminRecorder interface {
+	RecordAdmin(record *AdminRecord) error
+	// QueryAdminRecord the records matching the params, the latest first
+	QueryAdminRecord(params *AdminQueryParams) ([]AdminRecord, error)
+}
@@ -0,0 +1,49 @@
+package storage
+
+import (
+	"errors"
+	"time"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/venus/venus-shared/types"
+)
+
+var ErrApprovalNotFound = errors.New("approval request not found")
+
+type ApprovalState string
+
+const (
+	ApprovalPending  ApprovalState = "pending"
+	ApprovalApproved ApprovalState = "approved"
+	ApprovalRejected ApprovalState = "rejected"
+	ApprovalExpired  ApprovalState = "expired"
+)
+
+// ApprovalRequest a sign request parked until an approver decides on it
+type ApprovalRequest struct {
+	ID     string
+	Type   types.MsgType
+	Signer address.Address
+	// RawMsg cbor encoded sign object, same as SignRecord.RawMsg
+	RawMsg []byte
+	// Reason the rule which put the request into the queue
+	Reason   string
+	State    ApprovalState
+	Comment  string
+	CreateAt time.Time
+	Deadline time.Time
+	DecideAt time.Time
+}
+
+type IApprovalStore interface {
+	// Put saves a new approval request
+	Put(req *ApprovalRequest) error
+	// Get returns the approval request with the given id
+	Get(id string) (*ApprovalRequest, error)
+	// List returns the approval requests in the given state, all of them if state is empty
+	List(state ApprovalState) ([]ApprovalRequest, error)
+	// Update changes the state of a pending request, it fails if the request has been decided already
+	Update(id string, state ApprovalState, comment string) error
+	// ExpirePending marks all pending requests as expired
+	ExpirePending() error
+}
@@ -0,0 +1,48 @@
+package storage
+
+import (
+	"fmt"
+
+	"github.com/filecoin-project/go-address"
+)
+
+type KeyRole string
+
+const (
+	RoleOwner    KeyRole = "owner"
+	RoleWorker   KeyRole = "worker"
+	RoleControl  KeyRole = "control"
+	RoleMarket   KeyRole = "market"
+	RoleEthereum KeyRole = "ethereum"
+)
+
+var KeyRoles = []KeyRole{RoleOwner, RoleWorker, RoleControl, RoleMarket, RoleEthereum}
+
+func ParseKeyRole(s string) (KeyRole, error) {
+	for _, role := range KeyRoles {
+		if string(role) == s {
+			return role, nil
+		}
+	}
+	return "", fmt.Errorf("unknown key role %s, expect one of %v", s, KeyRoles)
+}
+
+// KeyMeta the settings of a stored key besides the key itself
+type KeyMeta struct {
+	Address address.Address
+	// Roles restrict the message types the key may sign, a key without roles may sign any type
+	Roles []KeyRole
+	// Disabled the key is kept, but refuses to sign
+	Disabled bool
+	// NoExport the key can never be exported, only set when the key is created or imported, never cleared
+	NoExport bool
+}
+
+// IKeyMetaStore stores the KeyMeta by address
+type IKeyMetaStore interface {
+	// GetMeta returns an empty KeyMeta for keys never set
+	GetMeta(addr address.Address) (*KeyMeta, error)
+	ListMeta() ([]KeyMeta, error)
+	PutMeta(meta *KeyMeta) error
+	DeleteMeta(addr address.Address) error
+}
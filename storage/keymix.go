@@ -25,6 +25,7 @@ var (
 	ErrPasswordExist   = errors.New("the password already exists")
 	ErrAlreadyUnlocked = errors.New("wallet already unlocked")
 	ErrAlreadyLocked   = errors.New("wallet already locked")
+	ErrPanicLocked     = errors.New("wallet panic locked, unlock with the password")
 )
 
 var EmptyPassword []byte
@@ -41,12 +42,19 @@ type KeyMiddleware interface {
 	Next() error
 	// CheckToken check if the `strategy` token has all permissions
 	CheckToken(ctx context.Context) error
+	// EncryptData aes encrypt arbitrary data, the password in use if password is empty
+	EncryptData(password []byte, data []byte) (*aes.CryptoJSON, error)
+	// DecryptData aes decrypt arbitrary data, the password in use if password is empty
+	DecryptData(password []byte, data *aes.CryptoJSON) ([]byte, error)
+	// PanicLock locks the wallet without the password, until unlocked with the password
+	PanicLock()
 	walletAPI.IWalletLock
 }
 
 type KeyMixLayer struct {
 	m        sync.RWMutex
 	locked   bool
+	panicked bool
 	password []byte
 	scryptN  int // aes cryptographic variable
 	scryptP  int // aes cryptographic variable
@@ -109,10 +117,18 @@ func (o *KeyMixLayer) changeLock(password string, lock bool) error {
 	o.locked = lock
 	if !o.locked {
 		o.password = hashPasswd
+		o.panicked = false
 	}
 	return nil
 }
 
+func (o *KeyMixLayer) PanicLock() {
+	o.m.Lock()
+	defer o.m.Unlock()
+	o.locked = true
+	o.panicked = true
+}
+
 func (o *KeyMixLayer) CheckToken(ctx context.Context) error {
 	if len(o.password) == 0 {
 		return ErrPasswordEmpty
@@ -133,6 +149,9 @@ func (o *KeyMixLayer) Next() error {
 	if len(o.password) == 0 {
 		return ErrPasswordEmpty
 	}
+	if o.panicked {
+		return ErrPanicLocked
+	}
 	if o.locked {
 		return ErrLocked
 	}
@@ -158,6 +177,20 @@ func (o *KeyMixLayer) Encrypt(password []byte, key crypto.PrivateKey) (*aes.Encr
 	return encryptedKeyJSON, nil
 }
 
+func (o *KeyMixLayer) EncryptData(password []byte, data []byte) (*aes.CryptoJSON, error) {
+	if len(password) == 0 {
+		password = o.password
+	}
+	return o.encryptData(password, data)
+}
+
+func (o *KeyMixLayer) DecryptData(password []byte, data *aes.CryptoJSON) ([]byte, error) {
+	if len(password) == 0 {
+		password = o.password
+	}
+	return aes.Decrypt(data, password)
+}
+
 func (o *KeyMixLayer) encryptData(password []byte, data []byte) (*aes.CryptoJSON, error) {
 	return aes.EncryptData(password, data, o.scryptN, o.scryptP)
 }
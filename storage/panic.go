@@ -0,0 +1,26 @@
+package storage
+
+import (
+	"time"
+
+	"github.com/filecoin-project/venus-wallet/middleware"
+)
+
+// PanicEvent an emergency lock of the wallet
+type PanicEvent struct {
+	ID       string
+	CreateAt time.Time
+	Reason   string
+	// RevokeTokens the tokens without admin permission created before were revoked
+	RevokeTokens bool
+	// Caller nil when the lock was triggered by signal
+	Caller *middleware.Caller
+}
+
+// IPanicStore stores the panic events, the latest one revoking tokens gives the revocation time
+type IPanicStore interface {
+	PutPanicEvent(event *PanicEvent) error
+	ListPanicEvents() ([]PanicEvent, error)
+	// RevokeBefore the tokens without admin permission created before are rejected, zero when never revoked
+	RevokeBefore() (time.Time, error)
+}
@@ -0,0 +1,96 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/filecoin-project/venus-wallet/middleware"
+)
+
+var ErrQuorumNotFound = errors.New("quorum request not found")
+
+// QuorumOp an operation requiring the confirmation of a second admin
+type QuorumOp string
+
+const (
+	QuorumExport      QuorumOp = "export"
+	QuorumDelete      QuorumOp = "delete"
+	QuorumSetPassword QuorumOp = "set_password"
+)
+
+var QuorumOps = []QuorumOp{QuorumExport, QuorumDelete, QuorumSetPassword}
+
+func ParseQuorumOp(s string) (QuorumOp, error) {
+	for _, op := range QuorumOps {
+		if string(op) == s {
+			return op, nil
+		}
+	}
+	return "", fmt.Errorf("unknown quorum operation %s, expect one of %v", s, QuorumOps)
+}
+
+type QuorumState string
+
+const (
+	QuorumPending   QuorumState = "pending"
+	QuorumConfirmed QuorumState = "confirmed"
+	QuorumRejected  QuorumState = "rejected"
+	QuorumExpired   QuorumState = "expired"
+)
+
+// QuorumRequest an operation waiting for the confirmation of a second admin
+type QuorumRequest struct {
+	ID string
+	Op QuorumOp
+	// Target the address the operation is on, empty for set_password
+	Target    string
+	Requester *middleware.Caller
+	State     QuorumState
+	// Confirmer the admin who confirmed or rejected the request
+	Confirmer *middleware.Caller
+	Comment   string
+	CreateAt  time.Time
+	Deadline  time.Time
+	DecideAt  time.Time
+}
+
+// QuorumAction a step of a quorum request
+type QuorumAction string
+
+const (
+	QuorumActionRequest QuorumAction = "request"
+	QuorumActionConfirm QuorumAction = "confirm"
+	QuorumActionReject  QuorumAction = "reject"
+	QuorumActionExpire  QuorumAction = "expire"
+	QuorumActionExecute QuorumAction = "execute"
+)
+
+// QuorumAudit the audit trail of the quorum requests, one entry per step
+type QuorumAudit struct {
+	ID        string
+	RequestID string
+	Action    QuorumAction
+	// Caller nil for the steps taken by the wallet itself, like expiry
+	Caller   *middleware.Caller
+	Comment  string
+	Err      string
+	CreateAt time.Time
+}
+
+type IQuorumStore interface {
+	// Put saves a new quorum request
+	Put(req *QuorumRequest) error
+	// Get returns the quorum request with the given id
+	Get(id string) (*QuorumRequest, error)
+	// List returns the quorum requests in the given state, all of them if state is empty
+	List(state QuorumState) ([]QuorumRequest, error)
+	// Decide changes the state of a pending request, it fails if the request has been decided already
+	Decide(id string, state QuorumState, confirmer *middleware.Caller, comment string) error
+	// ExpirePending marks all pending requests as expired, and returns their ids
+	ExpirePending() ([]string, error)
+	// AddAudit appends an entry to the audit trail
+	AddAudit(audit *QuorumAudit) error
+	// ListAudit returns the audit trail of the request, of all the requests if id is empty, oldest first
+	ListAudit(requestID string) ([]QuorumAudit, error)
+}
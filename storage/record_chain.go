@@ -0,0 +1,139 @@
+package storage
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"time"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/crypto"
+	"github.com/filecoin-project/venus/venus-shared/types"
+)
+
+// checkpointPrefix the checkpoint bytes signed by the audit key start with it,
+// so that the signature can never be valid for a filecoin message
+const checkpointPrefix = "venus-wallet sign record checkpoint\n"
+
+// prunePrefix the prefix of the signed prune checkpoints, they can't be taken for a signed head or the other way round
+const prunePrefix = "venus-wallet sign record prune\n"
+
+// TimePrecision the precision of the times covered by the record hashes and the checkpoint signatures,
+// the server databases keep no more than microseconds
+const TimePrecision = time.Microsecond
+
+type CheckpointKind string
+
+const (
+	// CheckpointSigned the chain head signed by the audit key
+	CheckpointSigned CheckpointKind = "signed"
+	// CheckpointPrune a record deleted by the retention, the remaining records chain to it
+	CheckpointPrune CheckpointKind = "prune"
+)
+
+// RecordCheckpoint a position of the sign record chain
+type RecordCheckpoint struct {
+	ID       uint64
+	Kind     CheckpointKind
+	Seq      uint64
+	Hash     []byte
+	CreateAt time.Time
+	// RecordAt and RecordType the time and type of the pruned record, for prune checkpoints
+	RecordAt   time.Time
+	RecordType types.MsgType
+	// Keep the retention of the pruned record when it was pruned
+	Keep time.Duration
+	// Signer the audit key, undef for the prune checkpoints written without an audit key
+	Signer    address.Address
+	Signature *crypto.Signature
+}
+
+// SigningBytes the bytes signed by the audit key
+func (cp *RecordCheckpoint) SigningBytes() []byte {
+	if cp.Kind == CheckpointPrune {
+		buf := make([]byte, 0, len(prunePrefix)+40+len(cp.Hash)+len(cp.RecordType))
+		buf = append(buf, prunePrefix...)
+		buf = binary.BigEndian.AppendUint64(buf, cp.Seq)
+		buf = binary.BigEndian.AppendUint64(buf, uint64(len(cp.Hash)))
+		buf = append(buf, cp.Hash...)
+		buf = binary.BigEndian.AppendUint64(buf, uint64(cp.CreateAt.UnixNano()))
+		buf = binary.BigEndian.AppendUint64(buf, uint64(cp.RecordAt.UnixNano()))
+		buf = binary.BigEndian.AppendUint64(buf, uint64(cp.Keep))
+		return append(buf, cp.RecordType...)
+	}
+	buf := make([]byte, 0, len(checkpointPrefix)+8+len(cp.Hash))
+	buf = append(buf, checkpointPrefix...)
+	buf = binary.BigEndian.AppendUint64(buf, cp.Seq)
+	return append(buf, cp.Hash...)
+}
+
+// PruneSigner signs the prune checkpoints of a batch with the audit key
+type PruneSigner func(cps []*RecordCheckpoint) error
+
+// RecordVerifyResult the result of the check of the sign record chain
+type RecordVerifyResult struct {
+	// Records chained records checked
+	Records int
+	// Unchained records written before the chain was introduced, they can't be checked
+	Unchained int
+	// Pruned records deleted by the retention, of which only the hash, time and type are kept
+	Pruned   int
+	FirstSeq uint64
+	LastSeq  uint64
+	// Checkpoints signed checkpoints checked
+	Checkpoints int
+	// LastCheckpoint the seq of the latest signed checkpoint, records after it can be removed unnoticed
+	LastCheckpoint uint64
+	// Problems the deleted, reordered or modified entries found, the chain is intact if empty
+	Problems []string
+}
+
+// ChainHash the hash of the record, covering all the fields and the hash of the previous record
+func (r *SignRecord) ChainHash() []byte {
+	h := sha256.New()
+	writeField := func(b []byte) {
+		_ = binary.Write(h, binary.BigEndian, uint64(len(b)))
+		h.Write(b)
+	}
+	writeInt := func(v int64) {
+		_ = binary.Write(h, binary.BigEndian, v)
+	}
+
+	writeInt(int64(r.Seq))
+	writeField(r.PrevHash)
+	writeField([]byte(r.ID))
+	writeField([]byte(r.Type))
+	writeField(r.Signer.Bytes())
+	writeField([]byte(r.Err))
+	writeField(r.RawMsg)
+	if r.Signature != nil {
+		writeInt(int64(r.Signature.Type))
+		writeField(r.Signature.Data)
+	} else {
+		writeInt(-1)
+	}
+	writeInt(r.CreateAt.UnixNano())
+	var caller []byte
+	if r.Caller != nil {
+		caller, _ = json.Marshal(r.Caller)
+	}
+	writeField(caller)
+	writeField([]byte(r.MsgCID))
+	writeField(r.Extra)
+	writeField(r.ToSign)
+	writeInt(int64(r.Duration))
+	writeField([]byte(r.Summary))
+	return h.Sum(nil)
+}
+
+// IRecordChain the sign records are hash chained, every record carries the hash of the previous one
+type IRecordChain interface {
+	// ChainHead the seq and hash of the last record, nil if there is none
+	ChainHead() (*RecordCheckpoint, error)
+	// PutCheckpoint saves a signed checkpoint
+	PutCheckpoint(cp *RecordCheckpoint) error
+	// VerifyChain checks the chain and the checkpoints, which must be signed by the audit key if it is set
+	VerifyChain(auditKey address.Address) (*RecordVerifyResult, error)
+	// SetPruneSigner the prune checkpoints are signed with it, and the records aren't pruned if it fails
+	SetPruneSigner(signer PruneSigner)
+}
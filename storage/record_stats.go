@@ -0,0 +1,64 @@
+package storage
+
+import (
+	"time"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/venus/venus-shared/types"
+)
+
+type StatsBucket string
+
+const (
+	BucketNone StatsBucket = ""
+	BucketHour StatsBucket = "hour"
+	BucketDay  StatsBucket = "day"
+)
+
+// RecordStatsParams selects the records counted, empty fields select all
+type RecordStatsParams struct {
+	Signer address.Address
+	Type   types.MsgType
+	After  time.Time
+	Before time.Time
+	// Bucket the size of the time buckets, no bucket if empty
+	Bucket StatsBucket
+	// TopErrors the number of distinct errors returned, 20 if zero
+	TopErrors int
+}
+
+// RecordCount the number of records of a group, Errors are the failed or rejected ones
+type RecordCount struct {
+	Key    string
+	Count  int64
+	Errors int64
+}
+
+// RecordBucket the records of a time bucket
+type RecordBucket struct {
+	Start  time.Time
+	Count  int64
+	Errors int64
+}
+
+// ChainMsgSums the chain messages signed successfully by a signer
+type ChainMsgSums struct {
+	Signer address.Address
+	Count  int64
+	Value  abi.TokenAmount
+	// MaxFee the sum of GasFeeCap * GasLimit, what the messages may pay at most
+	MaxFee abi.TokenAmount
+}
+
+type RecordStats struct {
+	Total    int64
+	Errors   int64
+	BySigner []RecordCount
+	ByType   []RecordCount
+	// ByError the most frequent errors, Key is the error message
+	ByError []RecordCount
+	Buckets []RecordBucket
+	// ChainMsg per signer, sorted by value
+	ChainMsg []ChainMsgSums
+}
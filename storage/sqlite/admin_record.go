@@ -0,0 +1,144 @@
+package sqlite
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/filecoin-project/venus-wallet/middleware"
+	"github.com/filecoin-project/venus-wallet/storage"
+)
+
+type sqliteAdminRecord struct {
+	ID        string             `gorm:"primaryKey;type:varchar(256);not null"`
+	CreatedAt time.Time          `gorm:"index"`
+	Op        storage.AdminOp    `gorm:"type:varchar(64);index;not null"`
+	Target    string             `gorm:"type:varchar(256);index;default:null"`
+	Detail    string             `gorm:"type:varchar(1024);default:null"`
+	Caller    *middleware.Caller `gorm:"serializer:json;default:null"`
+	Err       string             `gorm:"type:varchar(256);default:null"`
+}
+
+func (s *sqliteAdminRecord) TableName() string {
+	return "admin_record"
+}
+
+func newFromAdminRecord(record *storage.AdminRecord) *sqliteAdminRecord {
+	return &sqliteAdminRecord{
+		ID:        record.ID,
+		CreatedAt: record.CreateAt,
+		Op:        record.Op,
+		Target:    record.Target,
+		Detail:    record.Detail,
+		Caller:    record.Caller,
+		Err:       record.Err,
+	}
+}
+
+func (s *sqliteAdminRecord) toAdminRecord() storage.AdminRecord {
+	return storage.AdminRecord{
+		ID:       s.ID,
+		CreateAt: s.CreatedAt,
+		Op:       s.Op,
+		Target:   s.Target,
+		Detail:   s.Detail,
+		Caller:   s.Caller,
+		Err:      s.Err,
+	}
+}
+
+// initAdminRecord admin records are never updated nor deleted
+func initAdminRecord(db *gorm.DB) error {
+	if err := db.AutoMigrate(&sqliteAdminRecord{}); err != nil {
+		return err
+	}
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := createAbortTrigger(tx, "admin_record_append_only", "UPDATE", "admin_record", nil); err != nil {
+			return err
+		}
+		return createAbortTrigger(tx, "admin_record_no_delete", "DELETE", "admin_record", nil)
+	})
+}
+
+// adminRecorder the recorder of a wallet whose sign records are disabled, the admin records are kept anyway
+type adminRecorder struct {
+	RecorderStub
+	db *gorm.DB
+}
+
+func newAdminRecorder(db *gorm.DB) (*adminRecorder, error) {
+	if err := initAdminRecord(db); err != nil {
+		return nil, err
+	}
+	return &adminRecorder{db: db}, nil
+}
+
+func (r *adminRecorder) RecordAdmin(record *storage.AdminRecord) error {
+	return recordAdmin(r.db, record)
+}
+
+func (r *adminRecorder) QueryAdminRecord(params *storage.AdminQueryParams) ([]storage.AdminRecord, error) {
+	return queryAdminRecord(r.db, params)
+}
+
+func (s *SqliteRecorder) RecordAdmin(record *storage.AdminRecord) error {
+	return recordAdmin(s.db, record)
+}
+
+func (s *SqliteRecorder) QueryAdminRecord(params *storage.AdminQueryParams) ([]storage.AdminRecord, error) {
+	return queryAdminRecord(s.db, params)
+}
+
+func recordAdmin(db *gorm.DB, record *storage.AdminRecord) error {
+	if record.CreateAt.IsZero() {
+		record.CreateAt = time.Now()
+	}
+	return db.Create(newFromAdminRecord(record)).Error
+}
+
+func queryAdminRecord(db *gorm.DB, params *storage.AdminQueryParams) ([]storage.AdminRecord, error) {
+	query := db
+	if params.ID != "" {
+		query = query.Where("id = ?", params.ID)
+	} else {
+		if params.Op != "" {
+			query = query.Where("op = ?", params.Op)
+		}
+		if params.Target != "" {
+			query = query.Where("target = ?", params.Target)
+		}
+		if !params.After.IsZero() {
+			query = query.Where("created_at >= ?", params.After)
+		}
+		if !params.Before.IsZero() {
+			query = query.Where("created_at <= ?", params.Before)
+		}
+		if params.IsError {
+			query = query.Where("err is not null")
+		}
+		if params.Skip > 0 {
+			query = query.Offset(params.Skip)
+		}
+		if params.Limit > 0 {
+			query = query.Limit(params.Limit)
+		}
+	}
+
+	var records []*sqliteAdminRecord
+	if err := query.Order("created_at desc").Find(&records).Error; err != nil {
+		return nil, err
+	}
+	ret := make([]storage.AdminRecord, 0, len(records))
+	for _, r := range records {
+		ret = append(ret, r.toAdminRecord())
+	}
+	return ret, nil
+}
+
+func (r *RecorderStub) RecordAdmin(record *storage.AdminRecord) error {
+	return nil
+}
+
+func (r *RecorderStub) QueryAdminRecord(params *storage.AdminQueryParams) ([]storage.AdminRecord, error) {
+	return nil, nil
+}
@@ -0,0 +1,75 @@
+package sqlite
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/filecoin-project/venus-wallet/config"
+	"github.com/filecoin-project/venus-wallet/middleware"
+	"github.com/filecoin-project/venus-wallet/storage"
+)
+
+func TestAdminRecord(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file:TestAdminRecord?mode=memory&cache=shared"), &gorm.Config{})
+	require.NoError(t, err)
+	s, err := NewSqliteRecorder(db, nil)
+	require.NoError(t, err)
+
+	now := time.Now()
+	caller := &middleware.Caller{Name: "ops"}
+	records := []*storage.AdminRecord{
+		{ID: "1", CreateAt: now.Add(-2 * time.Hour), Op: storage.AdminWalletNew, Target: "f01000", Caller: caller},
+		{ID: "2", CreateAt: now.Add(-time.Hour), Op: storage.AdminWalletExport, Target: "f01000", Caller: caller},
+		{ID: "3", CreateAt: now, Op: storage.AdminWalletExport, Target: "f01001", Err: "export forbidden"},
+	}
+	for _, r := range records {
+		require.NoError(t, s.RecordAdmin(r))
+	}
+
+	ids := func(params *storage.AdminQueryParams) []string {
+		res, err := s.QueryAdminRecord(params)
+		require.NoError(t, err)
+		var ret []string
+		for _, r := range res {
+			ret = append(ret, r.ID)
+		}
+		return ret
+	}
+	assert.Equal(t, []string{"3", "2", "1"}, ids(&storage.AdminQueryParams{}))
+	assert.Equal(t, []string{"3", "2"}, ids(&storage.AdminQueryParams{Op: storage.AdminWalletExport}))
+	assert.Equal(t, []string{"2"}, ids(&storage.AdminQueryParams{Op: storage.AdminWalletExport, Target: "f01000"}))
+	assert.Equal(t, []string{"3"}, ids(&storage.AdminQueryParams{IsError: true}))
+	assert.Equal(t, []string{"2", "1"}, ids(&storage.AdminQueryParams{Before: now.Add(-time.Minute)}))
+	assert.Equal(t, []string{"2"}, ids(&storage.AdminQueryParams{Skip: 1, Limit: 1}))
+
+	res, err := s.QueryAdminRecord(&storage.AdminQueryParams{ID: "1"})
+	require.NoError(t, err)
+	require.Len(t, res, 1)
+	assert.Equal(t, caller, res[0].Caller)
+
+	assert.Error(t, db.Exec("UPDATE admin_record SET target = 'f09999' WHERE id = '2'").Error)
+	assert.Error(t, db.Exec("DELETE FROM admin_record WHERE id = '2'").Error)
+	assert.Equal(t, []string{"3", "2", "1"}, ids(&storage.AdminQueryParams{}))
+}
+
+func TestAdminRecord_SignRecordDisabled(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file:TestAdminRecord_SignRecordDisabled?mode=memory&cache=shared"), &gorm.Config{})
+	require.NoError(t, err)
+	s, err := NewSqliteRecorder(db, &config.SignRecorderConfig{Enable: false})
+	require.NoError(t, err)
+
+	require.NoError(t, s.Record(&storage.SignRecord{ID: "sign", CreateAt: time.Now()}))
+	assert.False(t, db.Migrator().HasTable(&sqliteSignRecord{}))
+
+	require.NoError(t, s.RecordAdmin(&storage.AdminRecord{ID: "1", Op: storage.AdminWalletExport, Target: "f01000"}))
+	res, err := s.QueryAdminRecord(&storage.AdminQueryParams{})
+	require.NoError(t, err)
+	require.Len(t, res, 1)
+	assert.Equal(t, "f01000", res[0].Target)
+	assert.Error(t, db.Exec("DELETE FROM admin_record WHERE id = '1'").Error)
+}
@@ -0,0 +1,130 @@
+package sqlite
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/filecoin-project/venus-wallet/storage"
+	"github.com/filecoin-project/venus/venus-shared/types"
+	"gorm.io/gorm"
+)
+
+type sqliteApprovalRequest struct {
+	ID        string    `gorm:"primaryKey;type:varchar(256);not null"`
+	CreatedAt time.Time `gorm:"index"`
+	Type      types.MsgType
+	Signer    string `gorm:"type:varchar(256);index;not null"`
+	RawMsg    []byte `gorm:"type:blob;default:null"`
+	Reason    string `gorm:"type:varchar(256)"`
+	State     string `gorm:"type:varchar(32);index;not null"`
+	Comment   string `gorm:"type:varchar(256)"`
+	Deadline  time.Time
+	DecideAt  time.Time
+}
+
+func (s *sqliteApprovalRequest) TableName() string {
+	return "approval_request"
+}
+
+func newFromApprovalRequest(req *storage.ApprovalRequest) *sqliteApprovalRequest {
+	return &sqliteApprovalRequest{
+		ID:        req.ID,
+		CreatedAt: req.CreateAt,
+		Type:      req.Type,
+		Signer:    req.Signer.String(),
+		RawMsg:    req.RawMsg,
+		Reason:    req.Reason,
+		State:     string(req.State),
+		Comment:   req.Comment,
+		Deadline:  req.Deadline,
+		DecideAt:  req.DecideAt,
+	}
+}
+
+func (s *sqliteApprovalRequest) toApprovalRequest() *storage.ApprovalRequest {
+	return &storage.ApprovalRequest{
+		ID:       s.ID,
+		Type:     s.Type,
+		Signer:   MustParseAddress(s.Signer),
+		RawMsg:   s.RawMsg,
+		Reason:   s.Reason,
+		State:    storage.ApprovalState(s.State),
+		Comment:  s.Comment,
+		CreateAt: s.CreatedAt,
+		Deadline: s.Deadline,
+		DecideAt: s.DecideAt,
+	}
+}
+
+type approvalStore struct {
+	db *gorm.DB
+}
+
+func NewApprovalStore(db *gorm.DB) (storage.IApprovalStore, error) {
+	if err := db.AutoMigrate(&sqliteApprovalRequest{}); err != nil {
+		return nil, fmt.Errorf("init approval store: %w", err)
+	}
+	return &approvalStore{db: db}, nil
+}
+
+func (s *approvalStore) Put(req *storage.ApprovalRequest) error {
+	return s.db.Create(newFromApprovalRequest(req)).Error
+}
+
+func (s *approvalStore) Get(id string) (*storage.ApprovalRequest, error) {
+	var req sqliteApprovalRequest
+	if err := s.db.Where("id = ?", id).First(&req).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, storage.ErrApprovalNotFound
+		}
+		return nil, err
+	}
+	return req.toApprovalRequest(), nil
+}
+
+func (s *approvalStore) List(state storage.ApprovalState) ([]storage.ApprovalRequest, error) {
+	var reqs []*sqliteApprovalRequest
+	query := s.db
+	if state != "" {
+		query = query.Where("state = ?", string(state))
+	}
+	if err := query.Order("created_at desc").Find(&reqs).Error; err != nil {
+		return nil, err
+	}
+
+	ret := make([]storage.ApprovalRequest, 0, len(reqs))
+	for _, r := range reqs {
+		ret = append(ret, *r.toApprovalRequest())
+	}
+	return ret, nil
+}
+
+func (s *approvalStore) Update(id string, state storage.ApprovalState, comment string) error {
+	res := s.db.Model(&sqliteApprovalRequest{}).
+		Where("id = ? and state = ?", id, string(storage.ApprovalPending)).
+		Updates(map[string]interface{}{
+			"state":     string(state),
+			"comment":   comment,
+			"decide_at": time.Now(),
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		if _, err := s.Get(id); err != nil {
+			return err
+		}
+		return fmt.Errorf("approval request %s is not pending", id)
+	}
+	return nil
+}
+
+func (s *approvalStore) ExpirePending() error {
+	return s.db.Model(&sqliteApprovalRequest{}).
+		Where("state = ?", string(storage.ApprovalPending)).
+		Updates(map[string]interface{}{
+			"state":     string(storage.ApprovalExpired),
+			"decide_at": time.Now(),
+		}).Error
+}
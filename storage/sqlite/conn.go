@@ -16,6 +16,21 @@ const (
 )
 
 func NewDB(cfg *config.DBConfig) (*gorm.DB, error) {
+	db, err := openDB(cfg)
+	if err != nil {
+		return nil, err
+	}
+	// key_types 1
+	if !db.Migrator().HasTable(TBWallet) {
+		if err = db.Table(TBWallet).AutoMigrate(&Wallet{}); err != nil {
+			return nil, fmt.Errorf("migrate failed:%w", err)
+		}
+	}
+
+	return db, nil
+}
+
+func openDB(cfg *config.DBConfig) (*gorm.DB, error) {
 	db, err := gorm.Open(sqlite.Open(cfg.Conn), &gorm.Config{})
 	var sqldb *sql.DB
 	if err != nil {
@@ -29,12 +44,5 @@ func NewDB(cfg *config.DBConfig) (*gorm.DB, error) {
 	sqldb.SetConnMaxIdleTime(300)
 	sqldb.SetMaxIdleConns(8)
 	sqldb.SetMaxOpenConns(64)
-	// key_types 1
-	if !db.Migrator().HasTable(TBWallet) {
-		if err = db.Table(TBWallet).AutoMigrate(&Wallet{}); err != nil {
-			return nil, fmt.Errorf("migrate failed:%w", err)
-		}
-	}
-
 	return db, nil
 }
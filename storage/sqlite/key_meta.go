@@ -0,0 +1,83 @@
+package sqlite
+
+import (
+	"fmt"
+
+	"github.com/filecoin-project/go-address"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/filecoin-project/venus-wallet/storage"
+)
+
+type sqliteKeyMeta struct {
+	Address  string            `gorm:"primaryKey;type:varchar(256);not null"`
+	Roles    []storage.KeyRole `gorm:"serializer:json"`
+	Disabled bool
+	NoExport bool
+}
+
+func (s *sqliteKeyMeta) TableName() string {
+	return "key_meta"
+}
+
+func newFromKeyMeta(meta *storage.KeyMeta) *sqliteKeyMeta {
+	return &sqliteKeyMeta{
+		Address:  meta.Address.String(),
+		Roles:    meta.Roles,
+		Disabled: meta.Disabled,
+		NoExport: meta.NoExport,
+	}
+}
+
+func (s *sqliteKeyMeta) toKeyMeta() *storage.KeyMeta {
+	return &storage.KeyMeta{
+		Address:  MustParseAddress(s.Address),
+		Roles:    s.Roles,
+		Disabled: s.Disabled,
+		NoExport: s.NoExport,
+	}
+}
+
+type keyMetaStore struct {
+	db *gorm.DB
+}
+
+func NewKeyMetaStore(db *gorm.DB) (storage.IKeyMetaStore, error) {
+	if err := db.AutoMigrate(&sqliteKeyMeta{}); err != nil {
+		return nil, fmt.Errorf("init key meta store: %w", err)
+	}
+	return &keyMetaStore{db: db}, nil
+}
+
+func (s *keyMetaStore) GetMeta(addr address.Address) (*storage.KeyMeta, error) {
+	// most keys have no meta, Find doesn't log the miss as an error like First does
+	var metas []*sqliteKeyMeta
+	if err := s.db.Where("address = ?", addr.String()).Limit(1).Find(&metas).Error; err != nil {
+		return nil, err
+	}
+	if len(metas) == 0 {
+		return &storage.KeyMeta{Address: addr}, nil
+	}
+	return metas[0].toKeyMeta(), nil
+}
+
+func (s *keyMetaStore) ListMeta() ([]storage.KeyMeta, error) {
+	var metas []*sqliteKeyMeta
+	if err := s.db.Find(&metas).Error; err != nil {
+		return nil, err
+	}
+	ret := make([]storage.KeyMeta, 0, len(metas))
+	for _, m := range metas {
+		ret = append(ret, *m.toKeyMeta())
+	}
+	return ret, nil
+}
+
+func (s *keyMetaStore) PutMeta(meta *storage.KeyMeta) error {
+	return s.db.Clauses(clause.OnConflict{UpdateAll: true}).Create(newFromKeyMeta(meta)).Error
+}
+
+func (s *keyMetaStore) DeleteMeta(addr address.Address) error {
+	return s.db.Where("address = ?", addr.String()).Delete(&sqliteKeyMeta{}).Error
+}
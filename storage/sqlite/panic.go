@@ -0,0 +1,96 @@
+package sqlite
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/filecoin-project/venus-wallet/middleware"
+	"github.com/filecoin-project/venus-wallet/storage"
+)
+
+type sqlitePanicEvent struct {
+	ID           string    `gorm:"primaryKey;type:varchar(36);not null"`
+	CreateAt     time.Time `gorm:"index"`
+	Reason       string
+	RevokeTokens bool
+	Caller       *middleware.Caller `gorm:"serializer:json"`
+}
+
+func (s *sqlitePanicEvent) TableName() string {
+	return "panic_event"
+}
+
+type panicStore struct {
+	db *gorm.DB
+
+	// the revocation time is checked on every request, cache it
+	lk           sync.Mutex
+	revokeBefore *time.Time
+}
+
+func NewPanicStore(db *gorm.DB) (storage.IPanicStore, error) {
+	if err := db.AutoMigrate(&sqlitePanicEvent{}); err != nil {
+		return nil, fmt.Errorf("init panic store: %w", err)
+	}
+	return &panicStore{db: db}, nil
+}
+
+func (s *panicStore) PutPanicEvent(event *storage.PanicEvent) error {
+	s.lk.Lock()
+	defer s.lk.Unlock()
+	err := s.db.Create(&sqlitePanicEvent{
+		ID:           event.ID,
+		CreateAt:     event.CreateAt,
+		Reason:       event.Reason,
+		RevokeTokens: event.RevokeTokens,
+		Caller:       event.Caller,
+	}).Error
+	if err != nil {
+		return err
+	}
+	if event.RevokeTokens && (s.revokeBefore == nil || event.CreateAt.After(*s.revokeBefore)) {
+		t := event.CreateAt
+		s.revokeBefore = &t
+	}
+	return nil
+}
+
+func (s *panicStore) ListPanicEvents() ([]storage.PanicEvent, error) {
+	var events []*sqlitePanicEvent
+	if err := s.db.Order("create_at desc").Find(&events).Error; err != nil {
+		return nil, err
+	}
+	ret := make([]storage.PanicEvent, 0, len(events))
+	for _, e := range events {
+		ret = append(ret, storage.PanicEvent{
+			ID:           e.ID,
+			CreateAt:     e.CreateAt,
+			Reason:       e.Reason,
+			RevokeTokens: e.RevokeTokens,
+			Caller:       e.Caller,
+		})
+	}
+	return ret, nil
+}
+
+func (s *panicStore) RevokeBefore() (time.Time, error) {
+	s.lk.Lock()
+	defer s.lk.Unlock()
+	if s.revokeBefore != nil {
+		return *s.revokeBefore, nil
+	}
+	var events []*sqlitePanicEvent
+	err := s.db.Where("revoke_tokens = ?", true).Order("create_at desc").Limit(1).Find(&events).Error
+	if err != nil {
+		return time.Time{}, err
+	}
+	var t time.Time
+	if len(events) > 0 {
+		t = events[0].CreateAt
+	}
+	s.revokeBefore = &t
+	return t, nil
+}
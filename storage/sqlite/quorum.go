@@ -0,0 +1,189 @@
+package sqlite
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/filecoin-project/venus-wallet/middleware"
+	"github.com/filecoin-project/venus-wallet/storage"
+)
+
+type sqliteQuorumRequest struct {
+	ID        string             `gorm:"primaryKey;type:varchar(256);not null"`
+	CreatedAt time.Time          `gorm:"index"`
+	Op        string             `gorm:"type:varchar(32);not null"`
+	Target    string             `gorm:"type:varchar(256)"`
+	Requester *middleware.Caller `gorm:"serializer:json"`
+	State     string             `gorm:"type:varchar(32);index;not null"`
+	Confirmer *middleware.Caller `gorm:"serializer:json"`
+	Comment   string             `gorm:"type:varchar(256)"`
+	Deadline  time.Time
+	DecideAt  time.Time
+}
+
+func (s *sqliteQuorumRequest) TableName() string {
+	return "quorum_request"
+}
+
+func (s *sqliteQuorumRequest) toQuorumRequest() *storage.QuorumRequest {
+	return &storage.QuorumRequest{
+		ID:        s.ID,
+		Op:        storage.QuorumOp(s.Op),
+		Target:    s.Target,
+		Requester: s.Requester,
+		State:     storage.QuorumState(s.State),
+		Confirmer: s.Confirmer,
+		Comment:   s.Comment,
+		CreateAt:  s.CreatedAt,
+		Deadline:  s.Deadline,
+		DecideAt:  s.DecideAt,
+	}
+}
+
+type sqliteQuorumAudit struct {
+	ID        string             `gorm:"primaryKey;type:varchar(256);not null"`
+	CreatedAt time.Time          `gorm:"index"`
+	RequestID string             `gorm:"type:varchar(256);index;not null"`
+	Action    string             `gorm:"type:varchar(32);not null"`
+	Caller    *middleware.Caller `gorm:"serializer:json"`
+	Comment   string             `gorm:"type:varchar(256)"`
+	Err       string
+}
+
+func (s *sqliteQuorumAudit) TableName() string {
+	return "quorum_audit"
+}
+
+type quorumStore struct {
+	db *gorm.DB
+}
+
+func NewQuorumStore(db *gorm.DB) (storage.IQuorumStore, error) {
+	if err := db.AutoMigrate(&sqliteQuorumRequest{}, &sqliteQuorumAudit{}); err != nil {
+		return nil, fmt.Errorf("init quorum store: %w", err)
+	}
+	return &quorumStore{db: db}, nil
+}
+
+func (s *quorumStore) Put(req *storage.QuorumRequest) error {
+	return s.db.Create(&sqliteQuorumRequest{
+		ID:        req.ID,
+		CreatedAt: req.CreateAt,
+		Op:        string(req.Op),
+		Target:    req.Target,
+		Requester: req.Requester,
+		State:     string(req.State),
+		Confirmer: req.Confirmer,
+		Comment:   req.Comment,
+		Deadline:  req.Deadline,
+		DecideAt:  req.DecideAt,
+	}).Error
+}
+
+func (s *quorumStore) Get(id string) (*storage.QuorumRequest, error) {
+	var req sqliteQuorumRequest
+	if err := s.db.Where("id = ?", id).First(&req).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, storage.ErrQuorumNotFound
+		}
+		return nil, err
+	}
+	return req.toQuorumRequest(), nil
+}
+
+func (s *quorumStore) List(state storage.QuorumState) ([]storage.QuorumRequest, error) {
+	var reqs []*sqliteQuorumRequest
+	query := s.db
+	if state != "" {
+		query = query.Where("state = ?", string(state))
+	}
+	if err := query.Order("created_at desc").Find(&reqs).Error; err != nil {
+		return nil, err
+	}
+
+	ret := make([]storage.QuorumRequest, 0, len(reqs))
+	for _, r := range reqs {
+		ret = append(ret, *r.toQuorumRequest())
+	}
+	return ret, nil
+}
+
+func (s *quorumStore) Decide(id string, state storage.QuorumState, confirmer *middleware.Caller, comment string) error {
+	res := s.db.Model(&sqliteQuorumRequest{}).
+		Where("id = ? and state = ?", id, string(storage.QuorumPending)).
+		Updates(&sqliteQuorumRequest{
+			State:     string(state),
+			Confirmer: confirmer,
+			Comment:   comment,
+			DecideAt:  time.Now(),
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		if _, err := s.Get(id); err != nil {
+			return err
+		}
+		return fmt.Errorf("quorum request %s is not pending", id)
+	}
+	return nil
+}
+
+func (s *quorumStore) ExpirePending() ([]string, error) {
+	var ids []string
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&sqliteQuorumRequest{}).Where("state = ?", string(storage.QuorumPending)).Pluck("id", &ids).Error; err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+		return tx.Model(&sqliteQuorumRequest{}).
+			Where("id in ?", ids).
+			Updates(map[string]interface{}{
+				"state":     string(storage.QuorumExpired),
+				"decide_at": time.Now(),
+			}).Error
+	})
+	return ids, err
+}
+
+func (s *quorumStore) AddAudit(audit *storage.QuorumAudit) error {
+	return s.db.Create(&sqliteQuorumAudit{
+		ID:        audit.ID,
+		CreatedAt: audit.CreateAt,
+		RequestID: audit.RequestID,
+		Action:    string(audit.Action),
+		Caller:    audit.Caller,
+		Comment:   audit.Comment,
+		Err:       audit.Err,
+	}).Error
+}
+
+func (s *quorumStore) ListAudit(requestID string) ([]storage.QuorumAudit, error) {
+	var audits []*sqliteQuorumAudit
+	query := s.db
+	if requestID != "" {
+		query = query.Where("request_id = ?", requestID)
+	}
+	if err := query.Order("created_at").Find(&audits).Error; err != nil {
+		return nil, err
+	}
+
+	ret := make([]storage.QuorumAudit, 0, len(audits))
+	for _, a := range audits {
+		ret = append(ret, storage.QuorumAudit{
+			ID:        a.ID,
+			RequestID: a.RequestID,
+			Action:    storage.QuorumAction(a.Action),
+			Caller:    a.Caller,
+			Comment:   a.Comment,
+			Err:       a.Err,
+			CreateAt:  a.CreatedAt,
+		})
+	}
+	return ret, nil
+}
@@ -0,0 +1,318 @@
+package sqlite
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/crypto"
+	"github.com/filecoin-project/venus/venus-shared/types"
+	"gorm.io/gorm"
+
+	vcrypto "github.com/filecoin-project/venus-wallet/crypto"
+	"github.com/filecoin-project/venus-wallet/storage"
+)
+
+// verifyBatch records read at once by VerifyChain
+const verifyBatch = 1000
+
+type sqliteRecordCheckpoint struct {
+	ID        uint64                 `gorm:"primaryKey;autoIncrement"`
+	Kind      storage.CheckpointKind `gorm:"type:varchar(16);index;not null"`
+	Seq       uint64                 `gorm:"index"`
+	Hash      []byte                 `gorm:"type:blob"`
+	CreatedAt time.Time
+	// RecordAt and RecordType the pruned record
+	RecordAt   time.Time     `gorm:"default:null"`
+	RecordType types.MsgType `gorm:"type:varchar(64);default:null"`
+	// Keep the retention of the record when it was pruned
+	Keep      time.Duration     `gorm:"default:null"`
+	Signer    string            `gorm:"type:varchar(256);default:null"`
+	Signature *crypto.Signature `gorm:"embedded;embeddedPrefix:signature_"`
+}
+
+func (s *sqliteRecordCheckpoint) TableName() string {
+	return "sign_record_checkpoint"
+}
+
+func (s *sqliteRecordCheckpoint) hashedTimes() []time.Time {
+	if s.Kind != storage.CheckpointPrune {
+		return nil
+	}
+	return []time.Time{s.CreatedAt, s.RecordAt}
+}
+
+func (s *sqliteRecordCheckpoint) toCheckpoint() *storage.RecordCheckpoint {
+	cp := &storage.RecordCheckpoint{
+		ID:         s.ID,
+		Kind:       s.Kind,
+		Seq:        s.Seq,
+		Hash:       s.Hash,
+		CreateAt:   s.CreatedAt,
+		RecordAt:   s.RecordAt,
+		RecordType: s.RecordType,
+		Keep:       s.Keep,
+		Signature:  s.Signature,
+	}
+	if s.Signer != "" {
+		cp.Signer = MustParseAddress(s.Signer)
+	}
+	if cp.Signature != nil && cp.Signature.Data == nil {
+		cp.Signature = nil
+	}
+	return cp
+}
+
+// chainHead the last chained record, or the last pruned one if the retention deleted them all
+func chainHead(tx *gorm.DB) (*storage.RecordCheckpoint, error) {
+	var head *storage.RecordCheckpoint
+
+	var record sqliteSignRecord
+	err := tx.Where("seq > 0").Order("seq desc").Take(&record).Error
+	if err == nil {
+		head = &storage.RecordCheckpoint{Seq: record.Seq, Hash: record.Hash, CreateAt: record.CreatedAt}
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("get chain head: %w", err)
+	}
+
+	prune, err := lastPrune(tx)
+	if err != nil {
+		return nil, err
+	}
+	if prune != nil && (head == nil || prune.Seq > head.Seq) {
+		head = prune
+	}
+	return head, nil
+}
+
+func lastPrune(tx *gorm.DB) (*storage.RecordCheckpoint, error) {
+	var cp sqliteRecordCheckpoint
+	err := tx.Where("kind = ?", storage.CheckpointPrune).Order("seq desc").Take(&cp).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get prune checkpoint: %w", err)
+	}
+	return cp.toCheckpoint(), nil
+}
+
+func (s *SqliteRecorder) ChainHead() (*storage.RecordCheckpoint, error) {
+	return chainHead(s.db)
+}
+
+func (s *SqliteRecorder) PutCheckpoint(cp *storage.RecordCheckpoint) error {
+	if cp.Kind != storage.CheckpointSigned || cp.Signature == nil {
+		return fmt.Errorf("only signed checkpoints can be saved")
+	}
+	return s.db.Create(&sqliteRecordCheckpoint{
+		Kind:      cp.Kind,
+		Seq:       cp.Seq,
+		Hash:      cp.Hash,
+		CreatedAt: cp.CreateAt,
+		Signer:    cp.Signer.String(),
+		Signature: cp.Signature,
+	}).Error
+}
+
+func (s *SqliteRecorder) SetPruneSigner(signer storage.PruneSigner) {
+	s.lk.Lock()
+	defer s.lk.Unlock()
+	s.pruneSigner = signer
+}
+
+func (s *SqliteRecorder) VerifyChain(auditKey address.Address) (*storage.RecordVerifyResult, error) {
+	res := &storage.RecordVerifyResult{}
+	problem := func(format string, args ...interface{}) {
+		res.Problems = append(res.Problems, fmt.Sprintf(format, args...))
+	}
+
+	var unchained int64
+	if err := s.db.Model(&sqliteSignRecord{}).Where("seq = 0").Count(&unchained).Error; err != nil {
+		return nil, err
+	}
+	res.Unchained = int(unchained)
+
+	var checkpoints []*sqliteRecordCheckpoint
+	if err := s.db.Where("kind = ?", storage.CheckpointSigned).Order("seq").Find(&checkpoints).Error; err != nil {
+		return nil, err
+	}
+	// the signed hashes, checked against the records while walking the chain
+	signed := make(map[uint64][]byte)
+	for _, c := range checkpoints {
+		cp := c.toCheckpoint()
+		res.Checkpoints++
+		res.LastCheckpoint = cp.Seq
+		if auditKey != address.Undef && cp.Signer != auditKey {
+			problem("checkpoint %d at seq %d is signed by %s, not by the audit key %s", cp.ID, cp.Seq, cp.Signer, auditKey)
+			continue
+		}
+		if cp.Signature == nil || vcrypto.Verify(cp.Signature, cp.Signer, cp.SigningBytes()) != nil {
+			problem("checkpoint %d at seq %d has an invalid signature", cp.ID, cp.Seq)
+			continue
+		}
+		signed[cp.Seq] = cp.Hash
+	}
+
+	records := &chainCursor{load: func(after uint64) ([]chainEntry, error) {
+		var rows []*sqliteSignRecord
+		if err := s.db.Where("seq > ?", after).Order("seq").Limit(verifyBatch).Find(&rows).Error; err != nil {
+			return nil, err
+		}
+		entries := make([]chainEntry, 0, len(rows))
+		for _, r := range rows {
+			entries = append(entries, chainEntry{seq: r.Seq, hash: r.Hash, record: r.toSignRecord()})
+		}
+		return entries, nil
+	}}
+	prunes := &chainCursor{load: func(after uint64) ([]chainEntry, error) {
+		var rows []*sqliteRecordCheckpoint
+		err := s.db.Where("kind = ? AND seq > ?", storage.CheckpointPrune, after).Order("seq").Limit(verifyBatch).Find(&rows).Error
+		if err != nil {
+			return nil, err
+		}
+		entries := make([]chainEntry, 0, len(rows))
+		for _, r := range rows {
+			entries = append(entries, chainEntry{seq: r.Seq, hash: r.Hash, prune: r.toCheckpoint()})
+		}
+		return entries, nil
+	}}
+
+	var prev *chainEntry
+	for {
+		r, err := records.peek()
+		if err != nil {
+			return nil, err
+		}
+		p, err := prunes.peek()
+		if err != nil {
+			return nil, err
+		}
+		var e *chainEntry
+		switch {
+		case r == nil && p == nil:
+		case p == nil || r != nil && r.seq <= p.seq:
+			e = records.pop()
+		default:
+			e = prunes.pop()
+		}
+		if e == nil {
+			break
+		}
+
+		if e.record == nil {
+			cp := e.prune
+			// a forged prune checkpoint hides a deleted record
+			if auditKey != address.Undef && cp.Signer != auditKey {
+				problem("pruned record %d isn't signed by the audit key %s", cp.Seq, auditKey)
+			} else if cp.Signer != address.Undef &&
+				(cp.Signature == nil || vcrypto.Verify(cp.Signature, cp.Signer, cp.SigningBytes()) != nil) {
+				problem("pruned record %d has an invalid signature", cp.Seq)
+			}
+			// against the retention in force when it was pruned, the later changes don't apply to it
+			if cp.RecordAt.IsZero() || cp.Keep <= 0 || cp.RecordAt.After(cp.CreateAt.Add(-cp.Keep)) {
+				problem("pruned record %d (%s at %s) wasn't past its retention when pruned at %s",
+					cp.Seq, cp.RecordType, cp.RecordAt.Format(time.RFC3339), cp.CreateAt.Format(time.RFC3339))
+			}
+			// the chain starts with the last pruned record, the ones before it were compacted
+			if prev == nil {
+				prev = e
+				continue
+			}
+			res.Pruned++
+			if e.seq == prev.seq && prev.record != nil {
+				problem("record %d (%s) was pruned, but is still present", prev.seq, prev.record.ID)
+			} else if e.seq != prev.seq+1 {
+				problem("records %d to %d are missing", prev.seq+1, e.seq-1)
+			}
+			if hash, ok := signed[e.seq]; ok && !bytes.Equal(hash, e.hash) {
+				problem("pruned record %d doesn't match the signed checkpoint", e.seq)
+			}
+			prev = e
+			continue
+		}
+
+		record := e.record
+		if prev == nil {
+			prev = &chainEntry{}
+		}
+		res.Records++
+		if res.FirstSeq == 0 {
+			res.FirstSeq = record.Seq
+		}
+		res.LastSeq = record.Seq
+
+		if record.Seq != prev.seq+1 {
+			problem("records %d to %d are missing", prev.seq+1, record.Seq-1)
+		}
+		if !bytes.Equal(record.PrevHash, prev.hash) {
+			problem("record %d (%s) doesn't chain to the previous record, it was reordered or the previous one was modified", record.Seq, record.ID)
+		}
+		if !bytes.Equal(record.ChainHash(), record.Hash) {
+			problem("record %d (%s) was modified", record.Seq, record.ID)
+		}
+		if hash, ok := signed[record.Seq]; ok && !bytes.Equal(hash, record.Hash) {
+			problem("record %d (%s) doesn't match the signed checkpoint", record.Seq, record.ID)
+		}
+		prev = e
+	}
+	if prev == nil {
+		prev = &chainEntry{}
+	}
+
+	var chained int64
+	if err := s.db.Model(&sqliteSignRecord{}).Where("seq > 0").Count(&chained).Error; err != nil {
+		return nil, err
+	}
+	if int(chained) != res.Records {
+		problem("%d records are outside of the chain, they were inserted or have a duplicated seq", int(chained)-res.Records)
+	}
+
+	// a signed checkpoint past the last record tells the latest records were deleted
+	if res.LastCheckpoint > prev.seq {
+		problem("records after %d are missing, the checkpoint at seq %d was signed", prev.seq, res.LastCheckpoint)
+	}
+	return res, nil
+}
+
+// chainEntry a record, or a record deleted by the retention of which only the prune checkpoint is kept
+type chainEntry struct {
+	seq    uint64
+	hash   []byte
+	record *storage.SignRecord
+	prune  *storage.RecordCheckpoint
+}
+
+// chainCursor reads the entries in the order of the chain, in batches
+type chainCursor struct {
+	load  func(after uint64) ([]chainEntry, error)
+	buf   []chainEntry
+	after uint64
+	done  bool
+}
+
+func (c *chainCursor) peek() (*chainEntry, error) {
+	if len(c.buf) == 0 && !c.done {
+		buf, err := c.load(c.after)
+		if err != nil {
+			return nil, err
+		}
+		c.done = len(buf) < verifyBatch
+		if len(buf) > 0 {
+			c.after = buf[len(buf)-1].seq
+		}
+		c.buf = buf
+	}
+	if len(c.buf) == 0 {
+		return nil, nil
+	}
+	return &c.buf[0], nil
+}
+
+func (c *chainCursor) pop() *chainEntry {
+	e := &c.buf[0]
+	c.buf = c.buf[1:]
+	return e
+}
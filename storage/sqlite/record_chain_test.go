@@ -0,0 +1,270 @@
+package sqlite
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/venus/venus-shared/types"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/filecoin-project/venus-wallet/config"
+	"github.com/filecoin-project/venus-wallet/crypto"
+	"github.com/filecoin-project/venus-wallet/storage"
+)
+
+func newTestChainRecorder(t *testing.T, n int) (*SqliteRecorder, *gorm.DB) {
+	db, err := gorm.Open(sqlite.Open(fmt.Sprintf("file:%s-%s?mode=memory&cache=shared", t.Name(), uuid.New())), &gorm.Config{})
+	assert.NoError(t, err)
+	r, err := NewSqliteRecorder(db, nil)
+	assert.NoError(t, err)
+	recorder := r.(*SqliteRecorder)
+
+	signer, err := address.NewIDAddress(1000)
+	assert.NoError(t, err)
+	for i := 0; i < n; i++ {
+		assert.NoError(t, recorder.Record(&storage.SignRecord{
+			ID:      uuid.New().String(),
+			Type:    types.MTChainMsg,
+			Signer:  signer,
+			RawMsg:  []byte{byte(i)},
+			Summary: fmt.Sprintf("record %d", i),
+		}))
+	}
+	return recorder, db
+}
+
+func TestRecordChain_Intact(t *testing.T) {
+	recorder, _ := newTestChainRecorder(t, 5)
+
+	res, err := recorder.VerifyChain(address.Undef)
+	assert.NoError(t, err)
+	assert.Empty(t, res.Problems)
+	assert.Equal(t, 5, res.Records)
+	assert.Equal(t, uint64(1), res.FirstSeq)
+	assert.Equal(t, uint64(5), res.LastSeq)
+
+	// records are never updated
+	assert.Error(t, recorder.db.Exec("UPDATE sign_record SET summary = 'x'").Error)
+}
+
+func TestRecordChain_Tampered(t *testing.T) {
+	t.Run("deleted", func(t *testing.T) {
+		recorder, db := newTestChainRecorder(t, 5)
+		assert.NoError(t, db.Exec("DELETE FROM sign_record WHERE seq = 3").Error)
+		res, err := recorder.VerifyChain(address.Undef)
+		assert.NoError(t, err)
+		assert.NotEmpty(t, res.Problems)
+	})
+
+	t.Run("modified", func(t *testing.T) {
+		recorder, db := newTestChainRecorder(t, 5)
+		assert.NoError(t, db.Exec("DROP TRIGGER sign_record_append_only").Error)
+		assert.NoError(t, db.Exec("UPDATE sign_record SET err = 'changed' WHERE seq = 2").Error)
+		res, err := recorder.VerifyChain(address.Undef)
+		assert.NoError(t, err)
+		assert.Len(t, res.Problems, 1)
+		assert.Contains(t, res.Problems[0], "record 2")
+	})
+
+	t.Run("reordered", func(t *testing.T) {
+		recorder, db := newTestChainRecorder(t, 5)
+		assert.NoError(t, db.Exec("DROP TRIGGER sign_record_append_only").Error)
+		assert.NoError(t, db.Exec("UPDATE sign_record SET seq = 100 WHERE seq = 2").Error)
+		assert.NoError(t, db.Exec("UPDATE sign_record SET seq = 2 WHERE seq = 3").Error)
+		assert.NoError(t, db.Exec("UPDATE sign_record SET seq = 3 WHERE seq = 100").Error)
+		res, err := recorder.VerifyChain(address.Undef)
+		assert.NoError(t, err)
+		assert.NotEmpty(t, res.Problems)
+	})
+}
+
+func TestRecordChain_Checkpoint(t *testing.T) {
+	recorder, db := newTestChainRecorder(t, 3)
+
+	key, err := crypto.GeneratePrivateKey(types.SigTypeSecp256k1)
+	assert.NoError(t, err)
+	auditKey, err := key.Address()
+	assert.NoError(t, err)
+
+	head, err := recorder.ChainHead()
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(3), head.Seq)
+	cp := &storage.RecordCheckpoint{Kind: storage.CheckpointSigned, Seq: head.Seq, Hash: head.Hash, CreateAt: time.Now(), Signer: auditKey}
+	cp.Signature, err = key.Sign(cp.SigningBytes())
+	assert.NoError(t, err)
+	assert.NoError(t, recorder.PutCheckpoint(cp))
+
+	res, err := recorder.VerifyChain(auditKey)
+	assert.NoError(t, err)
+	assert.Empty(t, res.Problems)
+	assert.Equal(t, 1, res.Checkpoints)
+
+	other, err := address.NewIDAddress(1001)
+	assert.NoError(t, err)
+	res, err = recorder.VerifyChain(other)
+	assert.NoError(t, err)
+	assert.Len(t, res.Problems, 1)
+
+	// removing the last records is only noticed thanks to the checkpoint
+	assert.NoError(t, db.Exec("DELETE FROM sign_record WHERE seq = 3").Error)
+	res, err = recorder.VerifyChain(auditKey)
+	assert.NoError(t, err)
+	assert.Len(t, res.Problems, 1)
+	assert.Contains(t, res.Problems[0], "after 2")
+}
+
+func TestRecordChain_Prune(t *testing.T) {
+	recorder, _ := newTestChainRecorder(t, 4)
+
+	assert.NoError(t, recorder.prune(time.Now().Add(defaultKeepDuration+time.Minute)))
+	head, err := recorder.ChainHead()
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(4), head.Seq)
+
+	// the new records chain to the pruned ones
+	signer, err := address.NewIDAddress(1000)
+	assert.NoError(t, err)
+	assert.NoError(t, recorder.Record(&storage.SignRecord{ID: uuid.New().String(), Type: types.MTChainMsg, Signer: signer}))
+
+	res, err := recorder.VerifyChain(address.Undef)
+	assert.NoError(t, err)
+	assert.Empty(t, res.Problems)
+	assert.Equal(t, 1, res.Records)
+	assert.Equal(t, uint64(5), res.FirstSeq)
+}
+
+func TestRecordChain_PruneRetentionChanged(t *testing.T) {
+	recorder, _ := newTestChainRecorder(t, 4)
+	assert.NoError(t, recorder.prune(time.Now().Add(defaultKeepDuration+time.Minute)))
+
+	// the records pruned before stay valid after the retention is raised
+	apply, err := recorder.Reload(&config.SignRecorderConfig{Enable: true, KeepDuration: "8760h",
+		KeepDurations: map[string]string{string(types.MTChainMsg): "87600h"}})
+	assert.NoError(t, err)
+	apply()
+	res, err := recorder.VerifyChain(address.Undef)
+	assert.NoError(t, err)
+	assert.Empty(t, res.Problems)
+	head, err := recorder.ChainHead()
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(4), head.Seq)
+	assert.Equal(t, defaultKeepDuration, head.Keep)
+}
+
+func TestRecordChain_ForgedPrune(t *testing.T) {
+	key, err := crypto.GeneratePrivateKey(types.KeyType2Sign(types.KTSecp256k1))
+	assert.NoError(t, err)
+	auditKey, err := key.Address()
+	assert.NoError(t, err)
+	signPrune := func(cps []*storage.RecordCheckpoint) error {
+		for _, cp := range cps {
+			cp.Signer = auditKey
+			if cp.Signature, err = key.Sign(cp.SigningBytes()); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	recorder, db := newTestChainRecorder(t, 4)
+	recorder.SetPruneSigner(signPrune)
+	assert.NoError(t, recorder.prune(time.Now().Add(defaultKeepDuration+time.Minute)))
+	res, err := recorder.VerifyChain(auditKey)
+	assert.NoError(t, err)
+	assert.Empty(t, res.Problems)
+
+	var cp sqliteRecordCheckpoint
+	assert.NoError(t, db.Where("kind = ?", storage.CheckpointPrune).Take(&cp).Error)
+	assert.Equal(t, types.MTChainMsg, cp.RecordType)
+	assert.False(t, cp.RecordAt.IsZero())
+
+	// the records are kept if the signature fails
+	recorder, _ = newTestChainRecorder(t, 2)
+	recorder.SetPruneSigner(func([]*storage.RecordCheckpoint) error { return fmt.Errorf("locked") })
+	assert.Error(t, recorder.prune(time.Now().Add(defaultKeepDuration+time.Minute)))
+	res, err = recorder.VerifyChain(address.Undef)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, res.Records)
+
+	// a record deleted and replaced by a prune checkpoint before its retention
+	recorder, db = newTestChainRecorder(t, 4)
+	var record sqliteSignRecord
+	assert.NoError(t, db.Where("seq = 2").Take(&record).Error)
+	assert.NoError(t, db.Exec("DELETE FROM sign_record WHERE seq = 2").Error)
+	assert.NoError(t, db.Create(&sqliteRecordCheckpoint{
+		Kind: storage.CheckpointPrune, Seq: record.Seq, Hash: record.Hash,
+		CreatedAt: time.Now(), RecordAt: record.CreatedAt, RecordType: record.Type, Keep: defaultKeepDuration,
+	}).Error)
+	res, err = recorder.VerifyChain(address.Undef)
+	assert.NoError(t, err)
+	assert.Len(t, res.Problems, 1)
+	assert.Contains(t, res.Problems[0], "retention")
+
+	// an unsigned one is rejected when there is an audit key, a signed one can't be moved
+	assert.NoError(t, db.Exec("UPDATE sign_record_checkpoint SET created_at = ?", time.Now().Add(defaultKeepDuration+time.Minute)).Error)
+	res, err = recorder.VerifyChain(address.Undef)
+	assert.NoError(t, err)
+	assert.Empty(t, res.Problems)
+	res, err = recorder.VerifyChain(auditKey)
+	assert.NoError(t, err)
+	assert.Len(t, res.Problems, 1)
+	assert.Contains(t, res.Problems[0], "audit key")
+
+	recorder, db = newTestChainRecorder(t, 4)
+	recorder.SetPruneSigner(signPrune)
+	assert.NoError(t, recorder.prune(time.Now().Add(defaultKeepDuration+time.Minute)))
+	assert.NoError(t, db.Exec("UPDATE sign_record_checkpoint SET record_type = ?", types.MTBlock).Error)
+	res, err = recorder.VerifyChain(auditKey)
+	assert.NoError(t, err)
+	assert.Len(t, res.Problems, 1)
+	assert.Contains(t, res.Problems[0], "invalid signature")
+}
+
+func TestRecordChain_Batch(t *testing.T) {
+	recorder, _ := newTestChainRecorder(t, 2)
+
+	signer, err := address.NewIDAddress(1000)
+	assert.NoError(t, err)
+	batch := make([]*storage.SignRecord, 0, 3)
+	for i := 0; i < 3; i++ {
+		batch = append(batch, &storage.SignRecord{ID: uuid.New().String(), Type: types.MTChainMsg, Signer: signer, RawMsg: []byte{byte(i)}})
+	}
+	assert.NoError(t, recorder.RecordBatch(batch))
+	for i, r := range batch {
+		assert.Equal(t, uint64(i+3), r.Seq)
+	}
+
+	res, err := recorder.VerifyChain(address.Undef)
+	assert.NoError(t, err)
+	assert.Empty(t, res.Problems)
+	assert.Equal(t, 5, res.Records)
+}
+
+func TestRecordChain_TimePrecision(t *testing.T) {
+	recorder, db := newTestChainRecorder(t, 1)
+	signer, err := address.NewIDAddress(1000)
+	assert.NoError(t, err)
+
+	// a record of an older version, hashed with the nanoseconds
+	head, err := chainHead(db)
+	assert.NoError(t, err)
+	old := &storage.SignRecord{ID: uuid.New().String(), CreateAt: time.Unix(0, 1_700_000_000_000_000_123), Type: types.MTChainMsg,
+		Signer: signer, Seq: head.Seq + 1, PrevHash: head.Hash}
+	old.Hash = old.ChainHash()
+	assert.NoError(t, db.Create(newFromSignRecord(old)).Error)
+
+	// the new ones are hashed with the microseconds the server databases keep
+	record := &storage.SignRecord{ID: uuid.New().String(), CreateAt: time.Unix(0, 1_700_000_001_000_000_456), Type: types.MTChainMsg, Signer: signer}
+	assert.NoError(t, recorder.Record(record))
+	assert.Equal(t, time.Unix(0, 1_700_000_001_000_000_000), record.CreateAt)
+
+	res, err := recorder.VerifyChain(address.Undef)
+	assert.NoError(t, err)
+	assert.Empty(t, res.Problems)
+	assert.Equal(t, 3, res.Records)
+}
@@ -0,0 +1,279 @@
+package sqlite
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	gomysql "github.com/go-sql-driver/mysql"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+
+	"github.com/filecoin-project/venus-wallet/config"
+	"github.com/filecoin-project/venus-wallet/storage"
+)
+
+// the types of recorder database
+const (
+	recordDBSqlite = "sqlite"
+	recordDBMySQL  = "mysql"
+)
+
+// migrateBatch rows copied at once by MigrateRecords
+const migrateBatch = 1000
+
+// NewRecorder the recorder on its own database if SignRecorder.DB is set, on the keystore database otherwise
+func NewRecorder(db *gorm.DB, dbCfg *config.DBConfig, cfg *config.SignRecorderConfig) (storage.IRecorder, error) {
+	if !separateRecordDB(dbCfg, cfg) {
+		return NewSqliteRecorder(db, cfg)
+	}
+	// the records left in the keystore database would fork the chain
+	count, err := countRecords(db)
+	if err != nil {
+		return nil, fmt.Errorf("init sqlite_recorder: %w", err)
+	}
+	if count > 0 {
+		return nil, fmt.Errorf("init sqlite_recorder: the keystore database still holds %d records, "+
+			"stop the wallet and run `record migrate` to move them to %s", count, cfg.DB.Conn)
+	}
+	rdb, err := OpenRecordDB(cfg.DB)
+	if err != nil {
+		return nil, fmt.Errorf("init sqlite_recorder: %w", err)
+	}
+	return NewSqliteRecorder(rdb, cfg)
+}
+
+// separateRecordDB the recorder has a database apart from the keystore
+func separateRecordDB(dbCfg *config.DBConfig, cfg *config.SignRecorderConfig) bool {
+	return cfg != nil && cfg.Enable && cfg.DB != nil && cfg.DB.Conn != "" && (dbCfg == nil || cfg.DB.Conn != dbCfg.Conn)
+}
+
+// OpenRecordDB opens the database of the recorder, sqlite if the type is empty
+func OpenRecordDB(cfg *config.DBConfig) (*gorm.DB, error) {
+	switch cfg.Type {
+	case "", recordDBSqlite:
+		return openDB(cfg)
+	case recordDBMySQL:
+		return openMySQL(cfg)
+	default:
+		return nil, fmt.Errorf("unsupported record database type %s, %s or %s is supported", cfg.Type, recordDBSqlite, recordDBMySQL)
+	}
+}
+
+// openMySQL the times are read back as time.Time in UTC, and kept to the microsecond that the record hashes cover
+func openMySQL(cfg *config.DBConfig) (*gorm.DB, error) {
+	dsn, err := gomysql.ParseDSN(cfg.Conn)
+	if err != nil {
+		return nil, fmt.Errorf("parse mysql dsn: %w", err)
+	}
+	dsn.ParseTime = true
+	dsn.Loc = time.UTC
+	precision := 6
+	db, err := gorm.Open(mysql.New(mysql.Config{
+		DSN:                      dsn.FormatDSN(),
+		DefaultDatetimePrecision: &precision,
+	}), &gorm.Config{})
+	if err != nil {
+		return nil, fmt.Errorf("open database(%s@%s/%s) failed:%w", dsn.User, dsn.Addr, dsn.DBName, err)
+	}
+	sqldb, err := db.DB()
+	if err != nil {
+		return nil, fmt.Errorf("sqlDb failed, %w", err)
+	}
+	sqldb.SetConnMaxIdleTime(300)
+	sqldb.SetMaxIdleConns(8)
+	sqldb.SetMaxOpenConns(64)
+	return db, nil
+}
+
+func isMySQL(db *gorm.DB) bool {
+	return db.Dialector.Name() == recordDBMySQL
+}
+
+// createAbortTrigger (re)creates the trigger name aborting the event on table, the update of the columns only if any.
+// The mysql user needs the TRIGGER privilege, and SUPER or log_bin_trust_function_creators if the binary log is on.
+func createAbortTrigger(db *gorm.DB, name, event, table string, columns []string) error {
+	if err := db.Exec(`DROP TRIGGER IF EXISTS ` + name).Error; err != nil {
+		return err
+	}
+	msg := table + " is append-only"
+	if !isMySQL(db) {
+		of := ""
+		if len(columns) > 0 {
+			of = " OF " + strings.Join(columns, ", ")
+		}
+		return db.Exec(fmt.Sprintf(`CREATE TRIGGER %s BEFORE %s%s ON %s
+BEGIN SELECT RAISE(ABORT, '%s'); END`, name, event, of, table, msg)).Error
+	}
+	abort := fmt.Sprintf(`SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = '%s'`, msg)
+	if len(columns) > 0 {
+		same := make([]string, 0, len(columns))
+		for _, c := range columns {
+			same = append(same, fmt.Sprintf("OLD.%s <=> NEW.%s", c, c))
+		}
+		abort = fmt.Sprintf(`IF NOT (%s) THEN %s; END IF`, strings.Join(same, " AND "), abort)
+	}
+	return db.Exec(fmt.Sprintf(`CREATE TRIGGER %s BEFORE %s ON %s FOR EACH ROW
+BEGIN %s; END`, name, event, table, abort)).Error
+}
+
+// countRecords the sign records, checkpoints and admin records of the database
+func countRecords(db *gorm.DB) (int64, error) {
+	var total int64
+	for _, model := range []interface{}{&sqliteSignRecord{}, &sqliteRecordCheckpoint{}, &sqliteAdminRecord{}} {
+		if !db.Migrator().HasTable(model) {
+			continue
+		}
+		var count int64
+		if err := db.Model(model).Count(&count).Error; err != nil {
+			return 0, err
+		}
+		total += count
+	}
+	return total, nil
+}
+
+// RecordMigration the rows moved by MigrateRecords
+type RecordMigration struct {
+	SignRecords  int64
+	Checkpoints  int64
+	AdminRecords int64
+}
+
+// MigrateRecords moves the records from the keystore database src to the recorder database dst, the wallet must be stopped.
+// The rows are copied as they are, so that the hash chain and the signed checkpoints stay valid. The tables are then
+// dropped from src, which is vacuumed to give the space back. dst must not hold any record yet, unless it holds
+// exactly the rows of src, left by a run that failed after the copy, the rows are then only dropped from src.
+func MigrateRecords(src, dst *gorm.DB) (*RecordMigration, error) {
+	ret := &RecordMigration{}
+	// the admin records are kept even when the sign records are disabled
+	if !src.Migrator().HasTable(&sqliteSignRecord{}) && !src.Migrator().HasTable(&sqliteAdminRecord{}) {
+		return ret, nil
+	}
+	if err := dst.AutoMigrate(&sqliteSignRecord{}, &sqliteRecordCheckpoint{}, &sqliteAdminRecord{}); err != nil {
+		return nil, fmt.Errorf("init record database: %w", err)
+	}
+	count, err := countRecords(dst)
+	if err != nil {
+		return nil, err
+	}
+	if count > 0 {
+		copied, err := sameRecords(src, dst)
+		if err != nil {
+			return nil, err
+		}
+		if copied == nil {
+			return nil, fmt.Errorf("the record database already holds %d records", count)
+		}
+		return copied, dropRecords(src)
+	}
+
+	err = dst.Transaction(func(tx *gorm.DB) error {
+		var err error
+		if ret.SignRecords, err = copyRows[sqliteSignRecord](src, tx); err != nil {
+			return fmt.Errorf("copy sign records: %w", err)
+		}
+		if ret.Checkpoints, err = copyRows[sqliteRecordCheckpoint](src, tx); err != nil {
+			return fmt.Errorf("copy checkpoints: %w", err)
+		}
+		if ret.AdminRecords, err = copyRows[sqliteAdminRecord](src, tx); err != nil {
+			return fmt.Errorf("copy admin records: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if count, err = countRecords(dst); err != nil {
+		return nil, err
+	}
+	if count != ret.SignRecords+ret.Checkpoints+ret.AdminRecords {
+		return nil, fmt.Errorf("the record database holds %d records after the copy, expected %d",
+			count, ret.SignRecords+ret.Checkpoints+ret.AdminRecords)
+	}
+
+	return ret, dropRecords(src)
+}
+
+// dropRecords drops the record tables of the keystore database once they are in the record database
+func dropRecords(src *gorm.DB) error {
+	if err := src.Migrator().DropTable(&sqliteSignRecord{}, &sqliteRecordCheckpoint{}, &sqliteAdminRecord{}); err != nil {
+		return fmt.Errorf("drop records from the keystore database: %w", err)
+	}
+	if err := src.Exec("VACUUM").Error; err != nil {
+		return fmt.Errorf("vacuum the keystore database: %w", err)
+	}
+	return nil
+}
+
+// sameRecords the rows of src if dst holds exactly the same ones, nil otherwise
+func sameRecords(src, dst *gorm.DB) (*RecordMigration, error) {
+	ret := &RecordMigration{}
+	for _, table := range []struct {
+		model interface{}
+		count *int64
+	}{
+		{&sqliteSignRecord{}, &ret.SignRecords},
+		{&sqliteRecordCheckpoint{}, &ret.Checkpoints},
+		{&sqliteAdminRecord{}, &ret.AdminRecords},
+	} {
+		srcIDs, err := rowIDs(src, table.model)
+		if err != nil {
+			return nil, err
+		}
+		dstIDs, err := rowIDs(dst, table.model)
+		if err != nil {
+			return nil, err
+		}
+		if !slices.Equal(srcIDs, dstIDs) {
+			return nil, nil
+		}
+		*table.count = int64(len(srcIDs))
+	}
+	return ret, nil
+}
+
+func rowIDs(db *gorm.DB, model interface{}) ([]string, error) {
+	var ids []string
+	if !db.Migrator().HasTable(model) {
+		return ids, nil
+	}
+	err := db.Model(model).Order("id").Pluck("id", &ids).Error
+	return ids, err
+}
+
+// hashedRow a row whose times are covered by the record hash or the checkpoint signature
+type hashedRow interface {
+	hashedTimes() []time.Time
+}
+
+func copyRows[T any](src, dst *gorm.DB) (int64, error) {
+	if !src.Migrator().HasTable(new(T)) {
+		return 0, nil
+	}
+	var (
+		rows  []*T
+		count int64
+	)
+	err := src.Model(new(T)).FindInBatches(&rows, migrateBatch, func(tx *gorm.DB, batch int) error {
+		// one by one, sqlite doesn't take the DEFAULT of the null columns in multi-row inserts
+		for _, row := range rows {
+			// the records written before the times were truncated would no longer match their hash in mysql
+			if hashed, ok := any(row).(hashedRow); ok && isMySQL(dst) {
+				for _, t := range hashed.hashedTimes() {
+					if !t.Equal(t.Truncate(storage.TimePrecision)) {
+						return fmt.Errorf("a record has the time %s, finer than the %s mysql keeps, "+
+							"the records must stay on sqlite", t.Format(time.RFC3339Nano), storage.TimePrecision)
+					}
+				}
+			}
+			if err := dst.Create(row).Error; err != nil {
+				return err
+			}
+		}
+		count += int64(len(rows))
+		return nil
+	}).Error
+	return count, err
+}
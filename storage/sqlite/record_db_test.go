@@ -0,0 +1,135 @@
+package sqlite
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/venus/venus-shared/types"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/venus-wallet/config"
+	"github.com/filecoin-project/venus-wallet/crypto"
+	"github.com/filecoin-project/venus-wallet/storage"
+)
+
+func TestMigrateRecords(t *testing.T) {
+	dir := t.TempDir()
+	dbCfg := &config.DBConfig{Conn: filepath.Join(dir, "keystore.sqlit"), Type: "sqlite"}
+	cfg := &config.SignRecorderConfig{Enable: true}
+
+	keystore, err := NewDB(dbCfg)
+	require.NoError(t, err)
+	r, err := NewRecorder(keystore, dbCfg, cfg)
+	require.NoError(t, err)
+
+	key, err := crypto.GeneratePrivateKey(types.KeyType2Sign(types.KTSecp256k1))
+	require.NoError(t, err)
+	auditKey, err := key.Address()
+	require.NoError(t, err)
+	signer, err := address.NewIDAddress(1000)
+	require.NoError(t, err)
+	for i := 0; i < migrateBatch+10; i++ {
+		require.NoError(t, r.Record(&storage.SignRecord{
+			ID:     uuid.New().String(),
+			Type:   types.MTChainMsg,
+			Signer: signer,
+			RawMsg: []byte{byte(i)},
+		}))
+	}
+	head, err := r.ChainHead()
+	require.NoError(t, err)
+	cp := &storage.RecordCheckpoint{Kind: storage.CheckpointSigned, Seq: head.Seq, Hash: head.Hash, CreateAt: time.Now(), Signer: auditKey}
+	cp.Signature, err = key.Sign(cp.SigningBytes())
+	require.NoError(t, err)
+	require.NoError(t, r.PutCheckpoint(cp))
+	require.NoError(t, r.RecordAdmin(&storage.AdminRecord{ID: "export", Op: storage.AdminWalletExport, Target: signer.String()}))
+
+	// the records must be moved before the recorder uses its own database
+	cfg.DB = &config.DBConfig{Conn: filepath.Join(dir, "sign_record.sqlit"), Type: "sqlite"}
+	_, err = NewRecorder(keystore, dbCfg, cfg)
+	require.ErrorContains(t, err, "record migrate")
+
+	recordDB, err := OpenRecordDB(cfg.DB)
+	require.NoError(t, err)
+	moved, err := MigrateRecords(keystore, recordDB)
+	require.NoError(t, err)
+	assert.Equal(t, &RecordMigration{SignRecords: migrateBatch + 10, Checkpoints: 1, AdminRecords: 1}, moved)
+	assert.False(t, keystore.Migrator().HasTable(&sqliteSignRecord{}))
+	assert.True(t, keystore.Migrator().HasTable(TBWallet))
+
+	// the chain and the checkpoint are still valid, and new records chain to the moved ones
+	r, err = NewRecorder(keystore, dbCfg, cfg)
+	require.NoError(t, err)
+	require.NoError(t, r.Record(&storage.SignRecord{ID: "after", Type: types.MTChainMsg, Signer: signer, RawMsg: []byte("after")}))
+	res, err := r.VerifyChain(auditKey)
+	require.NoError(t, err)
+	assert.Empty(t, res.Problems)
+	assert.Equal(t, migrateBatch+11, res.Records)
+	assert.Equal(t, 1, res.Checkpoints)
+	admin, err := r.QueryAdminRecord(&storage.AdminQueryParams{ID: "export"})
+	require.NoError(t, err)
+	assert.Len(t, admin, 1)
+	assert.False(t, keystore.Migrator().HasTable(&sqliteSignRecord{}), fmt.Sprintf("records written to %s", dbCfg.Conn))
+
+	// nothing left to move, and the record database isn't overwritten
+	moved, err = MigrateRecords(keystore, recordDB)
+	require.NoError(t, err)
+	assert.Equal(t, &RecordMigration{}, moved)
+	require.NoError(t, keystore.AutoMigrate(&sqliteSignRecord{}))
+	require.NoError(t, keystore.Create(&sqliteSignRecord{ID: "late", Signer: signer.String()}).Error)
+	_, err = MigrateRecords(keystore, recordDB)
+	assert.ErrorContains(t, err, "already holds")
+}
+
+func TestMigrateRecords_Resume(t *testing.T) {
+	dir := t.TempDir()
+	keystore, err := NewDB(&config.DBConfig{Conn: filepath.Join(dir, "keystore.sqlit"), Type: "sqlite"})
+	require.NoError(t, err)
+	r, err := NewSqliteRecorder(keystore, &config.SignRecorderConfig{Enable: true})
+	require.NoError(t, err)
+	signer, err := address.NewIDAddress(1000)
+	require.NoError(t, err)
+	for i := 0; i < 3; i++ {
+		require.NoError(t, r.Record(&storage.SignRecord{ID: uuid.New().String(), Type: types.MTChainMsg, Signer: signer}))
+	}
+	require.NoError(t, r.RecordAdmin(&storage.AdminRecord{ID: "export", Op: storage.AdminWalletExport}))
+
+	// a run that copied the rows but failed before dropping them from the keystore
+	recordDB, err := OpenRecordDB(&config.DBConfig{Conn: filepath.Join(dir, "sign_record.sqlit"), Type: "sqlite"})
+	require.NoError(t, err)
+	require.NoError(t, recordDB.AutoMigrate(&sqliteSignRecord{}, &sqliteRecordCheckpoint{}, &sqliteAdminRecord{}))
+	_, err = copyRows[sqliteSignRecord](keystore, recordDB)
+	require.NoError(t, err)
+	_, err = copyRows[sqliteAdminRecord](keystore, recordDB)
+	require.NoError(t, err)
+
+	moved, err := MigrateRecords(keystore, recordDB)
+	require.NoError(t, err)
+	assert.Equal(t, &RecordMigration{SignRecords: 3, AdminRecords: 1}, moved)
+	assert.False(t, keystore.Migrator().HasTable(&sqliteSignRecord{}))
+	count, err := countRecords(recordDB)
+	require.NoError(t, err)
+	assert.EqualValues(t, 4, count)
+
+	// a partial copy isn't taken for a finished one
+	keystore, err = NewDB(&config.DBConfig{Conn: filepath.Join(dir, "keystore2.sqlit"), Type: "sqlite"})
+	require.NoError(t, err)
+	r, err = NewSqliteRecorder(keystore, &config.SignRecorderConfig{Enable: true})
+	require.NoError(t, err)
+	require.NoError(t, r.Record(&storage.SignRecord{ID: "other", Type: types.MTChainMsg, Signer: signer}))
+	_, err = MigrateRecords(keystore, recordDB)
+	assert.ErrorContains(t, err, "already holds")
+	assert.True(t, keystore.Migrator().HasTable(&sqliteSignRecord{}))
+}
+
+func TestOpenRecordDB(t *testing.T) {
+	_, err := OpenRecordDB(&config.DBConfig{Conn: "postgres://127.0.0.1/wallet", Type: "postgres"})
+	assert.ErrorContains(t, err, "unsupported")
+	_, err = OpenRecordDB(&config.DBConfig{Conn: "127.0.0.1:3306/wallet", Type: "mysql"})
+	assert.ErrorContains(t, err, "parse mysql dsn")
+}
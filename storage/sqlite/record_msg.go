@@ -0,0 +1,93 @@
+package sqlite
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/venus/venus-shared/types"
+	"gorm.io/gorm"
+
+	"github.com/filecoin-project/venus-wallet/storage"
+)
+
+// hashedColumns the columns covered by the record hash, which are never updated
+var hashedColumns = []string{"id", "created_at", "type", "signer", "err", "raw_msg", "signature_type", "signature_data",
+	"caller", "msg_c_id", "extra", "to_sign", "duration", "summary", "seq", "prev_hash", "hash"}
+
+// valueWidth values are zero padded decimal attoFIL, so that they compare as text, the supply is below 1e28
+const valueWidth = 40
+
+func formatValue(v abi.TokenAmount) string {
+	return fmt.Sprintf("%0*s", valueWidth, v.String())
+}
+
+// decodeChainMsg fills the decoded fields of the chain messages, the other records are left untouched
+func (s *sqliteSignRecord) decodeChainMsg() {
+	if s.Type != types.MTChainMsg || len(s.RawMsg) == 0 {
+		return
+	}
+	msg := &types.Message{}
+	if err := msg.UnmarshalCBOR(bytes.NewReader(s.RawMsg)); err != nil {
+		log.Warnf("decode message of record %s: %v", s.ID, err)
+		return
+	}
+	method, nonce := uint64(msg.Method), msg.Nonce
+	s.MsgTo = msg.To.String()
+	s.MsgValue = formatValue(msg.Value)
+	s.MsgMethod = &method
+	s.MsgNonce = &nonce
+}
+
+// decodeChainMsgs fills the decoded fields of the records written before the columns were added
+func decodeChainMsgs(db *gorm.DB) error {
+	var rows []*sqliteSignRecord
+	return db.Select("id", "type", "raw_msg").
+		Where("type = ? AND msg_to IS NULL AND raw_msg IS NOT NULL", types.MTChainMsg).
+		FindInBatches(&rows, iterateBatch, func(tx *gorm.DB, batch int) error {
+			for _, r := range rows {
+				r.decodeChainMsg()
+				if r.MsgTo == "" {
+					continue
+				}
+				err := tx.Model(&sqliteSignRecord{}).Where("id = ?", r.ID).Updates(map[string]interface{}{
+					"msg_to":     r.MsgTo,
+					"msg_value":  r.MsgValue,
+					"msg_method": r.MsgMethod,
+					"msg_nonce":  r.MsgNonce,
+				}).Error
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		}).Error
+}
+
+// msgFilter the conditions on the decoded fields of the chain messages
+func msgFilter(query *gorm.DB, params *storage.RecordQueryParams) *gorm.DB {
+	if !params.HasMsgFilter() {
+		return query
+	}
+	query = query.Where("type = ?", types.MTChainMsg)
+	if params.To != address.Undef {
+		query = query.Where("msg_to = ?", params.To.String())
+	}
+	if params.Method != nil {
+		query = query.Where("msg_method = ?", uint64(*params.Method))
+	}
+	if params.Nonce != nil {
+		query = query.Where("msg_nonce = ?", *params.Nonce)
+	}
+	if params.MinValue != nil {
+		query = query.Where("msg_value >= ?", formatValue(*params.MinValue))
+	}
+	if params.MaxValue != nil {
+		query = query.Where("msg_value <= ?", formatValue(*params.MaxValue))
+	}
+	if params.MsgCID != "" {
+		query = query.Where("msg_c_id = ?", params.MsgCID)
+	}
+	return query
+}
@@ -0,0 +1,88 @@
+package sqlite
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/venus/venus-shared/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/filecoin-project/venus-wallet/storage"
+)
+
+func TestChainMsgFilter(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file:TestChainMsgFilter?mode=memory&cache=shared"), &gorm.Config{})
+	require.NoError(t, err)
+	s, err := NewSqliteRecorder(db, nil)
+	require.NoError(t, err)
+
+	from, _ := address.NewIDAddress(1000)
+	to1, _ := address.NewIDAddress(1001)
+	to2, _ := address.NewIDAddress(1002)
+	now := time.Now()
+	record := func(id string, to address.Address, method abi.MethodNum, nonce uint64, value string) {
+		msg := &types.Message{From: from, To: to, Method: method, Nonce: nonce, Value: abi.TokenAmount(types.MustParseFIL(value))}
+		buf := new(bytes.Buffer)
+		require.NoError(t, msg.MarshalCBOR(buf))
+		require.NoError(t, s.Record(&storage.SignRecord{
+			ID: id, Type: types.MTChainMsg, Signer: from, RawMsg: buf.Bytes(), MsgCID: msg.Cid().String(), CreateAt: now,
+		}))
+	}
+	record("a", to1, 0, 1, "1")
+	record("b", to1, 5, 2, "10")
+	record("c", to2, 5, 3, "100")
+	record("d", to2, 5, 4, "0.5")
+	require.NoError(t, s.Record(&storage.SignRecord{ID: "block", Type: types.MTBlock, RawMsg: []byte("block"), CreateAt: now}))
+
+	ids := func(params *storage.RecordQueryParams) []string {
+		records, err := s.QueryRecord(params)
+		require.NoError(t, err)
+		var ret []string
+		for _, r := range records {
+			ret = append(ret, r.ID)
+		}
+		return ret
+	}
+	fil := func(v string) *abi.TokenAmount {
+		amount := abi.TokenAmount(types.MustParseFIL(v))
+		return &amount
+	}
+	method, nonce := abi.MethodNum(5), uint64(2)
+
+	assert.Len(t, ids(&storage.RecordQueryParams{}), 5)
+	assert.ElementsMatch(t, []string{"a", "b"}, ids(&storage.RecordQueryParams{To: to1}))
+	assert.ElementsMatch(t, []string{"c", "d"}, ids(&storage.RecordQueryParams{To: to2, Method: &method}))
+	assert.ElementsMatch(t, []string{"b"}, ids(&storage.RecordQueryParams{Nonce: &nonce}))
+	// values of different lengths compare as numbers
+	assert.ElementsMatch(t, []string{"a", "b"}, ids(&storage.RecordQueryParams{MinValue: fil("1"), MaxValue: fil("10")}))
+	assert.ElementsMatch(t, []string{"c"}, ids(&storage.RecordQueryParams{MinValue: fil("50")}))
+	assert.ElementsMatch(t, []string{"d"}, ids(&storage.RecordQueryParams{MaxValue: fil("0.9")}))
+
+	records, err := s.QueryRecord(&storage.RecordQueryParams{QueryParams: storage.QueryParams{ID: "c"}})
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	assert.ElementsMatch(t, []string{"c"}, ids(&storage.RecordQueryParams{MsgCID: records[0].MsgCID}))
+
+	// the records written before the columns are decoded at startup
+	require.NoError(t, db.Exec("UPDATE sign_record SET msg_to = NULL, msg_value = NULL, msg_method = NULL, msg_nonce = NULL").Error)
+	assert.Empty(t, ids(&storage.RecordQueryParams{To: to1}))
+	_, err = NewSqliteRecorder(db, nil)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"a", "b"}, ids(&storage.RecordQueryParams{To: to1}))
+
+	// the hashed columns still can't be updated
+	assert.Error(t, db.Exec("UPDATE sign_record SET raw_msg = ? WHERE id = ?", []byte("x"), "a").Error)
+}
+
+func TestFormatValue(t *testing.T) {
+	for _, v := range []string{"0", "1", "0.000000000000000001", "2000000000"} {
+		assert.Len(t, formatValue(abi.TokenAmount(types.MustParseFIL(v))), valueWidth, fmt.Sprintf("value %s", v))
+	}
+}
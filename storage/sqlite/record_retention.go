@@ -0,0 +1,382 @@
+package sqlite
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/venus/venus-shared/types"
+	wallet_types "github.com/filecoin-project/venus/venus-shared/types/wallet"
+	"gorm.io/gorm"
+
+	"github.com/filecoin-project/venus-wallet/config"
+	"github.com/filecoin-project/venus-wallet/storage"
+)
+
+const (
+	defaultKeepDuration = time.Hour * 7 * 24
+	// pruneBatch records archived and deleted in one transaction
+	pruneBatch = 1000
+)
+
+// retention how long the records are kept, and where they go when they expire
+type retention struct {
+	keep   time.Duration
+	byType map[types.MsgType]time.Duration
+	// archive the directory of the archive files, the records are only deleted if empty
+	archive string
+}
+
+func parseDuration(s string) (time.Duration, error) {
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, err
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("must be positive")
+	}
+	return d, nil
+}
+
+func parseRetention(cfg *config.SignRecorderConfig) (*retention, error) {
+	ret := &retention{keep: defaultKeepDuration, byType: map[types.MsgType]time.Duration{}}
+	if cfg == nil {
+		return ret, nil
+	}
+	var err error
+	if cfg.KeepDuration != "" {
+		if ret.keep, err = parseDuration(cfg.KeepDuration); err != nil {
+			return nil, fmt.Errorf("parse keep duration: %w", err)
+		}
+	}
+	for t, d := range cfg.KeepDurations {
+		msgType := types.MsgType(t)
+		if _, ok := wallet_types.SupportedMsgTypes[msgType]; !ok {
+			return nil, fmt.Errorf("keep duration of unsupported type %s", t)
+		}
+		if ret.byType[msgType], err = parseDuration(d); err != nil {
+			return nil, fmt.Errorf("parse keep duration of %s: %w", t, err)
+		}
+	}
+	if cfg.Archive != nil && cfg.Archive.Enable {
+		if cfg.Archive.Path == "" {
+			return nil, fmt.Errorf("archive path is empty")
+		}
+		ret.archive = cfg.Archive.Path
+	}
+	return ret, nil
+}
+
+// keepOf how long the records of the type are kept
+func (r *retention) keepOf(t types.MsgType) time.Duration {
+	if keep, ok := r.byType[t]; ok {
+		return keep
+	}
+	return r.keep
+}
+
+// expired the records past their retention
+func (r *retention) expired(db *gorm.DB, now time.Time) *gorm.DB {
+	typed := make([]string, 0, len(r.byType))
+	for t := range r.byType {
+		typed = append(typed, string(t))
+	}
+	sort.Strings(typed)
+
+	cond := db.Where("created_at < ?", now.Add(-r.keep))
+	if len(typed) > 0 {
+		cond = db.Where("type NOT IN ? AND created_at < ?", typed, now.Add(-r.keep))
+	}
+	for _, t := range typed {
+		cond = cond.Or("type = ? AND created_at < ?", t, now.Add(-r.byType[types.MsgType(t)]))
+	}
+	return db.Where(cond)
+}
+
+// prune archives and deletes the expired records. A prune checkpoint is kept for every deleted
+// record of the chain, so that the remaining records still chain to the deleted ones. The checkpoints
+// carry the time and type of the record, and are signed with the audit key if there is one
+func (s *SqliteRecorder) prune(now time.Time) error {
+	ret := s.retention.Load()
+	for {
+		n, err := s.pruneBatch(ret, now)
+		if err != nil {
+			return err
+		}
+		if n < pruneBatch {
+			break
+		}
+	}
+	return s.compactPrune()
+}
+
+func (s *SqliteRecorder) pruneBatch(ret *retention, now time.Time) (int, error) {
+	s.lk.Lock()
+	defer s.lk.Unlock()
+
+	var records []*sqliteSignRecord
+	err := ret.expired(s.db, now).Order("seq").Limit(pruneBatch).Find(&records).Error
+	if err != nil || len(records) == 0 {
+		return 0, err
+	}
+
+	ids := make([]string, 0, len(records))
+	var cps []*storage.RecordCheckpoint
+	for _, r := range records {
+		ids = append(ids, r.ID)
+		if r.Seq > 0 {
+			cps = append(cps, &storage.RecordCheckpoint{
+				Kind:       storage.CheckpointPrune,
+				Seq:        r.Seq,
+				Hash:       r.Hash,
+				CreateAt:   now.Truncate(storage.TimePrecision),
+				RecordAt:   r.CreatedAt,
+				RecordType: r.Type,
+				Keep:       ret.keepOf(r.Type),
+			})
+		}
+	}
+	if s.pruneSigner != nil && len(cps) > 0 {
+		if err := s.pruneSigner(cps); err != nil {
+			return 0, fmt.Errorf("sign prune checkpoints: %w", err)
+		}
+	}
+
+	var pending []string
+	if ret.archive != "" {
+		if err := recoverArchive(s.db, ret.archive); err != nil {
+			return 0, fmt.Errorf("recover archive: %w", err)
+		}
+		if pending, err = archiveRecords(ret.archive, records); err != nil {
+			removePending(pending)
+			return 0, fmt.Errorf("archive records: %w", err)
+		}
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		tombstones := make([]*sqliteRecordCheckpoint, 0, len(cps))
+		for _, cp := range cps {
+			tombstone := &sqliteRecordCheckpoint{
+				Kind:       cp.Kind,
+				Seq:        cp.Seq,
+				Hash:       cp.Hash,
+				CreatedAt:  cp.CreateAt,
+				RecordAt:   cp.RecordAt,
+				RecordType: cp.RecordType,
+				Keep:       cp.Keep,
+				Signature:  cp.Signature,
+			}
+			if cp.Signer != address.Undef {
+				tombstone.Signer = cp.Signer.String()
+			}
+			tombstones = append(tombstones, tombstone)
+		}
+		if len(tombstones) > 0 {
+			if err := tx.Create(tombstones).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Where("id IN ?", ids).Delete(&sqliteSignRecord{}).Error
+	})
+	if err != nil {
+		removePending(pending)
+		return 0, err
+	}
+	// the records are gone, an archive failing from here is finished by the next prune
+	for _, path := range pending {
+		if err := commitPending(path); err != nil {
+			return 0, fmt.Errorf("archive records: %w", err)
+		}
+	}
+	return len(records), nil
+}
+
+// compactPrune only the last prune checkpoint before the first remaining record is needed
+func (s *SqliteRecorder) compactPrune() error {
+	s.lk.Lock()
+	defer s.lk.Unlock()
+
+	var first sqliteSignRecord
+	err := s.db.Where("seq > 0").Order("seq").Limit(1).Find(&first).Error
+	if err != nil {
+		return err
+	}
+	query := s.db.Where("kind = ?", storage.CheckpointPrune)
+	if first.ID != "" {
+		query = query.Where("seq < ?", first.Seq)
+	}
+	var last sqliteRecordCheckpoint
+	if err := query.Order("seq desc").Limit(1).Find(&last).Error; err != nil || last.ID == 0 {
+		return err
+	}
+	return s.db.Where("kind = ? AND seq < ?", storage.CheckpointPrune, last.Seq).Delete(&sqliteRecordCheckpoint{}).Error
+}
+
+// pendingSuffix the records of a batch wait in a file next to the archive of their day until they are deleted.
+// The name holds the size of the archive before the batch, so that moving them in can be redone after a crash.
+const pendingSuffix = ".pending"
+
+// archiveRecords writes the records to the pending files of their days, which commitPending appends to the gzip
+// files of the days once the records are deleted. Every batch adds a gzip member, which the gzip tools and readers
+// read as a single stream.
+func archiveRecords(dir string, records []*sqliteSignRecord) ([]string, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	byDay := make(map[string][]*sqliteSignRecord)
+	var days []string
+	for _, r := range records {
+		day := r.CreatedAt.UTC().Format("2006-01-02")
+		if _, ok := byDay[day]; !ok {
+			days = append(days, day)
+		}
+		byDay[day] = append(byDay[day], r)
+	}
+	var pending []string
+	for _, day := range days {
+		path := filepath.Join(dir, "sign_record-"+day+".jsonl.gz")
+		var size int64
+		if info, err := os.Stat(path); err == nil {
+			size = info.Size()
+		} else if !os.IsNotExist(err) {
+			return pending, err
+		}
+		tmp := fmt.Sprintf("%s.%d%s", path, size, pendingSuffix)
+		pending = append(pending, tmp)
+		if err := writeArchive(tmp, byDay[day]); err != nil {
+			return pending, err
+		}
+	}
+	return pending, nil
+}
+
+// commitPending appends the pending file to the archive of its day, from the size the archive had before,
+// so that a half done append is overwritten
+func commitPending(pending string) error {
+	name := strings.TrimSuffix(pending, pendingSuffix)
+	dot := strings.LastIndexByte(name, '.')
+	if dot < 0 {
+		return fmt.Errorf("pending archive %s has no size", pending)
+	}
+	size, err := strconv.ParseInt(name[dot+1:], 10, 64)
+	if err != nil {
+		return fmt.Errorf("pending archive %s has no size: %w", pending, err)
+	}
+	src, err := os.Open(pending)
+	if err != nil {
+		return err
+	}
+	defer src.Close() //nolint:errcheck
+
+	f, err := os.OpenFile(name[:dot], os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	defer f.Close() //nolint:errcheck
+	if err := f.Truncate(size); err != nil {
+		return err
+	}
+	if _, err := f.Seek(size, io.SeekStart); err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, src); err != nil {
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Remove(pending)
+}
+
+// recoverArchive finishes the batches interrupted after their records were deleted,
+// and drops the pending files of the batches whose records are still there
+func recoverArchive(db *gorm.DB, dir string) error {
+	paths, err := filepath.Glob(filepath.Join(dir, "*"+pendingSuffix))
+	if err != nil {
+		return err
+	}
+	for _, path := range paths {
+		id, err := firstArchived(path)
+		if err != nil {
+			// the pending file is synced before the records are deleted, an unreadable one was never used
+			log.Warnf("drop pending archive %s: %v", path, err)
+			if err := os.Remove(path); err != nil {
+				return err
+			}
+			continue
+		}
+		var count int64
+		if err := db.Model(&sqliteSignRecord{}).Where("id = ?", id).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			err = os.Remove(path)
+		} else {
+			err = commitPending(path)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// firstArchived the id of the first record of the archive file
+func firstArchived(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close() //nolint:errcheck
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		return "", err
+	}
+	var record storage.SignRecord
+	if err := json.NewDecoder(zr).Decode(&record); err != nil {
+		return "", err
+	}
+	return record.ID, nil
+}
+
+func removePending(pending []string) {
+	for _, path := range pending {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			log.Warnf("remove pending archive %s: %v", path, err)
+		}
+	}
+}
+
+func writeArchive(path string, records []*sqliteSignRecord) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	defer f.Close() //nolint:errcheck
+
+	zw := gzip.NewWriter(f)
+	enc := json.NewEncoder(zw)
+	for _, r := range records {
+		if err := enc.Encode(r.toSignRecord()); err != nil {
+			return err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,182 @@
+package sqlite
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/venus/venus-shared/types"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/filecoin-project/venus-wallet/config"
+	"github.com/filecoin-project/venus-wallet/storage"
+)
+
+func TestParseRetention(t *testing.T) {
+	ret, err := parseRetention(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, defaultKeepDuration, ret.keep)
+	assert.Empty(t, ret.archive)
+
+	ret, err = parseRetention(&config.SignRecorderConfig{
+		KeepDuration:  "24h",
+		KeepDurations: map[string]string{string(types.MTBlock): "87600h"},
+		Archive:       &config.RecordArchiveConfig{Enable: true, Path: "/tmp/archive"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 24*time.Hour, ret.keep)
+	assert.Equal(t, 87600*time.Hour, ret.byType[types.MTBlock])
+	assert.Equal(t, "/tmp/archive", ret.archive)
+
+	_, err = parseRetention(&config.SignRecorderConfig{KeepDurations: map[string]string{"foo": "1h"}})
+	assert.Error(t, err)
+	_, err = parseRetention(&config.SignRecorderConfig{KeepDurations: map[string]string{string(types.MTBlock): "-1h"}})
+	assert.Error(t, err)
+	_, err = parseRetention(&config.SignRecorderConfig{Archive: &config.RecordArchiveConfig{Enable: true}})
+	assert.Error(t, err)
+}
+
+func TestRecordRetention(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(fmt.Sprintf("file:%s-%s?mode=memory&cache=shared", t.Name(), uuid.New())), &gorm.Config{})
+	assert.NoError(t, err)
+	dir := t.TempDir()
+	r, err := NewSqliteRecorder(db, &config.SignRecorderConfig{
+		Enable:        true,
+		KeepDuration:  "48h",
+		KeepDurations: map[string]string{string(types.MTBlock): "87600h", string(types.MTProviderDealState): "1h"},
+		Archive:       &config.RecordArchiveConfig{Enable: true, Path: dir},
+	})
+	assert.NoError(t, err)
+	recorder := r.(*SqliteRecorder)
+
+	signer, err := address.NewIDAddress(1000)
+	assert.NoError(t, err)
+	old := time.Now().Add(-24 * time.Hour)
+	msgTypes := []types.MsgType{types.MTBlock, types.MTProviderDealState, types.MTChainMsg, types.MTProviderDealState, types.MTBlock}
+	for _, msgType := range msgTypes {
+		assert.NoError(t, recorder.Record(&storage.SignRecord{ID: uuid.New().String(), Type: msgType, Signer: signer, CreateAt: old}))
+	}
+
+	assert.NoError(t, recorder.prune(time.Now()))
+	records, err := recorder.QueryRecord(&storage.RecordQueryParams{})
+	assert.NoError(t, err)
+	assert.Len(t, records, 3)
+	for _, r := range records {
+		assert.NotEqual(t, types.MTProviderDealState, r.Type)
+	}
+
+	// the chain is intact with the pruned records in the middle
+	res, err := recorder.VerifyChain(address.Undef)
+	assert.NoError(t, err)
+	assert.Empty(t, res.Problems)
+	assert.Equal(t, 3, res.Records)
+	assert.Equal(t, 2, res.Pruned)
+
+	// pruned twice, the archive file has two gzip members
+	assert.NoError(t, recorder.Record(&storage.SignRecord{ID: uuid.New().String(), Type: types.MTProviderDealState, Signer: signer, CreateAt: old}))
+	assert.NoError(t, recorder.prune(time.Now()))
+
+	archived := readArchive(t, filepath.Join(dir, "sign_record-"+old.UTC().Format("2006-01-02")+".jsonl.gz"))
+	assert.Len(t, archived, 3)
+	for _, r := range archived {
+		assert.Equal(t, types.MTProviderDealState, r.Type)
+		assert.Equal(t, r.Hash, r.ChainHash())
+	}
+
+	res, err = recorder.VerifyChain(address.Undef)
+	assert.NoError(t, err)
+	assert.Empty(t, res.Problems)
+}
+
+func readArchive(t *testing.T, path string) []storage.SignRecord {
+	f, err := os.Open(path)
+	assert.NoError(t, err)
+	defer f.Close() //nolint:errcheck
+	zr, err := gzip.NewReader(f)
+	assert.NoError(t, err)
+	var archived []storage.SignRecord
+	scanner := bufio.NewScanner(zr)
+	for scanner.Scan() {
+		var r storage.SignRecord
+		assert.NoError(t, json.Unmarshal(scanner.Bytes(), &r))
+		archived = append(archived, r)
+	}
+	assert.NoError(t, scanner.Err())
+	return archived
+}
+
+func TestRecordRetention_ArchiveRetry(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(fmt.Sprintf("file:%s-%s?mode=memory&cache=shared", t.Name(), uuid.New())), &gorm.Config{})
+	assert.NoError(t, err)
+	dir := t.TempDir()
+	r, err := NewSqliteRecorder(db, &config.SignRecorderConfig{
+		Enable:       true,
+		KeepDuration: "1h",
+		Archive:      &config.RecordArchiveConfig{Enable: true, Path: dir},
+	})
+	assert.NoError(t, err)
+	recorder := r.(*SqliteRecorder)
+
+	signer, err := address.NewIDAddress(1000)
+	assert.NoError(t, err)
+	old := time.Now().Add(-24 * time.Hour)
+	record := func() {
+		assert.NoError(t, recorder.Record(&storage.SignRecord{ID: uuid.New().String(), Type: types.MTChainMsg, Signer: signer, CreateAt: old}))
+	}
+	path := filepath.Join(dir, "sign_record-"+old.UTC().Format("2006-01-02")+".jsonl.gz")
+	pending := func() []string {
+		paths, err := filepath.Glob(filepath.Join(dir, "*"+pendingSuffix))
+		assert.NoError(t, err)
+		return paths
+	}
+
+	// the records which couldn't be deleted aren't archived
+	record()
+	record()
+	assert.NoError(t, db.Exec(`CREATE TRIGGER sign_record_no_delete BEFORE DELETE ON sign_record
+BEGIN SELECT RAISE(ABORT, 'no delete'); END`).Error)
+	assert.Error(t, recorder.prune(time.Now()))
+	assert.Empty(t, pending())
+	assert.NoError(t, db.Exec(`DROP TRIGGER sign_record_no_delete`).Error)
+	assert.NoError(t, recorder.prune(time.Now()))
+	assert.Len(t, readArchive(t, path), 2)
+	assert.Empty(t, pending())
+
+	// a crash once the records are deleted, halfway through the append
+	record()
+	var rows []*sqliteSignRecord
+	assert.NoError(t, db.Find(&rows).Error)
+	moved, err := archiveRecords(dir, rows)
+	assert.NoError(t, err)
+	assert.NoError(t, db.Where("1 = 1").Delete(&sqliteSignRecord{}).Error)
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o600)
+	assert.NoError(t, err)
+	_, err = f.Write([]byte("half"))
+	assert.NoError(t, err)
+	assert.NoError(t, f.Close())
+	// and a crash before the records of another batch were deleted
+	record()
+	assert.NoError(t, db.Find(&rows).Error)
+	dropped, err := archiveRecords(dir, rows)
+	assert.NoError(t, err)
+	assert.NotEqual(t, moved, dropped)
+
+	assert.NoError(t, recorder.prune(time.Now()))
+	assert.Empty(t, pending())
+	archived := readArchive(t, path)
+	assert.Len(t, archived, 4)
+	ids := map[string]struct{}{}
+	for _, r := range archived {
+		ids[r.ID] = struct{}{}
+	}
+	assert.Len(t, ids, 4)
+}
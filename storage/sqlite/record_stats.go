@@ -0,0 +1,141 @@
+package sqlite
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/filecoin-project/venus/venus-shared/types"
+	"gorm.io/gorm"
+
+	"github.com/filecoin-project/venus-wallet/storage"
+)
+
+const defaultTopErrors = 20
+
+// bucketFormats strftime formats truncating the time to the bucket, in UTC, mysql DATE_FORMAT takes the same
+var bucketFormats = map[storage.StatsBucket]string{
+	storage.BucketHour: "%Y-%m-%d %H:00:00",
+	storage.BucketDay:  "%Y-%m-%d 00:00:00",
+}
+
+type groupCount struct {
+	// key is reserved in mysql
+	Key    string `gorm:"column:group_key"`
+	Count  int64
+	Errors int64
+}
+
+const countColumns = "count(*) AS count, sum(CASE WHEN err IS NOT NULL THEN 1 ELSE 0 END) AS errors"
+
+func (s *SqliteRecorder) RecordStats(params *storage.RecordStatsParams) (*storage.RecordStats, error) {
+	bucketFormat, ok := bucketFormats[params.Bucket]
+	if params.Bucket != storage.BucketNone && !ok {
+		return nil, fmt.Errorf("unsupported bucket %s", params.Bucket)
+	}
+	topErrors := params.TopErrors
+	if topErrors <= 0 {
+		topErrors = defaultTopErrors
+	}
+	query := func() *gorm.DB {
+		return filter(s.db.Model(&sqliteSignRecord{}), &storage.QueryParams{
+			Signer: params.Signer,
+			Type:   params.Type,
+			After:  params.After,
+			Before: params.Before,
+		})
+	}
+	groupBy := func(column string, q *gorm.DB) ([]storage.RecordCount, error) {
+		var rows []groupCount
+		err := q.Select(column + " AS group_key, " + countColumns).Group(column).Order("count DESC").Scan(&rows).Error
+		if err != nil {
+			return nil, err
+		}
+		ret := make([]storage.RecordCount, 0, len(rows))
+		for _, r := range rows {
+			ret = append(ret, storage.RecordCount(r))
+		}
+		return ret, nil
+	}
+
+	stats := &storage.RecordStats{}
+	var total groupCount
+	if err := query().Select(countColumns).Scan(&total).Error; err != nil {
+		return nil, err
+	}
+	stats.Total, stats.Errors = total.Count, total.Errors
+
+	var err error
+	if stats.BySigner, err = groupBy("signer", query()); err != nil {
+		return nil, err
+	}
+	if stats.ByType, err = groupBy("type", query()); err != nil {
+		return nil, err
+	}
+	if stats.ByError, err = groupBy("err", query().Where("err IS NOT NULL").Limit(topErrors)); err != nil {
+		return nil, err
+	}
+
+	if bucketFormat != "" {
+		bucket := fmt.Sprintf("strftime('%s', created_at)", bucketFormat)
+		if isMySQL(s.db) {
+			bucket = fmt.Sprintf("DATE_FORMAT(created_at, '%s')", bucketFormat)
+		}
+		buckets, err := groupBy(bucket, query())
+		if err != nil {
+			return nil, err
+		}
+		for _, b := range buckets {
+			start, err := time.ParseInLocation(time.DateTime, b.Key, time.UTC)
+			if err != nil {
+				return nil, fmt.Errorf("parse bucket %s: %w", b.Key, err)
+			}
+			stats.Buckets = append(stats.Buckets, storage.RecordBucket{Start: start, Count: b.Count, Errors: b.Errors})
+		}
+		sort.Slice(stats.Buckets, func(i, j int) bool { return stats.Buckets[i].Start.Before(stats.Buckets[j].Start) })
+	}
+
+	if params.Type == MTUndefined || params.Type == types.MTChainMsg {
+		if stats.ChainMsg, err = s.chainMsgSums(query()); err != nil {
+			return nil, err
+		}
+	}
+	return stats, nil
+}
+
+// chainMsgSums sums the value and fee of the chain messages signed successfully, per signer
+func (s *SqliteRecorder) chainMsgSums(query *gorm.DB) ([]storage.ChainMsgSums, error) {
+	sums := make(map[string]*storage.ChainMsgSums)
+	var rows []*sqliteSignRecord
+	err := query.Select("id", "signer", "raw_msg").Where("type = ? AND err IS NULL", types.MTChainMsg).
+		FindInBatches(&rows, iterateBatch, func(tx *gorm.DB, batch int) error {
+			for _, r := range rows {
+				msg := &types.Message{}
+				if err := msg.UnmarshalCBOR(bytes.NewReader(r.RawMsg)); err != nil {
+					log.Warnf("decode message of record %s: %v", r.ID, err)
+					continue
+				}
+				sum, ok := sums[r.Signer]
+				if !ok {
+					sum = &storage.ChainMsgSums{Signer: MustParseAddress(r.Signer), Value: big.Zero(), MaxFee: big.Zero()}
+					sums[r.Signer] = sum
+				}
+				sum.Count++
+				sum.Value = big.Add(sum.Value, msg.Value)
+				sum.MaxFee = big.Add(sum.MaxFee, big.Mul(msg.GasFeeCap, big.NewInt(msg.GasLimit)))
+			}
+			return nil
+		}).Error
+	if err != nil {
+		return nil, err
+	}
+
+	ret := make([]storage.ChainMsgSums, 0, len(sums))
+	for _, sum := range sums {
+		ret = append(ret, *sum)
+	}
+	sort.Slice(ret, func(i, j int) bool { return ret[i].Value.GreaterThan(ret[j].Value) })
+	return ret, nil
+}
@@ -0,0 +1,77 @@
+package sqlite
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/venus/venus-shared/types"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/filecoin-project/venus-wallet/storage"
+)
+
+func TestRecordStats(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(fmt.Sprintf("file:%s-%s?mode=memory&cache=shared", t.Name(), uuid.New())), &gorm.Config{})
+	assert.NoError(t, err)
+	r, err := NewSqliteRecorder(db, nil)
+	assert.NoError(t, err)
+	recorder := r.(*SqliteRecorder)
+
+	a1, err := address.NewIDAddress(1001)
+	assert.NoError(t, err)
+	a2, err := address.NewIDAddress(1002)
+	assert.NoError(t, err)
+
+	day := time.Date(2026, 10, 1, 10, 20, 0, 123456789, time.UTC)
+	record := func(signer address.Address, at time.Time, msgType types.MsgType, raw []byte, signErr string) {
+		assert.NoError(t, recorder.Record(&storage.SignRecord{
+			ID: uuid.New().String(), Type: msgType, Signer: signer, CreateAt: at, RawMsg: raw, Err: signErr,
+		}))
+	}
+	chainMsg := func(value, feeCap int64, gasLimit int64) []byte {
+		msg := &types.Message{From: a1, To: a2, Value: abi.NewTokenAmount(value), GasFeeCap: abi.NewTokenAmount(feeCap), GasLimit: gasLimit, GasPremium: abi.NewTokenAmount(0)}
+		raw, err := msg.Serialize()
+		assert.NoError(t, err)
+		return raw
+	}
+
+	record(a1, day, types.MTChainMsg, chainMsg(100, 2, 10), "")
+	record(a1, day.Add(10*time.Minute), types.MTChainMsg, chainMsg(50, 1, 10), "")
+	record(a1, day.Add(20*time.Minute), types.MTChainMsg, chainMsg(1000, 1, 10), "rejected by filter")
+	record(a2, day.Add(time.Hour), types.MTBlock, nil, "")
+	record(a2, day.Add(25*time.Hour), types.MTBlock, nil, "rejected by filter")
+
+	stats, err := recorder.RecordStats(&storage.RecordStatsParams{Bucket: storage.BucketHour})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(5), stats.Total)
+	assert.Equal(t, int64(2), stats.Errors)
+	assert.Equal(t, []storage.RecordCount{{Key: a1.String(), Count: 3, Errors: 1}, {Key: a2.String(), Count: 2, Errors: 1}}, stats.BySigner)
+	assert.Equal(t, []storage.RecordCount{{Key: "rejected by filter", Count: 2, Errors: 2}}, stats.ByError)
+	assert.Equal(t, []storage.RecordBucket{
+		{Start: day.Truncate(time.Hour), Count: 3, Errors: 1},
+		{Start: day.Truncate(time.Hour).Add(time.Hour), Count: 1},
+		{Start: day.Truncate(time.Hour).Add(25 * time.Hour), Count: 1, Errors: 1},
+	}, stats.Buckets)
+
+	// the rejected message isn't counted in the sums
+	assert.Len(t, stats.ChainMsg, 1)
+	assert.Equal(t, int64(2), stats.ChainMsg[0].Count)
+	assert.Equal(t, abi.NewTokenAmount(150), stats.ChainMsg[0].Value)
+	assert.Equal(t, abi.NewTokenAmount(30), stats.ChainMsg[0].MaxFee)
+
+	stats, err = recorder.RecordStats(&storage.RecordStatsParams{Type: types.MTBlock, Bucket: storage.BucketDay})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), stats.Total)
+	assert.Len(t, stats.Buckets, 2)
+	assert.Equal(t, day.Truncate(24*time.Hour), stats.Buckets[0].Start)
+	assert.Empty(t, stats.ChainMsg)
+
+	_, err = recorder.RecordStats(&storage.RecordStatsParams{Bucket: "week"})
+	assert.Error(t, err)
+}
@@ -1,13 +1,15 @@
 package sqlite
 
 import (
-	"errors"
 	"fmt"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/filecoin-project/go-address"
 	"github.com/filecoin-project/go-state-types/crypto"
 	"github.com/filecoin-project/venus-wallet/config"
+	"github.com/filecoin-project/venus-wallet/middleware"
 	"github.com/filecoin-project/venus-wallet/storage"
 	"github.com/filecoin-project/venus/venus-shared/types"
 	logging "github.com/ipfs/go-log/v2"
@@ -18,20 +20,41 @@ const MTUndefined types.MsgType = ""
 
 var log = logging.Logger("recorder")
 
+// iterateBatch records read at once by IterateRecord
+const iterateBatch = 500
+
 type sqliteSignRecord struct {
 	ID        string    `gorm:"primaryKey;type:varchar(256);not null"`
 	CreatedAt time.Time `gorm:"index"`
 	Type      types.MsgType
-	Signer    string            `gorm:"type:varchar(256);index;not null"`
-	Err       string            `gorm:"type:varchar(256);default:null"`
-	RawMsg    []byte            `gorm:"type:blob;default:null"`
-	Signature *crypto.Signature `gorm:"embedded;embeddedPrefix:signature_"`
+	Signer    string             `gorm:"type:varchar(256);index;not null"`
+	Err       string             `gorm:"type:varchar(256);default:null"`
+	RawMsg    []byte             `gorm:"type:blob;default:null"`
+	Signature *crypto.Signature  `gorm:"embedded;embeddedPrefix:signature_"`
+	Caller    *middleware.Caller `gorm:"serializer:json;default:null"`
+	MsgCID    string             `gorm:"type:varchar(256);index;default:null"`
+	Extra     []byte             `gorm:"type:blob;default:null"`
+	ToSign    []byte             `gorm:"type:blob;default:null"`
+	Duration  time.Duration
+	Summary   string `gorm:"type:varchar(1024);default:null"`
+	Seq       uint64 `gorm:"index"`
+	PrevHash  []byte `gorm:"type:blob;default:null"`
+	Hash      []byte `gorm:"type:blob;default:null"`
+	// the fields decoded from the chain messages, not covered by the hash as they are derived from RawMsg
+	MsgTo     string  `gorm:"type:varchar(256);index;default:null"`
+	MsgValue  string  `gorm:"type:varchar(40);index;default:null"`
+	MsgMethod *uint64 `gorm:"index;default:null"`
+	MsgNonce  *uint64 `gorm:"index;default:null"`
 }
 
 func (s *sqliteSignRecord) TableName() string {
 	return "sign_record"
 }
 
+func (s *sqliteSignRecord) hashedTimes() []time.Time {
+	return []time.Time{s.CreatedAt}
+}
+
 func newFromSignRecord(record *storage.SignRecord) *sqliteSignRecord {
 	ret := &sqliteSignRecord{
 		ID:        record.ID,
@@ -39,10 +62,17 @@ func newFromSignRecord(record *storage.SignRecord) *sqliteSignRecord {
 		Type:      record.Type,
 		Signer:    record.Signer.String(),
 		RawMsg:    record.RawMsg,
+		Err:       record.Err,
 		Signature: record.Signature,
-	}
-	if record.Err != nil {
-		ret.Err = record.Err.Error()
+		Caller:    record.Caller,
+		MsgCID:    record.MsgCID,
+		Extra:     record.Extra,
+		ToSign:    record.ToSign,
+		Duration:  record.Duration,
+		Summary:   record.Summary,
+		Seq:       record.Seq,
+		PrevHash:  record.PrevHash,
+		Hash:      record.Hash,
 	}
 	return ret
 }
@@ -53,82 +83,163 @@ func (s *sqliteSignRecord) toSignRecord() *storage.SignRecord {
 		CreateAt:  s.CreatedAt,
 		Type:      s.Type,
 		Signer:    MustParseAddress(s.Signer),
-		Err:       errors.New(s.Err),
+		Err:       s.Err,
 		RawMsg:    s.RawMsg,
 		Signature: s.Signature,
+		Caller:    s.Caller,
+		MsgCID:    s.MsgCID,
+		Extra:     s.Extra,
+		ToSign:    s.ToSign,
+		Duration:  s.Duration,
+		Summary:   s.Summary,
+		Seq:       s.Seq,
+		PrevHash:  s.PrevHash,
+		Hash:      s.Hash,
 	}
-	if s.Err == "" {
-		ret.Err = nil
+	// the embedded columns of a missing signature read back as an empty signature
+	if ret.Signature != nil && ret.Signature.Data == nil {
+		ret.Signature = nil
 	}
 	return ret
 }
 
 type SqliteRecorder struct {
-	db *gorm.DB
+	db        *gorm.DB
+	retention atomic.Pointer[retention]
+	// lk serializes the appends to the chain
+	lk sync.Mutex
+	// pruneSigner signs the prune checkpoints, guarded by lk
+	pruneSigner storage.PruneSigner
 }
 
 func NewSqliteRecorder(db *gorm.DB, cfg *config.SignRecorderConfig) (storage.IRecorder, error) {
-	enable, keepDuration := true, time.Hour*7*24
+	enable := true
 	if cfg != nil {
 		enable = cfg.Enable
-		if cfg.KeepDuration != "" {
-			d, err := time.ParseDuration(cfg.KeepDuration)
-			if err != nil {
-				return nil, fmt.Errorf("init sqlite_recorder: %w", err)
-			}
-			keepDuration = d
-		}
+	}
+	ret, err := parseRetention(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("init sqlite_recorder: %w", err)
 	}
 
 	if !enable {
-		return &RecorderStub{}, nil
+		recorder, err := newAdminRecorder(db)
+		if err != nil {
+			return nil, fmt.Errorf("init sqlite_recorder: %w", err)
+		}
+		return recorder, nil
 	}
 
-	err := db.AutoMigrate(&sqliteSignRecord{})
+	err = db.AutoMigrate(&sqliteSignRecord{}, &sqliteRecordCheckpoint{})
 	if err != nil {
 		return nil, fmt.Errorf("init sqlite_recorder: %w", err)
 	}
+	// records are never updated, only the retention deletes them, the decoded fields can be filled later
+	err = db.Transaction(func(tx *gorm.DB) error {
+		return createAbortTrigger(tx, "sign_record_append_only", "UPDATE", "sign_record", hashedColumns)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("init sqlite_recorder: %w", err)
+	}
+	if err := decodeChainMsgs(db); err != nil {
+		return nil, fmt.Errorf("init sqlite_recorder: %w", err)
+	}
+	if err := initAdminRecord(db); err != nil {
+		return nil, fmt.Errorf("init sqlite_recorder: %w", err)
+	}
+
+	recorder := &SqliteRecorder{db: db}
+	recorder.retention.Store(ret)
 
 	go func() {
 		ticker := time.NewTicker(time.Hour)
 		for {
 			<-ticker.C
-			err := db.Where("created_at < ?", time.Now().Add(-keepDuration)).Delete(&sqliteSignRecord{}).Error
-			if err != nil {
+			if err := recorder.prune(time.Now()); err != nil {
 				log.Errorf("clean sqlite recorder: %s", err)
 			}
 		}
 	}()
 
-	return &SqliteRecorder{db: db}, nil
+	return recorder, nil
+}
+
+// Reload validates the new config, the returned function applies it.
+// Only the retention and the archive can be changed at runtime, enabling or disabling the recorder needs a restart.
+func (s *SqliteRecorder) Reload(cfg *config.SignRecorderConfig) (func(), error) {
+	ret, err := parseRetention(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return func() { s.retention.Store(ret) }, nil
 }
 
 func (s *SqliteRecorder) Record(record *storage.SignRecord) error {
-	return s.db.Create(newFromSignRecord(record)).Error
+	return s.RecordBatch([]*storage.SignRecord{record})
+}
+
+func (s *SqliteRecorder) RecordBatch(records []*storage.SignRecord) error {
+	s.lk.Lock()
+	defer s.lk.Unlock()
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		head, err := chainHead(tx)
+		if err != nil {
+			return err
+		}
+		seq, prevHash := uint64(0), []byte(nil)
+		if head != nil {
+			seq, prevHash = head.Seq, head.Hash
+		}
+		for _, record := range records {
+			seq++
+			record.Seq = seq
+			record.PrevHash = prevHash
+			if record.CreateAt.IsZero() {
+				record.CreateAt = time.Now()
+			}
+			record.CreateAt = record.CreateAt.Truncate(storage.TimePrecision)
+			record.Hash = record.ChainHash()
+			prevHash = record.Hash
+
+			row := newFromSignRecord(record)
+			row.decodeChainMsg()
+			if err := tx.Create(row).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// filter the conditions of the params, except the paging ones
+func filter(query *gorm.DB, params *storage.QueryParams) *gorm.DB {
+	if params.Signer != address.Undef {
+		query = query.Where("signer = ?", params.Signer.String())
+	}
+	if !params.After.IsZero() {
+		query = query.Where("created_at >= ?", params.After)
+	}
+	if !params.Before.IsZero() {
+		query = query.Where("created_at <= ?", params.Before)
+	}
+	if params.IsError {
+		query = query.Where("err is not null")
+	}
+	if params.Type != MTUndefined {
+		query = query.Where("type = ?", params.Type)
+	}
+	return query
 }
 
-func (s *SqliteRecorder) QueryRecord(params *storage.QueryParams) ([]storage.SignRecord, error) {
+func (s *SqliteRecorder) QueryRecord(params *storage.RecordQueryParams) ([]storage.SignRecord, error) {
 	var records []*sqliteSignRecord
 	query := s.db
 
 	if params.ID != "" {
 		query = query.Where("id = ?", params.ID)
 	} else {
-		if params.Signer != address.Undef {
-			query = query.Where("signer = ?", params.Signer.String())
-		}
-		if !params.After.IsZero() {
-			query = query.Where("created_at >= ?", params.After)
-		}
-		if !params.Before.IsZero() {
-			query = query.Where("created_at <= ?", params.Before)
-		}
-		if params.IsError {
-			query = query.Where("err is not null")
-		}
-		if params.Type != MTUndefined {
-			query = query.Where("type = ?", params.Type)
-		}
+		query = msgFilter(filter(query, &params.QueryParams), params)
 		if params.Skip > 0 {
 			query = query.Offset(params.Skip)
 		}
@@ -149,6 +260,44 @@ func (s *SqliteRecorder) QueryRecord(params *storage.QueryParams) ([]storage.Sig
 	return ret, nil
 }
 
+func (s *SqliteRecorder) IterateRecord(params *storage.RecordQueryParams, fn func(record *storage.SignRecord) error) error {
+	var (
+		lastTime time.Time
+		lastID   string
+		count    int
+	)
+	for {
+		size := iterateBatch
+		if params.Limit > 0 && params.Limit-count < size {
+			size = params.Limit - count
+		}
+		if size <= 0 {
+			return nil
+		}
+
+		// keyset paging, the offset of the later pages would scan all the rows before
+		query := msgFilter(filter(s.db, &params.QueryParams), params)
+		if lastID != "" {
+			query = query.Where("created_at > ? OR (created_at = ? AND id > ?)", lastTime, lastTime, lastID)
+		}
+		var records []*sqliteSignRecord
+		if err := query.Order("created_at, id").Limit(size).Find(&records).Error; err != nil {
+			return err
+		}
+		for _, r := range records {
+			if err := fn(r.toSignRecord()); err != nil {
+				return err
+			}
+		}
+		count += len(records)
+		if len(records) < size {
+			return nil
+		}
+		last := records[len(records)-1]
+		lastTime, lastID = last.CreatedAt, last.ID
+	}
+}
+
 func MustParseAddress(addr string) address.Address {
 	a, err := address.NewFromString(addr)
 	if err != nil {
@@ -164,6 +313,32 @@ func (r *RecorderStub) Record(record *storage.SignRecord) error {
 	return nil
 }
 
-func (r *RecorderStub) QueryRecord(params *storage.QueryParams) ([]storage.SignRecord, error) {
+func (r *RecorderStub) RecordBatch(records []*storage.SignRecord) error {
+	return nil
+}
+
+func (r *RecorderStub) QueryRecord(params *storage.RecordQueryParams) ([]storage.SignRecord, error) {
+	return nil, nil
+}
+
+func (r *RecorderStub) IterateRecord(params *storage.RecordQueryParams, fn func(record *storage.SignRecord) error) error {
+	return nil
+}
+
+func (r *RecorderStub) RecordStats(params *storage.RecordStatsParams) (*storage.RecordStats, error) {
+	return &storage.RecordStats{}, nil
+}
+
+func (r *RecorderStub) ChainHead() (*storage.RecordCheckpoint, error) {
 	return nil, nil
 }
+
+func (r *RecorderStub) PutCheckpoint(cp *storage.RecordCheckpoint) error {
+	return nil
+}
+
+func (r *RecorderStub) VerifyChain(auditKey address.Address) (*storage.RecordVerifyResult, error) {
+	return &storage.RecordVerifyResult{}, nil
+}
+
+func (r *RecorderStub) SetPruneSigner(signer storage.PruneSigner) {}
@@ -1,14 +1,19 @@
 package sqlite
 
 import (
+	"errors"
 	"fmt"
 	"testing"
 	"time"
 
+	"github.com/filecoin-project/go-state-types/crypto"
 	"github.com/filecoin-project/venus/venus-shared/types"
 	"github.com/stretchr/testify/assert"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
+
+	"github.com/filecoin-project/venus-wallet/middleware"
+	"github.com/filecoin-project/venus-wallet/storage"
 )
 
 func TestSingRecord(t *testing.T) {
@@ -19,15 +24,95 @@ func TestSingRecord(t *testing.T) {
 	s, err := NewSqliteRecorder(db, nil)
 	assert.NoError(t, err)
 
-	err = s.Record(&types.SignRecord{
+	caller := &middleware.Caller{TokenID: "id", Name: "messager", Perms: []string{"read", "sign"}, RemoteAddr: "127.0.0.1:5000"}
+	err = s.Record(&storage.SignRecord{
 		RawMsg:   []byte("hello"),
-		Err:      fmt.Errorf("error"),
+		Err:      "error",
 		Type:     types.MTVerifyAddress,
 		CreateAt: time.Now(),
+		Caller:   caller,
 	})
 	assert.NoError(t, err)
-	res, err := s.QueryRecord(&types.QuerySignRecordParams{})
+	res, err := s.QueryRecord(&storage.RecordQueryParams{})
 	assert.NoError(t, err)
 	assert.Equal(t, 1, len(res))
+	assert.Equal(t, caller, res[0].Caller)
+	assert.Equal(t, "error", res[0].ToShared().Err.Error())
+
+}
+
+func TestSignRecordFields(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file:TestSignRecordFields?mode=memory&cache=shared"), &gorm.Config{})
+	assert.NoError(t, err)
+	s, err := NewSqliteRecorder(db, nil)
+	assert.NoError(t, err)
+
+	record := &storage.SignRecord{
+		ID:        "fields",
+		Type:      types.MTChainMsg,
+		RawMsg:    []byte("raw"),
+		Signature: &crypto.Signature{Type: crypto.SigTypeSecp256k1, Data: []byte("sig")},
+		CreateAt:  time.Now(),
+		MsgCID:    "bafy2bzace",
+		Extra:     []byte("extra"),
+		ToSign:    []byte("to sign"),
+		Duration:  1500 * time.Millisecond,
+		Summary:   "to f01000 value 1 FIL",
+	}
+	assert.NoError(t, s.Record(record))
+
+	res, err := s.QueryRecord(&storage.RecordQueryParams{QueryParams: storage.QueryParams{ID: "fields"}})
+	assert.NoError(t, err)
+	assert.Len(t, res, 1)
+	assert.Equal(t, record.Signature, res[0].Signature)
+	assert.Equal(t, record.MsgCID, res[0].MsgCID)
+	assert.Equal(t, record.Extra, res[0].Extra)
+	assert.Equal(t, record.ToSign, res[0].ToSign)
+	assert.Equal(t, record.Duration, res[0].Duration)
+	assert.Equal(t, record.Summary, res[0].Summary)
+}
+
+func TestIterateRecord(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file:TestIterateRecord?mode=memory&cache=shared"), &gorm.Config{})
+	assert.NoError(t, err)
+	s, err := NewSqliteRecorder(db, nil)
+	assert.NoError(t, err)
+
+	// more than a page, with several records sharing the time
+	now := time.Now()
+	total := iterateBatch*2 + 10
+	for i := 0; i < total; i++ {
+		msgType := types.MTChainMsg
+		if i%2 == 1 {
+			msgType = types.MTBlock
+		}
+		assert.NoError(t, s.Record(&storage.SignRecord{
+			ID:       fmt.Sprintf("iterate-%04d", i),
+			Type:     msgType,
+			CreateAt: now.Add(time.Duration(i/3) * time.Millisecond),
+		}))
+	}
+
+	var ids []string
+	err = s.IterateRecord(&storage.RecordQueryParams{}, func(r *storage.SignRecord) error {
+		ids = append(ids, r.ID)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Len(t, ids, total)
+	for i, id := range ids {
+		assert.Equal(t, fmt.Sprintf("iterate-%04d", i), id)
+	}
+
+	count := 0
+	err = s.IterateRecord(&storage.RecordQueryParams{QueryParams: storage.QueryParams{Type: types.MTBlock, Limit: iterateBatch + 1}}, func(r *storage.SignRecord) error {
+		assert.Equal(t, types.MTBlock, r.Type)
+		count++
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, iterateBatch+1, count)
 
+	stop := errors.New("stop")
+	assert.ErrorIs(t, s.IterateRecord(&storage.RecordQueryParams{}, func(r *storage.SignRecord) error { return stop }), stop)
 }
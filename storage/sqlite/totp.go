@@ -0,0 +1,77 @@
+package sqlite
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/filecoin-project/venus-wallet/crypto/aes"
+	"github.com/filecoin-project/venus-wallet/storage"
+)
+
+// there is one totp enrollment per wallet, kept in the keystore next to the keys
+const totpRowID = 1
+
+type sqliteTOTP struct {
+	ID            int             `gorm:"primaryKey"`
+	Secret        *aes.CryptoJSON `gorm:"serializer:json"`
+	RecoveryCodes []string        `gorm:"serializer:json"`
+	Active        bool
+	LastCounter   int64
+	Failures      int
+	LockedUntil   time.Time
+	CreatedAt     time.Time
+}
+
+func (s *sqliteTOTP) TableName() string {
+	return "totp"
+}
+
+type totpStore struct {
+	db *gorm.DB
+}
+
+func NewTOTPStore(db *gorm.DB) (storage.ITOTPStore, error) {
+	if err := db.AutoMigrate(&sqliteTOTP{}); err != nil {
+		return nil, fmt.Errorf("init totp store: %w", err)
+	}
+	return &totpStore{db: db}, nil
+}
+
+func (s *totpStore) GetTOTP() (*storage.TOTPRecord, error) {
+	var rows []*sqliteTOTP
+	if err := s.db.Where("id = ?", totpRowID).Limit(1).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+	return &storage.TOTPRecord{
+		Secret:        rows[0].Secret,
+		RecoveryCodes: rows[0].RecoveryCodes,
+		Active:        rows[0].Active,
+		LastCounter:   rows[0].LastCounter,
+		Failures:      rows[0].Failures,
+		LockedUntil:   rows[0].LockedUntil,
+		CreateAt:      rows[0].CreatedAt,
+	}, nil
+}
+
+func (s *totpStore) PutTOTP(rec *storage.TOTPRecord) error {
+	return s.db.Clauses(clause.OnConflict{UpdateAll: true}).Create(&sqliteTOTP{
+		ID:            totpRowID,
+		Secret:        rec.Secret,
+		RecoveryCodes: rec.RecoveryCodes,
+		Active:        rec.Active,
+		LastCounter:   rec.LastCounter,
+		Failures:      rec.Failures,
+		LockedUntil:   rec.LockedUntil,
+		CreatedAt:     rec.CreateAt,
+	}).Error
+}
+
+func (s *totpStore) DeleteTOTP() error {
+	return s.db.Where("id = ?", totpRowID).Delete(&sqliteTOTP{}).Error
+}
@@ -1,10 +1,15 @@
 package storage
 
 import (
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/crypto"
 	"github.com/filecoin-project/venus-wallet/crypto/aes"
+	"github.com/filecoin-project/venus-wallet/middleware"
 	"github.com/filecoin-project/venus/venus-shared/types"
 )
 
@@ -30,9 +35,89 @@ type KeyStore interface {
 
 type QueryParams = types.QuerySignRecordParams
 
-type SignRecord = types.SignRecord
+// RecordQueryParams the shared query params, with the fields decoded from the chain messages.
+// Setting any of the message fields only selects chain messages.
+type RecordQueryParams struct {
+	QueryParams
+	To     address.Address
+	Method *abi.MethodNum
+	Nonce  *uint64
+	// MinValue, MaxValue bounds of the value, in attoFIL
+	MinValue *abi.TokenAmount
+	MaxValue *abi.TokenAmount
+	MsgCID   string
+}
+
+// HasMsgFilter any of the chain message fields is set
+func (p *RecordQueryParams) HasMsgFilter() bool {
+	return p.To != address.Undef || p.Method != nil || p.Nonce != nil || p.MinValue != nil || p.MaxValue != nil || p.MsgCID != ""
+}
+
+// SignRecord the record of a sign request, with the fields the shared types.SignRecord lacks
+type SignRecord struct {
+	ID     string
+	Type   types.MsgType
+	Signer address.Address
+	Err    string
+	// RawMsg cbor encoded sign object, nil if it couldn't be encoded
+	RawMsg    []byte
+	Signature *crypto.Signature
+	CreateAt  time.Time
+	// Caller who asked for the signature, nil for internal calls
+	Caller *middleware.Caller
+	// MsgCID cid of the signed object where one applies, eg. chain messages and block headers
+	MsgCID string
+	// Extra the MsgMeta.Extra sent with the request
+	Extra []byte
+	// ToSign the bytes actually signed
+	ToSign []byte
+	// Duration from the arrival of the request to the signature, including the approval wait
+	Duration time.Duration
+	// Summary a human readable description of the signed object
+	Summary string
+	// Seq position in the hash chain, starting from 1, 0 for the records written before the chain
+	Seq uint64
+	// PrevHash the Hash of the previous record, empty for the first one
+	PrevHash []byte
+	// Hash see ChainHash
+	Hash []byte
+}
+
+// ToShared converts to the record type of the shared api
+func (r *SignRecord) ToShared() types.SignRecord {
+	ret := types.SignRecord{
+		ID:        r.ID,
+		Type:      r.Type,
+		Signer:    r.Signer,
+		RawMsg:    r.RawMsg,
+		Signature: r.Signature,
+		CreateAt:  r.CreateAt,
+	}
+	if r.Err != "" {
+		ret.Err = errors.New(r.Err)
+	}
+	return ret
+}
 
 type IRecorder interface {
+	// Record appends the record to the chain, Seq, PrevHash and Hash are set by the recorder
 	Record(rcd *SignRecord) error
-	QueryRecord(params *QueryParams) ([]SignRecord, error)
+	// RecordBatch appends the records in one transaction, in their order
+	RecordBatch(rcds []*SignRecord) error
+	QueryRecord(params *RecordQueryParams) ([]SignRecord, error)
+	// IterateRecord calls fn on the records matching the params from the oldest one, reading them in pages.
+	// Limit caps the number of records, Skip and ID are ignored. It stops at the first error of fn.
+	IterateRecord(params *RecordQueryParams, fn func(record *SignRecord) error) error
+	// RecordStats counts the records by signer, type, error and time
+	RecordStats(params *RecordStatsParams) (*RecordStats, error)
+	IRecordChain
+	IAdminRecorder
+}
+
+// RecordExportItem an element of the record export stream,
+// the last one is either Done, or carries the error if the export failed
+type RecordExportItem struct {
+	Record *SignRecord `json:",omitempty"`
+	Err    string      `json:",omitempty"`
+	Done   bool        `json:",omitempty"`
 }
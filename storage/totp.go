@@ -0,0 +1,32 @@
+package storage
+
+import (
+	"time"
+
+	"github.com/filecoin-project/venus-wallet/crypto/aes"
+)
+
+// TOTPRecord the second factor of the wallet
+type TOTPRecord struct {
+	// Secret the totp secret encrypted under the wallet password
+	Secret *aes.CryptoJSON
+	// RecoveryCodes sha256 of the unused recovery codes, each code works once
+	RecoveryCodes []string
+	// Active the enrollment has been confirmed with a valid code
+	Active bool
+	// LastCounter the time step of the last accepted code, a code is never accepted twice
+	LastCounter int64
+	// Failures the invalid codes in a row, reset by a valid one
+	Failures int
+	// LockedUntil no code is accepted before, set after too many failures
+	LockedUntil time.Time
+	CreateAt    time.Time
+}
+
+// ITOTPStore keeps the single TOTPRecord of the wallet
+type ITOTPStore interface {
+	// GetTOTP returns nil if the wallet isn't enrolled
+	GetTOTP() (*TOTPRecord, error)
+	PutTOTP(rec *TOTPRecord) error
+	DeleteTOTP() error
+}
@@ -0,0 +1,54 @@
+package wallet
+
+import (
+	"testing"
+
+	"github.com/filecoin-project/venus/venus-shared/types"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/filecoin-project/venus-wallet/middleware"
+	"github.com/filecoin-project/venus-wallet/storage"
+)
+
+func TestWallet_AdminRecord(t *testing.T) {
+	w, ctx := newTestWallet(t)
+	setTestRecorder(t, w)
+
+	caller := &middleware.Caller{Name: "ops", RemoteAddr: "10.0.0.1:4000"}
+	ctx = middleware.WithCaller(ctx, caller)
+
+	addr, err := w.WalletNew(ctx, types.KTSecp256k1)
+	assert.NoError(t, err)
+	_, err = w.WalletExport(ctx, addr)
+	assert.NoError(t, err)
+	assert.Error(t, w.Lock(ctx, "wrong password"))
+	assert.NoError(t, w.Lock(ctx, "password"))
+	assert.NoError(t, w.Unlock(ctx, "password"))
+	assert.NoError(t, w.WalletDelete(ctx, addr))
+
+	records, err := w.recorder.QueryAdminRecord(&storage.AdminQueryParams{})
+	assert.NoError(t, err)
+	ops := make([]storage.AdminOp, 0, len(records))
+	for _, r := range records {
+		ops = append(ops, r.Op)
+	}
+	// the latest first
+	assert.Equal(t, []storage.AdminOp{storage.AdminWalletDelete, storage.AdminUnlock, storage.AdminLock,
+		storage.AdminLock, storage.AdminWalletExport, storage.AdminWalletNew}, ops)
+	for _, r := range records {
+		assert.Equal(t, caller, r.Caller)
+	}
+
+	// who exported the key, and when
+	exports, err := w.recorder.QueryAdminRecord(&storage.AdminQueryParams{Op: storage.AdminWalletExport, Target: addr.String()})
+	assert.NoError(t, err)
+	assert.Len(t, exports, 1)
+	assert.Equal(t, "ops", exports[0].Caller.Name)
+	assert.Empty(t, exports[0].Err)
+
+	failed, err := w.recorder.QueryAdminRecord(&storage.AdminQueryParams{IsError: true})
+	assert.NoError(t, err)
+	assert.Len(t, failed, 1)
+	assert.Equal(t, storage.AdminLock, failed[0].Op)
+	assert.NotEmpty(t, failed[0].Err)
+}
@@ -0,0 +1,235 @@
+package wallet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/venus/venus-shared/types"
+	"github.com/google/uuid"
+
+	"github.com/filecoin-project/venus-wallet/config"
+	"github.com/filecoin-project/venus-wallet/storage"
+)
+
+var (
+	ErrApprovalRejected = errors.New("sign request rejected by approver")
+	ErrApprovalTimeout  = errors.New("sign request approval timeout")
+)
+
+// IApproval manage the sign requests waiting for approval
+type IApproval interface {
+	// ApprovalList list the approval requests in the given state, all of them if state is empty
+	ApprovalList(ctx context.Context, state storage.ApprovalState) ([]storage.ApprovalRequest, error)
+	// ApprovalGet get an approval request by id
+	ApprovalGet(ctx context.Context, id string) (*storage.ApprovalRequest, error)
+	// ApprovalApprove let the parked sign request go on
+	ApprovalApprove(ctx context.Context, id string, comment string) error
+	// ApprovalReject fail the parked sign request
+	ApprovalReject(ctx context.Context, id string, comment string) error
+}
+
+var _ IApproval = &ApprovalQueue{}
+
+type approvalRules struct {
+	enable   bool
+	timeout  time.Duration
+	minValue abi.TokenAmount
+	signers  map[address.Address]struct{}
+	methods  map[abi.MethodNum]struct{}
+}
+
+func parseApprovalRules(cfg *config.ApprovalConfig) (*approvalRules, error) {
+	rules := &approvalRules{
+		timeout: 10 * time.Minute,
+		signers: make(map[address.Address]struct{}),
+		methods: make(map[abi.MethodNum]struct{}),
+	}
+	if cfg == nil {
+		return rules, nil
+	}
+	rules.enable = cfg.Enable
+	if cfg.Timeout != "" {
+		d, err := time.ParseDuration(cfg.Timeout)
+		if err != nil {
+			return nil, fmt.Errorf("parse approval timeout: %w", err)
+		}
+		rules.timeout = d
+	}
+	if cfg.MinValue != "" {
+		v, err := types.ParseFIL(cfg.MinValue)
+		if err != nil {
+			return nil, fmt.Errorf("parse approval min value: %w", err)
+		}
+		rules.minValue = abi.TokenAmount(v)
+	}
+	for _, s := range cfg.Signers {
+		addr, err := address.NewFromString(s)
+		if err != nil {
+			return nil, fmt.Errorf("parse approval signer %s: %w", s, err)
+		}
+		rules.signers[addr] = struct{}{}
+	}
+	for _, m := range cfg.Methods {
+		rules.methods[abi.MethodNum(m)] = struct{}{}
+	}
+	return rules, nil
+}
+
+// match returns the reason why the message needs approval, empty if it doesn't
+func (r *approvalRules) match(signer address.Address, signMsg SignMsg) string {
+	if !r.enable || signMsg.SignType != types.MTChainMsg {
+		return ""
+	}
+	msg, ok := signMsg.Data.(*types.Message)
+	if !ok {
+		return ""
+	}
+	if _, ok := r.signers[signer]; ok {
+		return fmt.Sprintf("signer %s requires approval", signer)
+	}
+	if _, ok := r.methods[msg.Method]; ok {
+		return fmt.Sprintf("method %d requires approval", msg.Method)
+	}
+	if !r.minValue.Nil() && msg.Value.GreaterThanEqual(r.minValue) {
+		return fmt.Sprintf("value %s reaches %s", types.FIL(msg.Value), types.FIL(r.minValue))
+	}
+	return ""
+}
+
+// ApprovalQueue parks the sign requests matching the approval rules until someone decides on them
+type ApprovalQueue struct {
+	rules   atomic.Pointer[approvalRules]
+	store   storage.IApprovalStore
+	lk      sync.Mutex
+	waiters map[string]chan storage.ApprovalState
+}
+
+func NewApprovalQueue(cfg *config.ApprovalConfig, store storage.IApprovalStore) (*ApprovalQueue, error) {
+	rules, err := parseApprovalRules(cfg)
+	if err != nil {
+		return nil, err
+	}
+	// nobody is waiting for the requests left from the last run
+	if err := store.ExpirePending(); err != nil {
+		return nil, fmt.Errorf("expire pending approval requests: %w", err)
+	}
+	q := &ApprovalQueue{
+		store:   store,
+		waiters: make(map[string]chan storage.ApprovalState),
+	}
+	q.rules.Store(rules)
+	return q, nil
+}
+
+// Reload validates the new config, the returned function applies it.
+// Requests already waiting keep the timeout they started with.
+func (q *ApprovalQueue) Reload(cfg *config.ApprovalConfig) (func(), error) {
+	rules, err := parseApprovalRules(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return func() { q.rules.Store(rules) }, nil
+}
+
+// Match returns the reason why the message needs approval, empty if it doesn't
+func (q *ApprovalQueue) Match(signer address.Address, signMsg SignMsg) string {
+	return q.rules.Load().match(signer, signMsg)
+}
+
+// Wait parks the request and blocks until it is approved, rejected or timeout
+func (q *ApprovalQueue) Wait(ctx context.Context, signer address.Address, signType types.MsgType, rawMsg []byte, reason string) error {
+	timeout := q.rules.Load().timeout
+	now := time.Now()
+	req := &storage.ApprovalRequest{
+		ID:       uuid.New().String(),
+		Type:     signType,
+		Signer:   signer,
+		RawMsg:   rawMsg,
+		Reason:   reason,
+		State:    storage.ApprovalPending,
+		CreateAt: now,
+		Deadline: now.Add(timeout),
+	}
+
+	ch := make(chan storage.ApprovalState, 1)
+	q.lk.Lock()
+	q.waiters[req.ID] = ch
+	q.lk.Unlock()
+	defer func() {
+		q.lk.Lock()
+		delete(q.waiters, req.ID)
+		q.lk.Unlock()
+	}()
+
+	if err := q.store.Put(req); err != nil {
+		return fmt.Errorf("park sign request: %w", err)
+	}
+	log.Infof("sign request %s from %s waits for approval: %s", req.ID, signer, reason)
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case state := <-ch:
+		if state == storage.ApprovalApproved {
+			return nil
+		}
+		return fmt.Errorf("%w: %s", ErrApprovalRejected, req.ID)
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+
+	// the decision may race with the timeout, the store keeps the first one
+	q.lk.Lock()
+	err := q.store.Update(req.ID, storage.ApprovalExpired, "")
+	q.lk.Unlock()
+	if err != nil {
+		select {
+		case state := <-ch:
+			if state == storage.ApprovalApproved {
+				return nil
+			}
+			return fmt.Errorf("%w: %s", ErrApprovalRejected, req.ID)
+		default:
+		}
+		log.Warnf("expire approval request %s: %v", req.ID, err)
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return fmt.Errorf("%w: %s", ErrApprovalTimeout, req.ID)
+}
+
+func (q *ApprovalQueue) ApprovalList(ctx context.Context, state storage.ApprovalState) ([]storage.ApprovalRequest, error) {
+	return q.store.List(state)
+}
+
+func (q *ApprovalQueue) ApprovalGet(ctx context.Context, id string) (*storage.ApprovalRequest, error) {
+	return q.store.Get(id)
+}
+
+func (q *ApprovalQueue) ApprovalApprove(ctx context.Context, id string, comment string) error {
+	return q.decide(id, storage.ApprovalApproved, comment)
+}
+
+func (q *ApprovalQueue) ApprovalReject(ctx context.Context, id string, comment string) error {
+	return q.decide(id, storage.ApprovalRejected, comment)
+}
+
+func (q *ApprovalQueue) decide(id string, state storage.ApprovalState, comment string) error {
+	q.lk.Lock()
+	defer q.lk.Unlock()
+	if err := q.store.Update(id, state, comment); err != nil {
+		return err
+	}
+	if ch, ok := q.waiters[id]; ok {
+		ch <- state
+	}
+	return nil
+}
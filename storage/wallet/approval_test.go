@@ -0,0 +1,109 @@
+package wallet
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/venus/venus-shared/types"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/filecoin-project/venus-wallet/config"
+	"github.com/filecoin-project/venus-wallet/storage"
+	walletsqlite "github.com/filecoin-project/venus-wallet/storage/sqlite"
+)
+
+func newTestApprovalQueue(t *testing.T, cfg *config.ApprovalConfig) *ApprovalQueue {
+	db, err := gorm.Open(sqlite.Open(fmt.Sprintf("file:%s?mode=memory&cache=shared", t.Name())), &gorm.Config{})
+	assert.NoError(t, err)
+	store, err := walletsqlite.NewApprovalStore(db)
+	assert.NoError(t, err)
+	q, err := NewApprovalQueue(cfg, store)
+	assert.NoError(t, err)
+	return q
+}
+
+func TestApprovalQueue(t *testing.T) {
+	ctx := context.Background()
+	owner, err := address.NewIDAddress(1000)
+	assert.NoError(t, err)
+	worker, err := address.NewIDAddress(1001)
+	assert.NoError(t, err)
+
+	q := newTestApprovalQueue(t, &config.ApprovalConfig{
+		Enable:   true,
+		Timeout:  "200ms",
+		MinValue: "100",
+		Signers:  []string{owner.String()},
+		Methods:  []uint64{23},
+	})
+
+	msgOf := func(from address.Address, method abi.MethodNum, value string) SignMsg {
+		return SignMsg{
+			SignType: types.MTChainMsg,
+			Data: &types.Message{
+				From:   from,
+				To:     worker,
+				Method: method,
+				Value:  abi.TokenAmount(types.MustParseFIL(value)),
+			},
+		}
+	}
+
+	t.Run("match", func(t *testing.T) {
+		assert.NotEmpty(t, q.Match(owner, msgOf(owner, 0, "0")))
+		assert.NotEmpty(t, q.Match(worker, msgOf(worker, 23, "0")))
+		assert.NotEmpty(t, q.Match(worker, msgOf(worker, 0, "100")))
+		assert.Empty(t, q.Match(worker, msgOf(worker, 0, "99")))
+		assert.Empty(t, q.Match(worker, SignMsg{SignType: types.MTBlock, Data: &types.BlockHeader{}}))
+	})
+
+	waitPending := func(t *testing.T) string {
+		for i := 0; i < 100; i++ {
+			reqs, err := q.ApprovalList(ctx, storage.ApprovalPending)
+			assert.NoError(t, err)
+			if len(reqs) > 0 {
+				return reqs[0].ID
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		t.Fatal("no pending request")
+		return ""
+	}
+
+	t.Run("approve", func(t *testing.T) {
+		done := make(chan error)
+		go func() { done <- q.Wait(ctx, owner, types.MTChainMsg, nil, "test") }()
+		id := waitPending(t)
+		assert.NoError(t, q.ApprovalApprove(ctx, id, "ok"))
+		assert.NoError(t, <-done)
+
+		req, err := q.ApprovalGet(ctx, id)
+		assert.NoError(t, err)
+		assert.Equal(t, storage.ApprovalApproved, req.State)
+		assert.Equal(t, "ok", req.Comment)
+		assert.Error(t, q.ApprovalReject(ctx, id, ""))
+	})
+
+	t.Run("reject", func(t *testing.T) {
+		done := make(chan error)
+		go func() { done <- q.Wait(ctx, owner, types.MTChainMsg, nil, "test") }()
+		id := waitPending(t)
+		assert.NoError(t, q.ApprovalReject(ctx, id, "no"))
+		assert.ErrorIs(t, <-done, ErrApprovalRejected)
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		err := q.Wait(ctx, owner, types.MTChainMsg, nil, "test")
+		assert.ErrorIs(t, err, ErrApprovalTimeout)
+
+		reqs, err := q.ApprovalList(ctx, storage.ApprovalExpired)
+		assert.NoError(t, err)
+		assert.Len(t, reqs, 1)
+	})
+}
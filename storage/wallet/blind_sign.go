@@ -0,0 +1,89 @@
+package wallet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/venus/venus-shared/types"
+
+	"github.com/filecoin-project/venus-wallet/config"
+)
+
+var ErrBlindSignNotAllowed = errors.New("blind signing is not allowed")
+
+var _ ISignMsgFilter = &BlindSignPolicy{}
+
+type blindSignRules struct {
+	addresses map[address.Address]struct{}
+	prefix    []byte
+}
+
+func parseBlindSignRules(cfg *config.BlindSignConfig) (*blindSignRules, error) {
+	r := &blindSignRules{
+		addresses: make(map[address.Address]struct{}),
+	}
+	if cfg == nil {
+		return r, nil
+	}
+	for _, s := range cfg.Addresses {
+		addr, err := address.NewFromString(s)
+		if err != nil {
+			return nil, fmt.Errorf("parse blind sign address %s: %w", s, err)
+		}
+		r.addresses[addr] = struct{}{}
+	}
+	r.prefix = []byte(cfg.DomainPrefix)
+	return r, nil
+}
+
+// BlindSignPolicy controls which addresses may sign arbitrary bytes (MsgType "unknown")
+type BlindSignPolicy struct {
+	rules atomic.Pointer[blindSignRules]
+}
+
+func NewBlindSignPolicy(cfg *config.BlindSignConfig) (*BlindSignPolicy, error) {
+	rules, err := parseBlindSignRules(cfg)
+	if err != nil {
+		return nil, err
+	}
+	p := &BlindSignPolicy{}
+	p.rules.Store(rules)
+	return p, nil
+}
+
+// Reload validates the new config, the returned function applies it
+func (p *BlindSignPolicy) Reload(cfg *config.BlindSignConfig) (func(), error) {
+	rules, err := parseBlindSignRules(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return func() { p.rules.Store(rules) }, nil
+}
+
+func (p *BlindSignPolicy) Name() string {
+	return "blind_sign"
+}
+
+func (p *BlindSignPolicy) CheckSignMsg(ctx context.Context, signMsg SignMsg) error {
+	if signMsg.SignType != types.MTUnknown {
+		return nil
+	}
+	if _, ok := p.rules.Load().addresses[signMsg.Signer]; !ok {
+		return fmt.Errorf("%w for %s", ErrBlindSignNotAllowed, signMsg.Signer)
+	}
+	return nil
+}
+
+// SignBytes returns the bytes actually signed for the arbitrary data
+func (p *BlindSignPolicy) SignBytes(data []byte) []byte {
+	prefix := p.rules.Load().prefix
+	if len(prefix) == 0 {
+		return data
+	}
+	out := make([]byte, 0, len(prefix)+len(data))
+	out = append(out, prefix...)
+	return append(out, data...)
+}
@@ -0,0 +1,41 @@
+package wallet
+
+import (
+	"context"
+	"testing"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/venus/venus-shared/types"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/filecoin-project/venus-wallet/config"
+)
+
+func TestBlindSignPolicy(t *testing.T) {
+	ctx := context.Background()
+	allowed, err := address.NewIDAddress(1000)
+	assert.NoError(t, err)
+	other, err := address.NewIDAddress(1001)
+	assert.NoError(t, err)
+
+	t.Run("off by default", func(t *testing.T) {
+		p, err := NewBlindSignPolicy(&config.BlindSignConfig{})
+		assert.NoError(t, err)
+		err = p.CheckSignMsg(ctx, SignMsg{SignType: types.MTUnknown, Signer: allowed, Data: []byte("hello")})
+		assert.ErrorIs(t, err, ErrBlindSignNotAllowed)
+		assert.Equal(t, []byte("hello"), p.SignBytes([]byte("hello")))
+	})
+
+	t.Run("per address", func(t *testing.T) {
+		p, err := NewBlindSignPolicy(&config.BlindSignConfig{
+			Addresses:    []string{allowed.String()},
+			DomainPrefix: "prefix:",
+		})
+		assert.NoError(t, err)
+		assert.NoError(t, p.CheckSignMsg(ctx, SignMsg{SignType: types.MTUnknown, Signer: allowed, Data: []byte("hello")}))
+		assert.ErrorIs(t, p.CheckSignMsg(ctx, SignMsg{SignType: types.MTUnknown, Signer: other, Data: []byte("hello")}), ErrBlindSignNotAllowed)
+		// typed messages are not affected
+		assert.NoError(t, p.CheckSignMsg(ctx, SignMsg{SignType: types.MTChainMsg, Signer: other, Data: &types.Message{}}))
+		assert.Equal(t, []byte("prefix:hello"), p.SignBytes([]byte("hello")))
+	})
+}
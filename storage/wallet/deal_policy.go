@@ -0,0 +1,207 @@
+package wallet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/dustin/go-humanize"
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/specs-actors/v2/actors/builtin/market"
+	"github.com/filecoin-project/venus/venus-shared/types"
+
+	"github.com/filecoin-project/venus-wallet/config"
+)
+
+var ErrDealPolicy = errors.New("deal proposal violates deal policy")
+
+const (
+	verifiedDealRequire = "require"
+	verifiedDealForbid  = "forbid"
+)
+
+var _ ISignMsgFilter = &DealPolicy{}
+
+type dealRules struct {
+	enable                bool
+	maxPricePerEpoch      abi.TokenAmount
+	maxProviderCollateral abi.TokenAmount
+	maxClientCollateral   abi.TokenAmount
+	minDuration           abi.ChainEpoch
+	maxDuration           abi.ChainEpoch
+	minPieceSize          abi.PaddedPieceSize
+	maxPieceSize          abi.PaddedPieceSize
+	verifiedDeal          string
+	allowedProviders      map[address.Address]struct{}
+}
+
+func parseDealRules(cfg *config.DealPolicyConfig) (*dealRules, error) {
+	p := &dealRules{
+		allowedProviders: make(map[address.Address]struct{}),
+	}
+	if cfg == nil || !cfg.Enable {
+		return p, nil
+	}
+	p.enable = true
+
+	parseFIL := func(name, s string) (abi.TokenAmount, error) {
+		if s == "" {
+			return abi.TokenAmount{}, nil
+		}
+		v, err := types.ParseFIL(s)
+		if err != nil {
+			return abi.TokenAmount{}, fmt.Errorf("parse deal policy %s: %w", name, err)
+		}
+		return abi.TokenAmount(v), nil
+	}
+	parseSize := func(name, s string) (abi.PaddedPieceSize, error) {
+		if s == "" {
+			return 0, nil
+		}
+		v, err := humanize.ParseBytes(s)
+		if err != nil {
+			return 0, fmt.Errorf("parse deal policy %s: %w", name, err)
+		}
+		return abi.PaddedPieceSize(v), nil
+	}
+
+	var err error
+	if p.maxPricePerEpoch, err = parseFIL("max price per epoch", cfg.MaxPricePerEpoch); err != nil {
+		return nil, err
+	}
+	if p.maxProviderCollateral, err = parseFIL("max provider collateral", cfg.MaxProviderCollateral); err != nil {
+		return nil, err
+	}
+	if p.maxClientCollateral, err = parseFIL("max client collateral", cfg.MaxClientCollateral); err != nil {
+		return nil, err
+	}
+	if p.minPieceSize, err = parseSize("min piece size", cfg.MinPieceSize); err != nil {
+		return nil, err
+	}
+	if p.maxPieceSize, err = parseSize("max piece size", cfg.MaxPieceSize); err != nil {
+		return nil, err
+	}
+	p.minDuration = abi.ChainEpoch(cfg.MinDuration)
+	p.maxDuration = abi.ChainEpoch(cfg.MaxDuration)
+
+	switch cfg.VerifiedDeal {
+	case "", verifiedDealRequire, verifiedDealForbid:
+		p.verifiedDeal = cfg.VerifiedDeal
+	default:
+		return nil, fmt.Errorf("parse deal policy verified deal: unknown value %s", cfg.VerifiedDeal)
+	}
+
+	for _, s := range cfg.AllowedProviders {
+		addr, err := address.NewFromString(s)
+		if err != nil {
+			return nil, fmt.Errorf("parse deal policy provider %s: %w", s, err)
+		}
+		p.allowedProviders[addr] = struct{}{}
+	}
+	return p, nil
+}
+
+// DealPolicy checks the terms of storage deal proposals against the configured bounds
+type DealPolicy struct {
+	rules atomic.Pointer[dealRules]
+}
+
+func NewDealPolicy(cfg *config.DealPolicyConfig) (*DealPolicy, error) {
+	rules, err := parseDealRules(cfg)
+	if err != nil {
+		return nil, err
+	}
+	p := &DealPolicy{}
+	p.rules.Store(rules)
+	return p, nil
+}
+
+// Reload validates the new config, the returned function applies it
+func (p *DealPolicy) Reload(cfg *config.DealPolicyConfig) (func(), error) {
+	rules, err := parseDealRules(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return func() { p.rules.Store(rules) }, nil
+}
+
+func (p *DealPolicy) Name() string {
+	return "deal_policy"
+}
+
+func (p *DealPolicy) CheckSignMsg(ctx context.Context, signMsg SignMsg) error {
+	rules := p.rules.Load()
+	if !rules.enable {
+		return nil
+	}
+
+	var proposal *market.DealProposal
+	switch signMsg.SignType {
+	case types.MTDealProposal:
+		proposal, _ = signMsg.Data.(*market.DealProposal)
+	case types.MTClientDeal:
+		if cdp, ok := signMsg.Data.(*market.ClientDealProposal); ok {
+			proposal = &cdp.Proposal
+		}
+	default:
+		return nil
+	}
+	if proposal == nil {
+		return fmt.Errorf("%w: unexpected data %T", ErrDealPolicy, signMsg.Data)
+	}
+
+	if err := rules.check(proposal); err != nil {
+		return fmt.Errorf("%w: %s", ErrDealPolicy, err)
+	}
+	return nil
+}
+
+func (p *dealRules) check(proposal *market.DealProposal) error {
+	if !p.maxPricePerEpoch.Nil() && proposal.StoragePricePerEpoch.GreaterThan(p.maxPricePerEpoch) {
+		return fmt.Errorf("price per epoch %s exceeds %s", types.FIL(proposal.StoragePricePerEpoch), types.FIL(p.maxPricePerEpoch))
+	}
+	if !p.maxProviderCollateral.Nil() && proposal.ProviderCollateral.GreaterThan(p.maxProviderCollateral) {
+		return fmt.Errorf("provider collateral %s exceeds %s", types.FIL(proposal.ProviderCollateral), types.FIL(p.maxProviderCollateral))
+	}
+	if !p.maxClientCollateral.Nil() && proposal.ClientCollateral.GreaterThan(p.maxClientCollateral) {
+		return fmt.Errorf("client collateral %s exceeds %s", types.FIL(proposal.ClientCollateral), types.FIL(p.maxClientCollateral))
+	}
+
+	duration := proposal.Duration()
+	if duration <= 0 {
+		return fmt.Errorf("end epoch %d is not after start epoch %d", proposal.EndEpoch, proposal.StartEpoch)
+	}
+	if p.minDuration > 0 && duration < p.minDuration {
+		return fmt.Errorf("duration %d is shorter than %d", duration, p.minDuration)
+	}
+	if p.maxDuration > 0 && duration > p.maxDuration {
+		return fmt.Errorf("duration %d is longer than %d", duration, p.maxDuration)
+	}
+
+	if p.minPieceSize > 0 && proposal.PieceSize < p.minPieceSize {
+		return fmt.Errorf("piece size %d is smaller than %d", proposal.PieceSize, p.minPieceSize)
+	}
+	if p.maxPieceSize > 0 && proposal.PieceSize > p.maxPieceSize {
+		return fmt.Errorf("piece size %d is larger than %d", proposal.PieceSize, p.maxPieceSize)
+	}
+
+	switch p.verifiedDeal {
+	case verifiedDealRequire:
+		if !proposal.VerifiedDeal {
+			return fmt.Errorf("only verified deals are allowed")
+		}
+	case verifiedDealForbid:
+		if proposal.VerifiedDeal {
+			return fmt.Errorf("verified deals are not allowed")
+		}
+	}
+
+	if len(p.allowedProviders) > 0 {
+		if _, ok := p.allowedProviders[proposal.Provider]; !ok {
+			return fmt.Errorf("provider %s is not allowed", proposal.Provider)
+		}
+	}
+	return nil
+}
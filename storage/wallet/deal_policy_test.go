@@ -0,0 +1,83 @@
+package wallet
+
+import (
+	"context"
+	"testing"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/specs-actors/v2/actors/builtin/market"
+	"github.com/filecoin-project/venus/venus-shared/types"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/filecoin-project/venus-wallet/config"
+)
+
+func TestDealPolicy_CheckSignMsg(t *testing.T) {
+	ctx := context.Background()
+	provider, err := address.NewIDAddress(1000)
+	assert.NoError(t, err)
+	other, err := address.NewIDAddress(1001)
+	assert.NoError(t, err)
+
+	policy, err := NewDealPolicy(&config.DealPolicyConfig{
+		Enable:                true,
+		MaxPricePerEpoch:      "0.0000001",
+		MaxProviderCollateral: "1",
+		MaxClientCollateral:   "1",
+		MinDuration:           518400,
+		MaxDuration:           1555200,
+		MinPieceSize:          "1MiB",
+		MaxPieceSize:          "32GiB",
+		VerifiedDeal:          "require",
+		AllowedProviders:      []string{provider.String()},
+	})
+	assert.NoError(t, err)
+
+	good := func() *market.DealProposal {
+		return &market.DealProposal{
+			PieceSize:            abi.PaddedPieceSize(32 << 30),
+			VerifiedDeal:         true,
+			Provider:             provider,
+			StartEpoch:           1000,
+			EndEpoch:             1000 + 518400,
+			StoragePricePerEpoch: abi.NewTokenAmount(0),
+			ProviderCollateral:   abi.TokenAmount(types.MustParseFIL("0.5")),
+			ClientCollateral:     abi.NewTokenAmount(0),
+		}
+	}
+	check := func(p *market.DealProposal) error {
+		return policy.CheckSignMsg(ctx, SignMsg{SignType: types.MTDealProposal, Data: p})
+	}
+
+	assert.NoError(t, check(good()))
+	assert.NoError(t, policy.CheckSignMsg(ctx, SignMsg{SignType: types.MTClientDeal, Data: &market.ClientDealProposal{Proposal: *good()}}))
+	// other types are not checked
+	assert.NoError(t, policy.CheckSignMsg(ctx, SignMsg{SignType: types.MTChainMsg, Data: &types.Message{}}))
+
+	bad := map[string]func(p *market.DealProposal){
+		"price":      func(p *market.DealProposal) { p.StoragePricePerEpoch = abi.TokenAmount(types.MustParseFIL("1")) },
+		"collateral": func(p *market.DealProposal) { p.ProviderCollateral = abi.TokenAmount(types.MustParseFIL("2")) },
+		"short":      func(p *market.DealProposal) { p.EndEpoch = p.StartEpoch + 10 },
+		"long":       func(p *market.DealProposal) { p.EndEpoch = p.StartEpoch + 2000000 },
+		"small":      func(p *market.DealProposal) { p.PieceSize = 512 },
+		"large":      func(p *market.DealProposal) { p.PieceSize = 64 << 30 },
+		"unverified": func(p *market.DealProposal) { p.VerifiedDeal = false },
+		"provider":   func(p *market.DealProposal) { p.Provider = other },
+	}
+	for name, modify := range bad {
+		t.Run(name, func(t *testing.T) {
+			p := good()
+			modify(p)
+			assert.ErrorIs(t, check(p), ErrDealPolicy)
+		})
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		policy, err := NewDealPolicy(&config.DealPolicyConfig{MaxPricePerEpoch: "0"})
+		assert.NoError(t, err)
+		p := good()
+		p.StoragePricePerEpoch = abi.TokenAmount(types.MustParseFIL("1"))
+		assert.NoError(t, policy.CheckSignMsg(ctx, SignMsg{SignType: types.MTDealProposal, Data: p}))
+	})
+}
@@ -0,0 +1,180 @@
+package wallet
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/venus/venus-shared/types"
+
+	"github.com/filecoin-project/venus-wallet/middleware"
+)
+
+// eventBuffer the events a subscription holds for a slow subscriber
+const eventBuffer = 256
+
+type EventType string
+
+const (
+	EventSignSuccess EventType = "sign_success"
+	// EventSignFailure the key couldn't be used, or the signature failed verification
+	EventSignFailure EventType = "sign_failure"
+	// EventSignRejected rejected by the policies: disabled key, rate limit, sign filter or approval
+	EventSignRejected EventType = "sign_rejected"
+	EventLock         EventType = "lock"
+	EventUnlock       EventType = "unlock"
+	EventKeyAdd       EventType = "key_add"
+	EventKeyRemove    EventType = "key_remove"
+)
+
+// EventTypes the event types, which can be subscribed to
+var EventTypes = []EventType{EventSignSuccess, EventSignFailure, EventSignRejected, EventLock, EventUnlock, EventKeyAdd, EventKeyRemove}
+
+// WalletEvent a change of the wallet, or the outcome of a sign request
+type WalletEvent struct {
+	Type EventType
+	Time time.Time
+	// Address the signer or the key, undef for lock and unlock
+	Address address.Address
+	// MsgType, MsgCID and Summary describe the message of the sign events
+	MsgType types.MsgType `json:",omitempty"`
+	MsgCID  string        `json:",omitempty"`
+	Summary string        `json:",omitempty"`
+	Err     string        `json:",omitempty"`
+	Caller  *middleware.Caller
+	// Missed the events of the subscription dropped before this one, because the subscriber didn't keep up
+	Missed uint64 `json:",omitempty"`
+}
+
+// EventFilter selects the events of a subscription, empty fields select all
+type EventFilter struct {
+	Addresses []address.Address
+	Types     []EventType
+}
+
+// IEvents the wallet events as they happen, the subscription needs a websocket connection
+type IEvents interface {
+	// WalletSubscribeEvents streams the events matching the filter until ctx is done.
+	// Lock and unlock events have no address, they are sent to the subscriptions filtering on addresses too.
+	WalletSubscribeEvents(ctx context.Context, filter *EventFilter) (<-chan WalletEvent, error)
+}
+
+type eventSub struct {
+	addresses map[address.Address]struct{}
+	types     map[EventType]struct{}
+	ch        chan WalletEvent
+	missed    uint64
+}
+
+func (s *eventSub) match(ev *WalletEvent) bool {
+	if len(s.types) > 0 {
+		if _, ok := s.types[ev.Type]; !ok {
+			return false
+		}
+	}
+	if len(s.addresses) > 0 && ev.Address != address.Undef {
+		if _, ok := s.addresses[ev.Address]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// eventHub fans the events out to the subscriptions, publishing never waits for a subscriber
+type eventHub struct {
+	lk   sync.Mutex
+	next uint64
+	subs map[uint64]*eventSub
+}
+
+func newEventHub() *eventHub {
+	return &eventHub{subs: make(map[uint64]*eventSub)}
+}
+
+func (h *eventHub) subscribe(ctx context.Context, filter *EventFilter) (<-chan WalletEvent, error) {
+	sub := &eventSub{
+		addresses: make(map[address.Address]struct{}),
+		types:     make(map[EventType]struct{}),
+		ch:        make(chan WalletEvent, eventBuffer),
+	}
+	if filter != nil {
+		for _, addr := range filter.Addresses {
+			sub.addresses[addr] = struct{}{}
+		}
+		for _, t := range filter.Types {
+			if !isEventType(t) {
+				return nil, fmt.Errorf("unsupported event type %s, supported: %v", t, EventTypes)
+			}
+			sub.types[t] = struct{}{}
+		}
+	}
+
+	h.lk.Lock()
+	id := h.next
+	h.next++
+	h.subs[id] = sub
+	h.lk.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		h.lk.Lock()
+		delete(h.subs, id)
+		close(sub.ch)
+		h.lk.Unlock()
+	}()
+	return sub.ch, nil
+}
+
+func (h *eventHub) publish(ev WalletEvent) {
+	if ev.Time.IsZero() {
+		ev.Time = time.Now()
+	}
+	h.lk.Lock()
+	defer h.lk.Unlock()
+	for _, sub := range h.subs {
+		if !sub.match(&ev) {
+			continue
+		}
+		ev.Missed = sub.missed
+		select {
+		case sub.ch <- ev:
+			sub.missed = 0
+		default:
+			sub.missed++
+		}
+	}
+}
+
+func isEventType(t EventType) bool {
+	for _, et := range EventTypes {
+		if et == t {
+			return true
+		}
+	}
+	return false
+}
+
+func (w *wallet) WalletSubscribeEvents(ctx context.Context, filter *EventFilter) (<-chan WalletEvent, error) {
+	return w.events.subscribe(ctx, filter)
+}
+
+// event publishes an event of the caller of ctx
+func (w *wallet) event(ctx context.Context, t EventType, addr address.Address, err error) {
+	ev := WalletEvent{Type: t, Address: addr, Caller: middleware.CallerFromContext(ctx)}
+	if err != nil {
+		ev.Err = err.Error()
+	}
+	w.events.publish(ev)
+}
+
+// signEvent publishes the outcome of a sign request
+func (w *wallet) signEvent(ctx context.Context, t EventType, req *signRequest, err error) {
+	ev := WalletEvent{Type: t, Address: req.signer, MsgType: req.meta.Type, Caller: middleware.CallerFromContext(ctx)}
+	ev.Summary, ev.MsgCID = signSummary(req.obj)
+	if err != nil {
+		ev.Err = err.Error()
+	}
+	w.events.publish(ev)
+}
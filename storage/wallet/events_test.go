@@ -0,0 +1,134 @@
+package wallet
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/venus/venus-shared/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func nextEvent(t *testing.T, events <-chan WalletEvent) WalletEvent {
+	select {
+	case ev := <-events:
+		return ev
+	case <-time.After(time.Second):
+		t.Fatal("no event")
+		return WalletEvent{}
+	}
+}
+
+func TestWallet_SubscribeEvents(t *testing.T) {
+	w, ctx := newTestWallet(t)
+
+	subCtx, cancel := context.WithCancel(ctx)
+	all, err := w.WalletSubscribeEvents(subCtx, nil)
+	assert.NoError(t, err)
+
+	addr, err := w.WalletNew(ctx, types.KTSecp256k1)
+	assert.NoError(t, err)
+	other, err := w.WalletNew(ctx, types.KTSecp256k1)
+	assert.NoError(t, err)
+	for _, want := range []address.Address{addr, other} {
+		ev := nextEvent(t, all)
+		assert.Equal(t, EventKeyAdd, ev.Type)
+		assert.Equal(t, want, ev.Address)
+	}
+
+	filtered, err := w.WalletSubscribeEvents(subCtx, &EventFilter{
+		Addresses: []address.Address{addr},
+		Types:     []EventType{EventSignSuccess, EventSignRejected, EventLock},
+	})
+	assert.NoError(t, err)
+
+	msg := &types.Message{From: addr, To: addr, Value: abi.NewTokenAmount(0)}
+	extra, err := msg.Serialize()
+	assert.NoError(t, err)
+	_, err = w.WalletSign(ctx, addr, msg.Cid().Bytes(), types.MsgMeta{Type: types.MTChainMsg, Extra: extra})
+	assert.NoError(t, err)
+	ev := nextEvent(t, filtered)
+	assert.Equal(t, EventSignSuccess, ev.Type)
+	assert.Equal(t, addr, ev.Address)
+	assert.Equal(t, types.MTChainMsg, ev.MsgType)
+	assert.Equal(t, msg.Cid().String(), ev.MsgCID)
+	assert.Empty(t, ev.Err)
+	assert.Equal(t, EventSignSuccess, nextEvent(t, all).Type)
+
+	assert.NoError(t, w.WalletDisable(ctx, addr))
+	_, err = w.WalletSign(ctx, addr, msg.Cid().Bytes(), types.MsgMeta{Type: types.MTChainMsg, Extra: extra})
+	assert.Error(t, err)
+	ev = nextEvent(t, filtered)
+	assert.Equal(t, EventSignRejected, ev.Type)
+	assert.NotEmpty(t, ev.Err)
+
+	assert.NoError(t, w.WalletDelete(ctx, other))
+	assert.NoError(t, w.Lock(ctx, "password"))
+	assert.NoError(t, w.Unlock(ctx, "password"))
+	// the key removal of the other address is filtered out, lock events have no address
+	assert.Equal(t, EventLock, nextEvent(t, filtered).Type)
+
+	var got []EventType
+	for len(got) < 4 {
+		got = append(got, nextEvent(t, all).Type)
+	}
+	assert.Equal(t, []EventType{EventSignRejected, EventKeyRemove, EventLock, EventUnlock}, got)
+
+	cancel()
+	_, ok := <-filtered
+	assert.False(t, ok)
+	_, ok = <-all
+	assert.False(t, ok)
+}
+
+func TestWallet_SignFailureEvents(t *testing.T) {
+	w, ctx := newTestWallet(t)
+	addr, err := w.WalletNew(ctx, types.KTSecp256k1)
+	assert.NoError(t, err)
+
+	subCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	events, err := w.WalletSubscribeEvents(subCtx, &EventFilter{Types: []EventType{EventSignFailure}})
+	assert.NoError(t, err)
+
+	// the data doesn't match the message
+	_, err = w.WalletSign(ctx, addr, []byte("data"), types.MsgMeta{Type: types.MTChainMsg, Extra: []byte("extra")})
+	assert.Error(t, err)
+	ev := nextEvent(t, events)
+	assert.Equal(t, addr, ev.Address)
+	assert.Equal(t, types.MTChainMsg, ev.MsgType)
+	assert.NotEmpty(t, ev.Err)
+
+	assert.NoError(t, w.Lock(ctx, "password"))
+	_, err = w.WalletSign(ctx, addr, []byte("data"), types.MsgMeta{Type: types.MTUnknown})
+	assert.Error(t, err)
+	ev = nextEvent(t, events)
+	assert.Equal(t, addr, ev.Address)
+	assert.NotEmpty(t, ev.Err)
+}
+
+func TestEventHub(t *testing.T) {
+	h := newEventHub()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	_, err := h.subscribe(ctx, &EventFilter{Types: []EventType{"unknown"}})
+	assert.Error(t, err)
+
+	events, err := h.subscribe(ctx, nil)
+	assert.NoError(t, err)
+	// publishing doesn't wait for a slow subscriber, it counts the dropped events instead
+	for i := 0; i < eventBuffer+3; i++ {
+		h.publish(WalletEvent{Type: EventLock})
+	}
+	for i := 0; i < eventBuffer; i++ {
+		assert.Zero(t, (<-events).Missed)
+	}
+	h.publish(WalletEvent{Type: EventUnlock})
+	ev := <-events
+	assert.Equal(t, EventUnlock, ev.Type)
+	assert.Equal(t, uint64(3), ev.Missed)
+	assert.False(t, ev.Time.IsZero())
+}
@@ -0,0 +1,57 @@
+package wallet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/venus/venus-shared/types"
+)
+
+var ErrExportForbidden = errors.New("export of the key is forbidden")
+
+// KeyOptions the settings of a key which can only be chosen when the key is created or imported
+type KeyOptions struct {
+	// NoExport the key can never be exported, the flag can't be cleared
+	NoExport bool
+}
+
+// IKeyExport creation and import of keys which can never leave the wallet
+type IKeyExport interface {
+	// WalletNewWithOptions generates a new key with the options
+	WalletNewWithOptions(ctx context.Context, kt types.KeyType, opts KeyOptions) (address.Address, error)
+	// WalletImportWithOptions imports the key with the options, NoExport is also applied to an existing key
+	WalletImportWithOptions(ctx context.Context, ki *types.KeyInfo, opts KeyOptions) (address.Address, error)
+}
+
+// checkExport fails when export is disabled in the config or for the key
+func (w *wallet) checkExport(addr address.Address) error {
+	if w.exportCfg != nil && w.exportCfg.Disable {
+		return fmt.Errorf("%w: disabled in config", ErrExportForbidden)
+	}
+	meta, err := w.meta.GetMeta(addr)
+	if err != nil {
+		return fmt.Errorf("get key meta: %w", err)
+	}
+	if meta.NoExport {
+		return fmt.Errorf("%w: %s", ErrExportForbidden, addr)
+	}
+	return nil
+}
+
+// applyKeyOptions is called before the key is stored, so that a key is never exportable by mistake
+func (w *wallet) applyKeyOptions(addr address.Address, opts KeyOptions) error {
+	if !opts.NoExport {
+		return nil
+	}
+	meta, err := w.meta.GetMeta(addr)
+	if err != nil {
+		return err
+	}
+	if meta.NoExport {
+		return nil
+	}
+	meta.NoExport = true
+	return w.meta.PutMeta(meta)
+}
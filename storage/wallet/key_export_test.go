@@ -0,0 +1,62 @@
+package wallet
+
+import (
+	"testing"
+
+	"github.com/filecoin-project/venus/venus-shared/types"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/filecoin-project/venus-wallet/config"
+	"github.com/filecoin-project/venus-wallet/crypto"
+	"github.com/filecoin-project/venus-wallet/storage"
+)
+
+func TestWallet_NoExport(t *testing.T) {
+	w, ctx := newTestWallet(t)
+
+	addr, err := w.WalletNew(ctx, types.KTSecp256k1)
+	assert.NoError(t, err)
+	_, err = w.WalletExport(ctx, addr)
+	assert.NoError(t, err)
+
+	locked, err := w.WalletNewWithOptions(ctx, types.KTSecp256k1, KeyOptions{NoExport: true})
+	assert.NoError(t, err)
+	_, err = w.WalletExport(ctx, locked)
+	assert.ErrorIs(t, err, ErrExportForbidden)
+
+	// changing the roles or disabling the key keeps the flag
+	assert.NoError(t, w.WalletSetRoles(ctx, locked, []storage.KeyRole{storage.RoleWorker}))
+	assert.NoError(t, w.WalletDisable(ctx, locked))
+	assert.NoError(t, w.WalletEnable(ctx, locked))
+	_, err = w.WalletExport(ctx, locked)
+	assert.ErrorIs(t, err, ErrExportForbidden)
+
+	// importing a known key with the flag sets it, importing it without doesn't clear it
+	prv, err := crypto.GeneratePrivateKey(types.KeyType2Sign(types.KTSecp256k1))
+	assert.NoError(t, err)
+	imported, err := w.WalletImport(ctx, prv.ToKeyInfo())
+	assert.NoError(t, err)
+	_, err = w.WalletExport(ctx, imported)
+	assert.NoError(t, err)
+	_, err = w.WalletImportWithOptions(ctx, prv.ToKeyInfo(), KeyOptions{NoExport: true})
+	assert.NoError(t, err)
+	_, err = w.WalletImport(ctx, prv.ToKeyInfo())
+	assert.NoError(t, err)
+	_, err = w.WalletExport(ctx, imported)
+	assert.ErrorIs(t, err, ErrExportForbidden)
+
+	metas, err := w.WalletListMeta(ctx)
+	assert.NoError(t, err)
+	noExport := 0
+	for _, m := range metas {
+		if m.NoExport {
+			noExport++
+		}
+	}
+	assert.Equal(t, 2, noExport)
+
+	// the config switch covers all the keys
+	w.exportCfg = &config.KeyExportConfig{Disable: true}
+	_, err = w.WalletExport(ctx, addr)
+	assert.ErrorIs(t, err, ErrExportForbidden)
+}
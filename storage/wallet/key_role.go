@@ -0,0 +1,135 @@
+package wallet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/venus/venus-shared/types"
+
+	"github.com/filecoin-project/venus-wallet/storage"
+)
+
+var ErrRoleNotAllowed = errors.New("msg type not allowed for the key roles")
+
+// roleMsgTypes the message types each role may sign.
+// verifyaddress is never restricted, as the gateway needs it to register every key.
+var roleMsgTypes = map[storage.KeyRole][]types.MsgType{
+	storage.RoleOwner:   {types.MTChainMsg},
+	storage.RoleWorker:  {types.MTChainMsg, types.MTBlock, types.MTDrawRandomParam, types.MTUnknown, types.MTF3},
+	storage.RoleControl: {types.MTChainMsg},
+	storage.RoleMarket: {types.MTChainMsg, types.MTDealProposal, types.MTClientDeal, types.MTSignedVoucher,
+		types.MTStorageAsk, types.MTAskResponse, types.MTNetWorkResponse, types.MTProviderDealState},
+	storage.RoleEthereum: {types.MTChainMsg},
+}
+
+func roleAllows(roles []storage.KeyRole, msgType types.MsgType) bool {
+	if len(roles) == 0 {
+		return true
+	}
+	for _, role := range roles {
+		for _, t := range roleMsgTypes[role] {
+			if t == msgType {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// IKeyRole manage the roles of the keys
+type IKeyRole interface {
+	// WalletSetRoles replaces the roles of the key, empty roles lift the restriction
+	WalletSetRoles(ctx context.Context, addr address.Address, roles []storage.KeyRole) error
+	// WalletListMeta list the keys with their roles
+	WalletListMeta(ctx context.Context) ([]storage.KeyMeta, error)
+}
+
+var _ ISignMsgFilter = &RolePolicy{}
+
+// RolePolicy rejects the message types the roles of the signer don't cover
+type RolePolicy struct {
+	store storage.IKeyMetaStore
+}
+
+func NewRolePolicy(store storage.IKeyMetaStore) *RolePolicy {
+	return &RolePolicy{store: store}
+}
+
+func (p *RolePolicy) Name() string {
+	return "key_role"
+}
+
+func (p *RolePolicy) CheckSignMsg(ctx context.Context, signMsg SignMsg) error {
+	meta, err := p.store.GetMeta(signMsg.Signer)
+	if err != nil {
+		return fmt.Errorf("get key meta: %w", err)
+	}
+	if !roleAllows(meta.Roles, signMsg.SignType) {
+		return fmt.Errorf("%w: %s with roles %v", ErrRoleNotAllowed, signMsg.SignType, meta.Roles)
+	}
+	return nil
+}
+
+func (w *wallet) WalletSetRoles(ctx context.Context, addr address.Address, roles []storage.KeyRole) error {
+	if err := w.mw.Next(); err != nil {
+		return err
+	}
+	if err := w.mw.CheckToken(ctx); err != nil {
+		return err
+	}
+	has, err := w.ws.Has(addr)
+	if err != nil {
+		return err
+	}
+	if !has {
+		return fmt.Errorf("%w: %s", storage.ErrKeyInfoNotFound, addr)
+	}
+
+	seen := make(map[storage.KeyRole]struct{}, len(roles))
+	uniq := make([]storage.KeyRole, 0, len(roles))
+	for _, role := range roles {
+		if _, err := storage.ParseKeyRole(string(role)); err != nil {
+			return err
+		}
+		if _, ok := seen[role]; !ok {
+			seen[role] = struct{}{}
+			uniq = append(uniq, role)
+		}
+	}
+	sort.Slice(uniq, func(i, j int) bool { return uniq[i] < uniq[j] })
+
+	meta, err := w.meta.GetMeta(addr)
+	if err != nil {
+		return err
+	}
+	meta.Roles = uniq
+	return w.meta.PutMeta(meta)
+}
+
+func (w *wallet) WalletListMeta(ctx context.Context) ([]storage.KeyMeta, error) {
+	addrs, err := w.ws.List()
+	if err != nil {
+		return nil, err
+	}
+	metas, err := w.meta.ListMeta()
+	if err != nil {
+		return nil, err
+	}
+	byAddr := make(map[address.Address]storage.KeyMeta, len(metas))
+	for _, m := range metas {
+		byAddr[m.Address] = m
+	}
+
+	ret := make([]storage.KeyMeta, 0, len(addrs))
+	for _, addr := range addrs {
+		meta, ok := byAddr[addr]
+		if !ok {
+			meta = storage.KeyMeta{Address: addr}
+		}
+		ret = append(ret, meta)
+	}
+	return ret, nil
+}
@@ -0,0 +1,69 @@
+package wallet
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/venus/venus-shared/types"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/filecoin-project/venus-wallet/storage"
+	walletsqlite "github.com/filecoin-project/venus-wallet/storage/sqlite"
+)
+
+func newTestKeyMetaStore(t *testing.T) storage.IKeyMetaStore {
+	db, err := gorm.Open(sqlite.Open(fmt.Sprintf("file:%s?mode=memory&cache=shared", t.Name())), &gorm.Config{})
+	assert.NoError(t, err)
+	store, err := walletsqlite.NewKeyMetaStore(db)
+	assert.NoError(t, err)
+	return store
+}
+
+func newTestPanicStore(t *testing.T) storage.IPanicStore {
+	db, err := gorm.Open(sqlite.Open(fmt.Sprintf("file:%s-panic?mode=memory&cache=shared", t.Name())), &gorm.Config{})
+	assert.NoError(t, err)
+	store, err := walletsqlite.NewPanicStore(db)
+	assert.NoError(t, err)
+	return store
+}
+
+func TestRolePolicy(t *testing.T) {
+	ctx := context.Background()
+	store := newTestKeyMetaStore(t)
+	policy := NewRolePolicy(store)
+
+	owner, err := address.NewIDAddress(1000)
+	assert.NoError(t, err)
+	worker, err := address.NewIDAddress(1001)
+	assert.NoError(t, err)
+	plain, err := address.NewIDAddress(1002)
+	assert.NoError(t, err)
+	assert.NoError(t, store.PutMeta(&storage.KeyMeta{Address: owner, Roles: []storage.KeyRole{storage.RoleOwner}}))
+	assert.NoError(t, store.PutMeta(&storage.KeyMeta{Address: worker, Roles: []storage.KeyRole{storage.RoleWorker}}))
+
+	check := func(signer address.Address, msgType types.MsgType) error {
+		return policy.CheckSignMsg(ctx, SignMsg{SignType: msgType, Signer: signer})
+	}
+	assert.NoError(t, check(owner, types.MTChainMsg))
+	assert.ErrorIs(t, check(owner, types.MTBlock), ErrRoleNotAllowed)
+	assert.NoError(t, check(worker, types.MTBlock))
+	assert.NoError(t, check(worker, types.MTChainMsg))
+	assert.ErrorIs(t, check(worker, types.MTDealProposal), ErrRoleNotAllowed)
+	assert.NoError(t, check(plain, types.MTDealProposal))
+
+	// the roles add up
+	assert.NoError(t, store.PutMeta(&storage.KeyMeta{Address: worker, Roles: []storage.KeyRole{storage.RoleMarket, storage.RoleWorker}}))
+	assert.NoError(t, check(worker, types.MTDealProposal))
+
+	metas, err := store.ListMeta()
+	assert.NoError(t, err)
+	assert.Len(t, metas, 2)
+	assert.NoError(t, store.DeleteMeta(owner))
+	meta, err := store.GetMeta(owner)
+	assert.NoError(t, err)
+	assert.Empty(t, meta.Roles)
+}
@@ -0,0 +1,78 @@
+package wallet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/filecoin-project/go-address"
+
+	"github.com/filecoin-project/venus-wallet/storage"
+)
+
+var ErrKeyDisabled = errors.New("key is disabled")
+
+// IKeySuspend suspend and resume signing of a key, without removing it from the keystore
+type IKeySuspend interface {
+	// WalletDisable makes the key refuse to sign, and removes it from the gateway
+	WalletDisable(ctx context.Context, addr address.Address) error
+	// WalletEnable lets the key sign again, and adds it back to the gateway
+	WalletEnable(ctx context.Context, addr address.Address) error
+}
+
+func (w *wallet) WalletDisable(ctx context.Context, addr address.Address) error {
+	if err := w.setDisabled(ctx, addr, true); err != nil {
+		return err
+	}
+	w.bus.Publish("wallet:remove_address", addr)
+	return nil
+}
+
+func (w *wallet) WalletEnable(ctx context.Context, addr address.Address) error {
+	if err := w.setDisabled(ctx, addr, false); err != nil {
+		return err
+	}
+	w.bus.Publish("wallet:add_address", addr)
+	return nil
+}
+
+func (w *wallet) setDisabled(ctx context.Context, addr address.Address, disabled bool) error {
+	if err := w.mw.Next(); err != nil {
+		return err
+	}
+	if err := w.mw.CheckToken(ctx); err != nil {
+		return err
+	}
+	has, err := w.ws.Has(addr)
+	if err != nil {
+		return err
+	}
+	if !has {
+		return fmt.Errorf("%w: %s", storage.ErrKeyInfoNotFound, addr)
+	}
+
+	meta, err := w.meta.GetMeta(addr)
+	if err != nil {
+		return err
+	}
+	if meta.Disabled == disabled {
+		if disabled {
+			return fmt.Errorf("key %s is already disabled", addr)
+		}
+		return fmt.Errorf("key %s is not disabled", addr)
+	}
+	meta.Disabled = disabled
+	return w.meta.PutMeta(meta)
+}
+
+// checkDisabled fails for the disabled keys
+func (w *wallet) checkDisabled(addr address.Address) error {
+	meta, err := w.meta.GetMeta(addr)
+	if err != nil {
+		return fmt.Errorf("get key meta: %w", err)
+	}
+	if meta.Disabled {
+		return fmt.Errorf("%w: %s", ErrKeyDisabled, addr)
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package wallet
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/asaskevich/EventBus"
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/venus/venus-shared/types"
+	"github.com/ipfs-force-community/sophon-auth/core"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/filecoin-project/venus-wallet/config"
+	"github.com/filecoin-project/venus-wallet/storage"
+	walletsqlite "github.com/filecoin-project/venus-wallet/storage/sqlite"
+)
+
+// newTestWallet a wallet with the password set, and a context with admin permission
+func newTestWallet(t *testing.T) (*wallet, context.Context) {
+	db, err := gorm.Open(sqlite.Open(fmt.Sprintf("file:%s-wallet?mode=memory&cache=shared", t.Name())), &gorm.Config{})
+	assert.NoError(t, err)
+	assert.NoError(t, db.AutoMigrate(&walletsqlite.Wallet{}))
+
+	limiter, err := NewRateLimiter(&config.RateLimitConfig{})
+	assert.NoError(t, err)
+	blind, err := NewBlindSignPolicy(&config.BlindSignConfig{})
+	assert.NoError(t, err)
+	meta := newTestKeyMetaStore(t)
+	mw := storage.NewKeyMiddleware(&config.CryptoFactor{ScryptN: 1 << 2, ScryptP: 1})
+	recorder := &walletsqlite.RecorderStub{}
+	records := newRecordQueue(recorder, &recordQueueConfig{size: 16, batchSize: 4, interval: time.Millisecond})
+	t.Cleanup(func() { assert.NoError(t, records.Close(context.Background())) })
+	w := NewWallet(WalletParams{
+		KeyStore:   walletsqlite.NewKeyStore(db),
+		Recorder:   recorder,
+		Records:    records,
+		Middleware: mw,
+		Filter:     FilterChain{NewRolePolicy(meta)},
+		Approval:   newTestApprovalQueue(t, &config.ApprovalConfig{}),
+		Quorum:     newTestQuorumQueue(t, &config.QuorumConfig{}),
+		Limiter:    limiter,
+		BlindSign:  blind,
+		KeyMeta:    meta,
+		Panics:     newTestPanicStore(t),
+		TOTP:       newTestTOTPStore(t),
+		ExportCfg:  &config.KeyExportConfig{},
+		Bus:        EventBus.New(),
+		GetPwd:     func() string { return "password" },
+	})
+
+	ctx := core.CtxWithPerms(context.Background(), core.AdaptOldStrategy(core.PermAdmin))
+	return w.(*wallet), ctx
+}
+
+func TestWallet_Disable(t *testing.T) {
+	w, ctx := newTestWallet(t)
+
+	addr, err := w.WalletNew(ctx, types.KTSecp256k1)
+	assert.NoError(t, err)
+
+	var removed, added []address.Address
+	assert.NoError(t, w.bus.Subscribe("wallet:remove_address", func(addr address.Address) { removed = append(removed, addr) }))
+	assert.NoError(t, w.bus.Subscribe("wallet:add_address", func(addr address.Address) { added = append(added, addr) }))
+
+	msg := &types.Message{From: addr, To: addr, Value: abi.NewTokenAmount(0)}
+	extra, err := msg.Serialize()
+	assert.NoError(t, err)
+	sign := func() error {
+		_, err := w.WalletSign(ctx, addr, msg.Cid().Bytes(), types.MsgMeta{Type: types.MTChainMsg, Extra: extra})
+		return err
+	}
+	assert.NoError(t, sign())
+
+	assert.NoError(t, w.WalletDisable(ctx, addr))
+	assert.Error(t, w.WalletDisable(ctx, addr))
+	assert.ErrorIs(t, sign(), ErrKeyDisabled)
+	has, err := w.WalletHas(ctx, addr)
+	assert.NoError(t, err)
+	assert.True(t, has)
+
+	metas, err := w.WalletListMeta(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, metas, 1)
+	assert.True(t, metas[0].Disabled)
+
+	assert.NoError(t, w.WalletEnable(ctx, addr))
+	assert.NoError(t, sign())
+	assert.Equal(t, []address.Address{addr}, removed)
+	assert.Equal(t, []address.Address{addr}, added)
+
+	// disabling doesn't need the key to be decrypted, and survives the roles change
+	assert.NoError(t, w.WalletDisable(ctx, addr))
+	assert.NoError(t, w.WalletSetRoles(ctx, addr, []storage.KeyRole{storage.RoleWorker}))
+	assert.ErrorIs(t, sign(), ErrKeyDisabled)
+
+	unknown, err := address.NewIDAddress(1000)
+	assert.NoError(t, err)
+	assert.ErrorIs(t, w.WalletDisable(ctx, unknown), storage.ErrKeyInfoNotFound)
+}
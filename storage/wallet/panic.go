@@ -0,0 +1,96 @@
+package wallet
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/filecoin-project/venus-wallet/crypto"
+	"github.com/filecoin-project/venus-wallet/middleware"
+	"github.com/filecoin-project/venus-wallet/storage"
+)
+
+// IPanic the emergency lock of the wallet
+type IPanic interface {
+	// WalletPanicLock locks the wallet at once and purges the decrypted keys, every sign request is rejected
+	// until unlocked with the password. The keys are removed from the gateway, so that the sealers stop retrying.
+	// revokeTokens rejects all the tokens without admin permission created so far.
+	WalletPanicLock(ctx context.Context, reason string, revokeTokens bool) error
+	// WalletPanicList lists the panic locks, latest first
+	WalletPanicList(ctx context.Context) ([]storage.PanicEvent, error)
+}
+
+func (w *wallet) WalletPanicLock(ctx context.Context, reason string, revokeTokens bool) (err error) {
+	defer func() {
+		w.auditDetail(ctx, storage.AdminPanicLock, "", fmt.Sprintf("reason %q, revoke tokens %t", reason, revokeTokens), err)
+	}()
+	w.mw.PanicLock()
+	w.m.Lock()
+	w.keyCache = make(map[string]crypto.PrivateKey)
+	w.m.Unlock()
+	w.panicked.Store(true)
+
+	caller := middleware.CallerFromContext(ctx)
+	w.events.publish(WalletEvent{Type: EventLock, Summary: "panic lock: " + reason, Caller: caller})
+	log.Errorf("wallet panic locked by %s: %s, revoke tokens: %t", callerName(caller), reason, revokeTokens)
+
+	// the wallet is already locked, failing to record must not undo it
+	var recordErr error
+	if err := w.panics.PutPanicEvent(&storage.PanicEvent{
+		ID:           uuid.New().String(),
+		CreateAt:     time.Now(),
+		Reason:       reason,
+		RevokeTokens: revokeTokens,
+		Caller:       caller,
+	}); err != nil {
+		log.Errorf("record panic lock: %v", err)
+		recordErr = fmt.Errorf("wallet locked, but record panic lock failed: %w", err)
+	}
+
+	addrs, err := w.ws.List()
+	if err != nil {
+		log.Errorf("list keys to remove from gateway: %v", err)
+		return recordErr
+	}
+	for _, addr := range addrs {
+		w.bus.Publish("wallet:remove_address", addr)
+	}
+	return recordErr
+}
+
+func (w *wallet) WalletPanicList(ctx context.Context) ([]storage.PanicEvent, error) {
+	return w.panics.ListPanicEvents()
+}
+
+// panicRecovered adds the keys back to the gateway after the unlock of a panic lock
+func (w *wallet) panicRecovered() {
+	if !w.panicked.CompareAndSwap(true, false) {
+		return
+	}
+	log.Warn("wallet unlocked after panic lock")
+	addrs, err := w.ws.List()
+	if err != nil {
+		log.Errorf("list keys to add to gateway: %v", err)
+		return
+	}
+	for _, addr := range addrs {
+		if err := w.checkDisabled(addr); err != nil {
+			continue
+		}
+		w.bus.Publish("wallet:add_address", addr)
+	}
+}
+
+func callerName(caller *middleware.Caller) string {
+	switch {
+	case caller == nil:
+		return "signal"
+	case caller.Name != "":
+		return caller.Name
+	case caller.RemoteAddr != "":
+		return caller.RemoteAddr
+	}
+	return "local"
+}
@@ -0,0 +1,75 @@
+package wallet
+
+import (
+	"testing"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/venus/venus-shared/types"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/filecoin-project/venus-wallet/storage"
+)
+
+func TestWallet_PanicLock(t *testing.T) {
+	w, ctx := newTestWallet(t)
+	setTestRecorder(t, w)
+
+	addr, err := w.WalletNew(ctx, types.KTSecp256k1)
+	assert.NoError(t, err)
+	disabled, err := w.WalletNew(ctx, types.KTSecp256k1)
+	assert.NoError(t, err)
+	assert.NoError(t, w.WalletDisable(ctx, disabled))
+
+	var removed, added []address.Address
+	assert.NoError(t, w.bus.Subscribe("wallet:remove_address", func(addr address.Address) { removed = append(removed, addr) }))
+	assert.NoError(t, w.bus.Subscribe("wallet:add_address", func(addr address.Address) { added = append(added, addr) }))
+
+	msg := &types.Message{From: addr, To: addr, Value: abi.NewTokenAmount(0)}
+	extra, err := msg.Serialize()
+	assert.NoError(t, err)
+	sign := func() error {
+		_, err := w.WalletSign(ctx, addr, msg.Cid().Bytes(), types.MsgMeta{Type: types.MTChainMsg, Extra: extra})
+		return err
+	}
+	assert.NoError(t, sign())
+	assert.NotNil(t, w.cacheKey(addr))
+
+	assert.NoError(t, w.WalletPanicLock(ctx, "key leaked", true))
+	assert.ErrorIs(t, sign(), storage.ErrPanicLocked)
+	assert.Nil(t, w.cacheKey(addr))
+	assert.True(t, w.LockState(ctx))
+	assert.ElementsMatch(t, []address.Address{addr, disabled}, removed)
+
+	events, err := w.WalletPanicList(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, events, 1)
+	assert.Equal(t, "key leaked", events[0].Reason)
+	assert.True(t, events[0].RevokeTokens)
+
+	assert.Error(t, w.Unlock(ctx, "wrong"))
+	assert.ErrorIs(t, sign(), storage.ErrPanicLocked)
+	assert.Empty(t, added)
+
+	assert.NoError(t, w.Unlock(ctx, "password"))
+	assert.NoError(t, sign())
+	// the disabled key stays off the gateway
+	assert.Equal(t, []address.Address{addr}, added)
+
+	// the lock and the unlock attempts are in the admin records, latest first
+	records, err := w.recorder.QueryAdminRecord(&storage.AdminQueryParams{Op: storage.AdminPanicUnlock})
+	assert.NoError(t, err)
+	assert.Len(t, records, 2)
+	assert.Empty(t, records[0].Err)
+	assert.NotEmpty(t, records[1].Err)
+	records, err = w.recorder.QueryAdminRecord(&storage.AdminQueryParams{Op: storage.AdminPanicLock})
+	assert.NoError(t, err)
+	assert.Len(t, records, 1)
+	assert.Contains(t, records[0].Detail, "key leaked")
+	// unlocking a wallet which isn't panic locked isn't one
+	assert.NoError(t, w.Lock(ctx, "password"))
+	assert.NoError(t, w.Unlock(ctx, "password"))
+	records, err = w.recorder.QueryAdminRecord(&storage.AdminQueryParams{Op: storage.AdminPanicUnlock})
+	assert.NoError(t, err)
+	assert.Len(t, records, 2)
+}
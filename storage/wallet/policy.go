@@ -0,0 +1,145 @@
+package wallet
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/venus/venus-shared/types"
+
+	"github.com/filecoin-project/venus-wallet/middleware"
+)
+
+type PolicyDecision string
+
+const (
+	PolicyAllow    PolicyDecision = "allow"
+	PolicyReject   PolicyDecision = "reject"
+	PolicyApproval PolicyDecision = "approval"
+)
+
+// PolicyCheck the outcome of one stage of the sign pipeline
+type PolicyCheck struct {
+	Name string
+	// Matched the stage rejects the request, or requires an approval
+	Matched bool
+	// NotEvaluated the stage depends on what isn't known to the test, Detail tells what was skipped
+	NotEvaluated bool `json:",omitempty"`
+	Detail       string
+}
+
+// PolicyResult the outcome of running a sign request through the sign pipeline without signing
+type PolicyResult struct {
+	Decision PolicyDecision
+	Checks   []PolicyCheck
+}
+
+func (r *PolicyResult) add(name string, err error) {
+	check := PolicyCheck{Name: name}
+	if err != nil {
+		check.Matched = true
+		check.Detail = err.Error()
+		r.Decision = PolicyReject
+	}
+	r.Checks = append(r.Checks, check)
+}
+
+// PolicyCaller the caller a policy test runs the request as
+type PolicyCaller struct {
+	// Token the jwt token of the caller, TokenID is derived from it if set
+	Token string
+	// TokenID the token id as kept in the sign records
+	TokenID string
+	Name    string
+}
+
+func (c *PolicyCaller) caller() *middleware.Caller {
+	caller := &middleware.Caller{TokenID: c.TokenID, Name: c.Name}
+	if c.Token != "" {
+		caller.TokenID = middleware.TokenID(c.Token)
+	}
+	return caller
+}
+
+// IPolicy dry-run of the sign policies
+type IPolicy interface {
+	// PolicyTest runs the sign request through rate limits, filters and approval rules without signing it.
+	// The request is run as the caller if given, else the rate limits of a token are reported as not evaluated.
+	// The filters and the sign plugin get it with DryRun set, so that they don't act on it.
+	PolicyTest(ctx context.Context, signer address.Address, toSign []byte, meta types.MsgMeta, caller *PolicyCaller) (*PolicyResult, error)
+}
+
+type namedFilter interface {
+	Name() string
+}
+
+func filterName(filter ISignMsgFilter) string {
+	if named, ok := filter.(namedFilter); ok {
+		return named.Name()
+	}
+	return fmt.Sprintf("%T", filter)
+}
+
+func (w *wallet) PolicyTest(ctx context.Context, signer address.Address, toSign []byte, meta types.MsgMeta, caller *PolicyCaller) (*PolicyResult, error) {
+	res := &PolicyResult{Decision: PolicyAllow}
+	tokenID := ""
+	if caller != nil {
+		ctx = middleware.WithCaller(ctx, caller.caller())
+		tokenID = middleware.CallerFromContext(ctx).TokenID
+	}
+
+	signObj, _, err := w.parseSignMsg(signer, toSign, meta)
+	res.add("parse", err)
+	if err != nil {
+		return res, nil
+	}
+	err = w.checkKeyExists(signer)
+	res.add("key", err)
+	if err != nil {
+		return res, nil
+	}
+
+	res.add("key_disabled", w.checkDisabled(signer))
+	skipped, err := w.limiter.Check(signer, tokenID, meta.Type)
+	res.add("rate_limit", err)
+	if len(skipped) > 0 {
+		res.Checks = append(res.Checks, PolicyCheck{
+			Name:         "rate_limit_token",
+			NotEvaluated: true,
+			Detail:       fmt.Sprintf("no caller token given for %s", strings.Join(skipped, ", ")),
+		})
+	}
+
+	if meta.Type == types.MTVerifyAddress {
+		return res, nil
+	}
+
+	signMsg := SignMsg{
+		SignType: meta.Type,
+		Signer:   signer,
+		Data:     signObj,
+		Caller:   middleware.CallerFromContext(ctx),
+		DryRun:   true,
+	}
+	// run all the filters, so that every matched rule is reported
+	filters, ok := w.filter.(FilterChain)
+	if !ok {
+		filters = FilterChain{w.filter}
+	}
+	for _, filter := range filters {
+		res.add(filterName(filter), filter.CheckSignMsg(ctx, signMsg))
+	}
+
+	check := PolicyCheck{Name: "approval"}
+	if reason := w.approval.Match(signer, signMsg); reason != "" {
+		check.Matched = true
+		check.Detail = reason
+		if res.Decision == PolicyAllow {
+			res.Decision = PolicyApproval
+		}
+	}
+	res.Checks = append(res.Checks, check)
+
+	return res, nil
+}
@@ -0,0 +1,110 @@
+package wallet
+
+import (
+	"context"
+	"testing"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/venus/venus-shared/types"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/filecoin-project/venus-wallet/config"
+	"github.com/filecoin-project/venus-wallet/middleware"
+)
+
+// dryRunFilter keeps the requests it was given
+type dryRunFilter struct {
+	msgs []SignMsg
+}
+
+func (f *dryRunFilter) CheckSignMsg(_ context.Context, signMsg SignMsg) error {
+	f.msgs = append(f.msgs, signMsg)
+	return nil
+}
+
+func TestWallet_PolicyTest(t *testing.T) {
+	w, ctx := newTestWallet(t)
+	from, err := w.WalletNew(ctx, types.KTSecp256k1)
+	assert.NoError(t, err)
+	to, err := address.NewIDAddress(1001)
+	assert.NoError(t, err)
+
+	limiter, err := NewRateLimiter(&config.RateLimitConfig{
+		Enable: true,
+		Rules: []config.RateLimitRule{
+			{Signer: from.String(), Limit: 1, Interval: "1h"},
+			{Token: "market-token", Limit: 1, Interval: "1h"},
+		},
+	})
+	assert.NoError(t, err)
+	blind, err := NewBlindSignPolicy(&config.BlindSignConfig{})
+	assert.NoError(t, err)
+	dryRun := &dryRunFilter{}
+	w.filter = FilterChain{blind, dryRun}
+	w.limiter = limiter
+	w.blind = blind
+	w.approval = newTestApprovalQueue(t, &config.ApprovalConfig{Enable: true, Methods: []uint64{23}})
+
+	msg := &types.Message{From: from, To: to, Method: 23, Value: abi.NewTokenAmount(0)}
+	extra, err := msg.Serialize()
+	assert.NoError(t, err)
+	meta := types.MsgMeta{Type: types.MTChainMsg, Extra: extra}
+
+	res, err := w.PolicyTest(ctx, from, msg.Cid().Bytes(), meta, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, PolicyApproval, res.Decision)
+	// the filters are told the request is never signed
+	assert.Len(t, dryRun.msgs, 1)
+	assert.True(t, dryRun.msgs[0].DryRun)
+	// dry run takes no token from the buckets
+	res, err = w.PolicyTest(ctx, from, msg.Cid().Bytes(), meta, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, PolicyApproval, res.Decision)
+
+	res, err = w.PolicyTest(ctx, to, msg.Cid().Bytes(), meta, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, PolicyReject, res.Decision)
+	assert.Equal(t, "parse", res.Checks[0].Name)
+
+	// a key the wallet doesn't hold is never allowed
+	toMsg := &types.Message{From: to, To: from, Value: abi.NewTokenAmount(0)}
+	toExtra, err := toMsg.Serialize()
+	assert.NoError(t, err)
+	res, err = w.PolicyTest(ctx, to, toMsg.Cid().Bytes(), types.MsgMeta{Type: types.MTChainMsg, Extra: toExtra}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, PolicyReject, res.Decision)
+	assert.Equal(t, "key", res.Checks[len(res.Checks)-1].Name)
+	assert.True(t, res.Checks[len(res.Checks)-1].Matched)
+
+	res, err = w.PolicyTest(ctx, from, []byte("hello"), types.MsgMeta{Type: types.MTUnknown}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, PolicyReject, res.Decision)
+	for _, c := range res.Checks {
+		assert.Equal(t, c.Name == "blind_sign", c.Matched, c.Name)
+	}
+
+	// the rules of a token are evaluated for the caller given
+	checks := func(res *PolicyResult) map[string]PolicyCheck {
+		m := map[string]PolicyCheck{}
+		for _, c := range res.Checks {
+			m[c.Name] = c
+		}
+		return m
+	}
+	res, err = w.PolicyTest(ctx, from, msg.Cid().Bytes(), meta, nil)
+	assert.NoError(t, err)
+	assert.True(t, checks(res)["rate_limit_token"].NotEvaluated)
+
+	market := middleware.WithCaller(ctx, &middleware.Caller{TokenID: middleware.TokenID("market-token")})
+	assert.NoError(t, limiter.Allow(market, to, types.MTChainMsg))
+	res, err = w.PolicyTest(ctx, from, msg.Cid().Bytes(), meta, &PolicyCaller{Token: "market-token"})
+	assert.NoError(t, err)
+	assert.Equal(t, PolicyReject, res.Decision)
+	assert.True(t, checks(res)["rate_limit"].Matched)
+	assert.NotContains(t, checks(res), "rate_limit_token")
+
+	res, err = w.PolicyTest(ctx, from, msg.Cid().Bytes(), meta, &PolicyCaller{TokenID: middleware.TokenID("other-token")})
+	assert.NoError(t, err)
+	assert.Equal(t, PolicyApproval, res.Decision)
+}
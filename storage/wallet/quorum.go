@@ -0,0 +1,296 @@
+package wallet
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/filecoin-project/venus-wallet/config"
+	"github.com/filecoin-project/venus-wallet/middleware"
+	"github.com/filecoin-project/venus-wallet/storage"
+)
+
+var (
+	ErrQuorumRejected       = errors.New("operation rejected by the second admin")
+	ErrQuorumTimeout        = errors.New("operation not confirmed in time")
+	ErrQuorumSameCredential = errors.New("the operation must be confirmed by another admin")
+	ErrQuorumNoCredential   = errors.New("quorum operations require a token")
+	ErrQuorumNotApprover    = errors.New("the operation must be confirmed with the token of an approver")
+	ErrQuorumTokenTooNew    = errors.New("the operation must be confirmed with a token created before the request")
+)
+
+// IQuorum manage the operations waiting for the confirmation of a second admin
+type IQuorum interface {
+	// QuorumList list the quorum requests in the given state, all of them if state is empty
+	QuorumList(ctx context.Context, state storage.QuorumState) ([]storage.QuorumRequest, error)
+	// QuorumGet get a quorum request by id
+	QuorumGet(ctx context.Context, id string) (*storage.QuorumRequest, error)
+	// QuorumConfirm let the waiting operation go on, the token must be named after an approver other than the requester,
+	// and be created before the request
+	QuorumConfirm(ctx context.Context, id string, comment string) error
+	// QuorumReject fail the waiting operation
+	QuorumReject(ctx context.Context, id string, comment string) error
+	// QuorumAudit the audit trail of the request, of all the requests if id is empty
+	QuorumAudit(ctx context.Context, id string) ([]storage.QuorumAudit, error)
+}
+
+var _ IQuorum = &QuorumQueue{}
+
+type quorumRules struct {
+	enable    bool
+	timeout   time.Duration
+	ops       map[storage.QuorumOp]struct{}
+	approvers map[string]struct{}
+}
+
+func parseQuorumRules(cfg *config.QuorumConfig) (*quorumRules, error) {
+	rules := &quorumRules{
+		timeout:   10 * time.Minute,
+		ops:       make(map[storage.QuorumOp]struct{}),
+		approvers: make(map[string]struct{}),
+	}
+	if cfg == nil {
+		return rules, nil
+	}
+	rules.enable = cfg.Enable
+	if cfg.Timeout != "" {
+		d, err := time.ParseDuration(cfg.Timeout)
+		if err != nil {
+			return nil, fmt.Errorf("parse quorum timeout: %w", err)
+		}
+		rules.timeout = d
+	}
+	ops := cfg.Operations
+	if len(ops) == 0 {
+		for _, op := range storage.QuorumOps {
+			ops = append(ops, string(op))
+		}
+	}
+	for _, s := range ops {
+		op, err := storage.ParseQuorumOp(s)
+		if err != nil {
+			return nil, err
+		}
+		rules.ops[op] = struct{}{}
+	}
+	for _, id := range cfg.Approvers {
+		if b, err := hex.DecodeString(id); err != nil || len(b) != sha256.Size {
+			return nil, fmt.Errorf("quorum approver %q isn't a token id", id)
+		}
+		rules.approvers[id] = struct{}{}
+	}
+	// a second token of the same admin mustn't be enough, the approvers tell the admins apart
+	if rules.enable && len(rules.approvers) == 0 {
+		return nil, fmt.Errorf("quorum requires approvers")
+	}
+	return rules, nil
+}
+
+// QuorumQueue parks the key revealing or destructive operations until a second admin confirms them.
+// Every step is written to the audit trail.
+type QuorumQueue struct {
+	rules   atomic.Pointer[quorumRules]
+	store   storage.IQuorumStore
+	lk      sync.Mutex
+	waiters map[string]chan storage.QuorumState
+}
+
+func NewQuorumQueue(cfg *config.QuorumConfig, store storage.IQuorumStore) (*QuorumQueue, error) {
+	rules, err := parseQuorumRules(cfg)
+	if err != nil {
+		return nil, err
+	}
+	q := &QuorumQueue{
+		store:   store,
+		waiters: make(map[string]chan storage.QuorumState),
+	}
+	// nobody is waiting for the requests left from the last run
+	ids, err := store.ExpirePending()
+	if err != nil {
+		return nil, fmt.Errorf("expire pending quorum requests: %w", err)
+	}
+	for _, id := range ids {
+		q.audit(id, storage.QuorumActionExpire, nil, "wallet restarted", nil)
+	}
+	q.rules.Store(rules)
+	return q, nil
+}
+
+// Reload validates the new config, the returned function applies it.
+// Operations already waiting keep the timeout they started with.
+func (q *QuorumQueue) Reload(cfg *config.QuorumConfig) (func(), error) {
+	rules, err := parseQuorumRules(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return func() { q.rules.Store(rules) }, nil
+}
+
+// Wait parks the operation and blocks until a second admin confirms it, it fails on rejection or timeout.
+// The returned function writes the outcome of the operation to the audit trail.
+// Requests without caller come from within the process, eg. the password given on the command line, they never wait.
+func (q *QuorumQueue) Wait(ctx context.Context, op storage.QuorumOp, target string) (func(error), error) {
+	rules := q.rules.Load()
+	caller := middleware.CallerFromContext(ctx)
+	if _, ok := rules.ops[op]; !ok || !rules.enable || caller == nil {
+		return func(error) {}, nil
+	}
+	if caller.TokenID == "" {
+		return nil, ErrQuorumNoCredential
+	}
+
+	now := time.Now()
+	req := &storage.QuorumRequest{
+		ID:        uuid.New().String(),
+		Op:        op,
+		Target:    target,
+		Requester: caller,
+		State:     storage.QuorumPending,
+		CreateAt:  now,
+		Deadline:  now.Add(rules.timeout),
+	}
+
+	ch := make(chan storage.QuorumState, 1)
+	q.lk.Lock()
+	q.waiters[req.ID] = ch
+	q.lk.Unlock()
+	defer func() {
+		q.lk.Lock()
+		delete(q.waiters, req.ID)
+		q.lk.Unlock()
+	}()
+
+	if err := q.store.Put(req); err != nil {
+		return nil, fmt.Errorf("park %s: %w", op, err)
+	}
+	q.audit(req.ID, storage.QuorumActionRequest, caller, target, nil)
+	log.Warnf("%s %s waits for the confirmation of a second admin, request %s", op, target, req.ID)
+
+	finish := func(err error) {
+		q.audit(req.ID, storage.QuorumActionExecute, caller, target, err)
+	}
+	decided := func(state storage.QuorumState) (func(error), error) {
+		if state == storage.QuorumConfirmed {
+			return finish, nil
+		}
+		return nil, fmt.Errorf("%w: %s", ErrQuorumRejected, req.ID)
+	}
+
+	timer := time.NewTimer(rules.timeout)
+	defer timer.Stop()
+
+	select {
+	case state := <-ch:
+		return decided(state)
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+
+	// the decision may race with the timeout, the store keeps the first one
+	q.lk.Lock()
+	err := q.store.Decide(req.ID, storage.QuorumExpired, nil, "")
+	q.lk.Unlock()
+	if err != nil {
+		select {
+		case state := <-ch:
+			return decided(state)
+		default:
+		}
+		log.Warnf("expire quorum request %s: %v", req.ID, err)
+	}
+	q.audit(req.ID, storage.QuorumActionExpire, nil, "", ctx.Err())
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	return nil, fmt.Errorf("%w: %s", ErrQuorumTimeout, req.ID)
+}
+
+func (q *QuorumQueue) QuorumList(ctx context.Context, state storage.QuorumState) ([]storage.QuorumRequest, error) {
+	return q.store.List(state)
+}
+
+func (q *QuorumQueue) QuorumGet(ctx context.Context, id string) (*storage.QuorumRequest, error) {
+	return q.store.Get(id)
+}
+
+func (q *QuorumQueue) QuorumConfirm(ctx context.Context, id string, comment string) error {
+	return q.decide(ctx, id, storage.QuorumConfirmed, comment)
+}
+
+func (q *QuorumQueue) QuorumReject(ctx context.Context, id string, comment string) error {
+	return q.decide(ctx, id, storage.QuorumRejected, comment)
+}
+
+func (q *QuorumQueue) QuorumAudit(ctx context.Context, id string) ([]storage.QuorumAudit, error) {
+	return q.store.ListAudit(id)
+}
+
+func (q *QuorumQueue) decide(ctx context.Context, id string, state storage.QuorumState, comment string) error {
+	action := storage.QuorumActionConfirm
+	if state == storage.QuorumRejected {
+		action = storage.QuorumActionReject
+	}
+	caller := middleware.CallerFromContext(ctx)
+	err := q.checkDecider(id, state, caller)
+	if err == nil {
+		q.lk.Lock()
+		if err = q.store.Decide(id, state, caller, comment); err == nil {
+			if ch, ok := q.waiters[id]; ok {
+				ch <- state
+			}
+		}
+		q.lk.Unlock()
+	}
+	// the refused attempts are part of the trail too
+	q.audit(id, action, caller, comment, err)
+	return err
+}
+
+// checkDecider only an approver other than the requester may confirm, anyone with an admin token may reject,
+// including the requester. The approvers are token ids, as any admin can create a token with any name.
+func (q *QuorumQueue) checkDecider(id string, state storage.QuorumState, caller *middleware.Caller) error {
+	if caller == nil || caller.TokenID == "" {
+		return ErrQuorumNoCredential
+	}
+	req, err := q.store.Get(id)
+	if err != nil {
+		return err
+	}
+	if state != storage.QuorumConfirmed {
+		return nil
+	}
+	if req.Requester != nil && (req.Requester.TokenID == caller.TokenID || (req.Requester.Name != "" && req.Requester.Name == caller.Name)) {
+		return ErrQuorumSameCredential
+	}
+	if _, ok := q.rules.Load().approvers[caller.TokenID]; !ok {
+		return fmt.Errorf("%w: token %s", ErrQuorumNotApprover, caller.TokenID)
+	}
+	if caller.IssuedAt == 0 || time.Unix(caller.IssuedAt, 0).After(req.CreateAt) {
+		return ErrQuorumTokenTooNew
+	}
+	return nil
+}
+
+func (q *QuorumQueue) audit(id string, action storage.QuorumAction, caller *middleware.Caller, comment string, err error) {
+	audit := &storage.QuorumAudit{
+		ID:        uuid.New().String(),
+		RequestID: id,
+		Action:    action,
+		Caller:    caller,
+		Comment:   comment,
+		CreateAt:  time.Now(),
+	}
+	if err != nil {
+		audit.Err = err.Error()
+	}
+	if err := q.store.AddAudit(audit); err != nil {
+		log.Errorf("write quorum audit %s %s: %v", id, action, err)
+	}
+}
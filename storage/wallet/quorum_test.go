@@ -0,0 +1,151 @@
+package wallet
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/filecoin-project/venus/venus-shared/types"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/filecoin-project/venus-wallet/config"
+	"github.com/filecoin-project/venus-wallet/middleware"
+	"github.com/filecoin-project/venus-wallet/storage"
+	walletsqlite "github.com/filecoin-project/venus-wallet/storage/sqlite"
+)
+
+func newTestQuorumQueue(t *testing.T, cfg *config.QuorumConfig) *QuorumQueue {
+	db, err := gorm.Open(sqlite.Open(fmt.Sprintf("file:%s-quorum?mode=memory&cache=shared", t.Name())), &gorm.Config{})
+	assert.NoError(t, err)
+	store, err := walletsqlite.NewQuorumStore(db)
+	assert.NoError(t, err)
+	q, err := NewQuorumQueue(cfg, store)
+	assert.NoError(t, err)
+	return q
+}
+
+func waitQuorumPending(t *testing.T, q *QuorumQueue) storage.QuorumRequest {
+	var reqs []storage.QuorumRequest
+	assert.Eventually(t, func() bool {
+		var err error
+		reqs, err = q.QuorumList(context.Background(), storage.QuorumPending)
+		return err == nil && len(reqs) == 1
+	}, time.Second, 10*time.Millisecond)
+	return reqs[0]
+}
+
+func TestWallet_Quorum(t *testing.T) {
+	aliceToken, bobToken, daveToken := middleware.TokenID("alice"), middleware.TokenID("bob"), middleware.TokenID("dave")
+	q := newTestQuorumQueue(t, &config.QuorumConfig{Enable: true, Timeout: "1m", Operations: []string{"export", "delete"},
+		Approvers: []string{aliceToken, bobToken, daveToken}})
+	w, adminCtx := newTestWallet(t)
+	w.quorum = q
+	addr, err := w.WalletNew(adminCtx, types.KTSecp256k1)
+	assert.NoError(t, err)
+
+	issuedAt := time.Now().Add(-time.Hour).Unix()
+	alice := middleware.WithCaller(adminCtx, &middleware.Caller{TokenID: aliceToken, Name: "alice", IssuedAt: issuedAt})
+	bob := middleware.WithCaller(adminCtx, &middleware.Caller{TokenID: bobToken, Name: "bob", IssuedAt: issuedAt})
+
+	t.Run("confirm", func(t *testing.T) {
+		type result struct {
+			ki  *types.KeyInfo
+			err error
+		}
+		done := make(chan result, 1)
+		go func() {
+			ki, err := w.WalletExport(alice, addr)
+			done <- result{ki, err}
+		}()
+
+		req := waitQuorumPending(t, q)
+		assert.Equal(t, storage.QuorumExport, req.Op)
+		assert.Equal(t, addr.String(), req.Target)
+		assert.Equal(t, aliceToken, req.Requester.TokenID)
+
+		assert.ErrorIs(t, q.QuorumConfirm(alice, req.ID, ""), ErrQuorumSameCredential)
+		assert.ErrorIs(t, q.QuorumConfirm(context.Background(), req.ID, ""), ErrQuorumNoCredential)
+		// another token of the requester
+		aliceAgain := middleware.WithCaller(adminCtx, &middleware.Caller{TokenID: middleware.TokenID("alice-2"), Name: "alice", IssuedAt: issuedAt})
+		assert.ErrorIs(t, q.QuorumConfirm(aliceAgain, req.ID, ""), ErrQuorumSameCredential)
+		unnamed := middleware.WithCaller(adminCtx, &middleware.Caller{TokenID: middleware.TokenID("unnamed"), IssuedAt: issuedAt})
+		assert.ErrorIs(t, q.QuorumConfirm(unnamed, req.ID, ""), ErrQuorumNotApprover)
+		// an admin token named after an approver, minted ahead of the request by the requester
+		minted := middleware.WithCaller(adminCtx, &middleware.Caller{TokenID: middleware.TokenID("bob-2"), Name: "bob", IssuedAt: issuedAt})
+		assert.ErrorIs(t, q.QuorumConfirm(minted, req.ID, ""), ErrQuorumNotApprover)
+		// an approver token created once the request was made
+		dave := middleware.WithCaller(adminCtx, &middleware.Caller{TokenID: daveToken, Name: "dave", IssuedAt: req.CreateAt.Add(time.Second).Unix()})
+		assert.ErrorIs(t, q.QuorumConfirm(dave, req.ID, ""), ErrQuorumTokenTooNew)
+		assert.NoError(t, q.QuorumConfirm(bob, req.ID, "checked"))
+
+		res := <-done
+		assert.NoError(t, res.err)
+		assert.NotNil(t, res.ki)
+
+		req2, err := q.QuorumGet(adminCtx, req.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, storage.QuorumConfirmed, req2.State)
+		assert.Equal(t, bobToken, req2.Confirmer.TokenID)
+
+		audits, err := q.QuorumAudit(adminCtx, req.ID)
+		assert.NoError(t, err)
+		var actions []storage.QuorumAction
+		for _, a := range audits {
+			actions = append(actions, a.Action)
+		}
+		// the six refused confirmations are in the trail
+		assert.Len(t, actions, 9)
+		assert.Equal(t, storage.QuorumActionRequest, actions[0])
+		for i := 1; i <= 7; i++ {
+			assert.Equal(t, storage.QuorumActionConfirm, actions[i])
+			assert.Equal(t, i < 7, audits[i].Err != "", i)
+		}
+		assert.Equal(t, storage.QuorumActionExecute, actions[8])
+	})
+
+	t.Run("reject", func(t *testing.T) {
+		done := make(chan error, 1)
+		go func() { done <- w.WalletDelete(alice, addr) }()
+
+		req := waitQuorumPending(t, q)
+		assert.Equal(t, storage.QuorumDelete, req.Op)
+		// the requester may cancel its own request
+		assert.NoError(t, q.QuorumReject(alice, req.ID, "typo"))
+		assert.ErrorIs(t, <-done, ErrQuorumRejected)
+		assert.Error(t, q.QuorumConfirm(bob, req.ID, ""))
+
+		has, err := w.WalletHas(adminCtx, addr)
+		assert.NoError(t, err)
+		assert.True(t, has)
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		_, err := q.Reload(&config.QuorumConfig{Enable: true, Timeout: "50ms"})
+		assert.Error(t, err, "approvers are required")
+		_, err = q.Reload(&config.QuorumConfig{Enable: true, Timeout: "50ms", Approvers: []string{"bob"}})
+		assert.Error(t, err, "approvers are token ids, not names")
+		apply, err := q.Reload(&config.QuorumConfig{Enable: true, Timeout: "50ms", Approvers: []string{bobToken}})
+		assert.NoError(t, err)
+		apply()
+
+		_, err = w.WalletExport(alice, addr)
+		assert.ErrorIs(t, err, ErrQuorumTimeout)
+		reqs, err := q.QuorumList(adminCtx, storage.QuorumExpired)
+		assert.NoError(t, err)
+		assert.NotEmpty(t, reqs)
+		assert.Equal(t, addr.String(), reqs[0].Target)
+	})
+
+	t.Run("no caller", func(t *testing.T) {
+		// in-process calls, eg. the password from the command line, don't wait
+		_, err := w.WalletExport(adminCtx, addr)
+		assert.NoError(t, err)
+		assert.NoError(t, w.WalletDelete(adminCtx, addr))
+		has, err := w.WalletHas(adminCtx, addr)
+		assert.NoError(t, err)
+		assert.False(t, has)
+	})
+}
@@ -0,0 +1,330 @@
+package wallet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/venus/venus-shared/types"
+	"go.opencensus.io/stats"
+	"go.opencensus.io/tag"
+	"golang.org/x/time/rate"
+
+	"github.com/filecoin-project/venus-wallet/config"
+	"github.com/filecoin-project/venus-wallet/middleware"
+)
+
+var ErrRateLimited = errors.New("sign rate limit exceeded")
+
+const (
+	scopeRule   = "rule"
+	scopeSigner = "signer"
+	scopeToken  = "token"
+)
+
+type rateLimitRule struct {
+	index    int
+	signer   address.Address
+	tokenID  string
+	msgType  types.MsgType
+	scope    string
+	limit    int
+	interval time.Duration
+
+	// set under RateLimiter.lk once a reload replaced the rule,
+	// next is the rule of the new config which took over its buckets, nil if it was dropped
+	retired bool
+	next    *rateLimitRule
+}
+
+func (r *rateLimitRule) String() string {
+	return fmt.Sprintf("rule %d (%d per %s)", r.index, r.limit, r.interval)
+}
+
+func (r *rateLimitRule) match(signer address.Address, tokenID string, msgType types.MsgType) bool {
+	if r.signer != address.Undef && r.signer != signer {
+		return false
+	}
+	if r.tokenID != "" && r.tokenID != tokenID {
+		return false
+	}
+	if r.msgType != types.MTUndefined && r.msgType != msgType {
+		return false
+	}
+	return true
+}
+
+// bucketKey the bucket of the request among the buckets of the rule
+func (r *rateLimitRule) bucketKey(signer address.Address, tokenID string) string {
+	switch r.scope {
+	case scopeSigner:
+		return signer.String()
+	case scopeToken:
+		return tokenID
+	default:
+		return ""
+	}
+}
+
+// same the rules limit the same requests the same way, wherever they are in the config
+func (r *rateLimitRule) same(o *rateLimitRule) bool {
+	return r.signer == o.signer && r.tokenID == o.tokenID && r.msgType == o.msgType &&
+		r.scope == o.scope && r.limit == o.limit && r.interval == o.interval
+}
+
+func sameRules(a, b []*rateLimitRule) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !a[i].same(b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func parseRateLimitRules(cfg *config.RateLimitConfig) ([]*rateLimitRule, error) {
+	if cfg == nil || !cfg.Enable {
+		return nil, nil
+	}
+	rules := make([]*rateLimitRule, 0, len(cfg.Rules))
+	for i, r := range cfg.Rules {
+		rule := &rateLimitRule{
+			index:   i,
+			msgType: types.MsgType(r.MsgType),
+			scope:   r.Scope,
+			limit:   r.Limit,
+		}
+		if r.Signer != "" {
+			addr, err := address.NewFromString(r.Signer)
+			if err != nil {
+				return nil, fmt.Errorf("rate limit rule %d: parse signer: %w", i, err)
+			}
+			rule.signer = addr
+		}
+		if r.Token != "" {
+			rule.tokenID = middleware.TokenID(r.Token)
+		}
+		switch rule.scope {
+		case "":
+			rule.scope = scopeRule
+		case scopeRule, scopeSigner, scopeToken:
+		default:
+			return nil, fmt.Errorf("rate limit rule %d: unknown scope %s", i, r.Scope)
+		}
+		if rule.limit <= 0 {
+			return nil, fmt.Errorf("rate limit rule %d: limit must be positive", i)
+		}
+		d, err := time.ParseDuration(r.Interval)
+		if err != nil {
+			return nil, fmt.Errorf("rate limit rule %d: parse interval: %w", i, err)
+		}
+		if d <= 0 {
+			return nil, fmt.Errorf("rate limit rule %d: interval must be positive", i)
+		}
+		rule.interval = d
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// minBucketSweep the buckets kept before the idle ones are looked for
+const minBucketSweep = 1024
+
+// RateLimiter token bucket rate limiting of sign requests, by signer address and by jwt token
+type RateLimiter struct {
+	lk    sync.Mutex
+	rules []*rateLimitRule
+	// buckets by rule, then by bucketKey
+	buckets map[*rateLimitRule]map[string]*rate.Limiter
+	size    int
+	// sweepAt the size at which the idle buckets are dropped
+	sweepAt int
+}
+
+func NewRateLimiter(cfg *config.RateLimitConfig) (*RateLimiter, error) {
+	rules, err := parseRateLimitRules(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &RateLimiter{
+		rules:   rules,
+		buckets: make(map[*rateLimitRule]map[string]*rate.Limiter),
+		sweepAt: minBucketSweep,
+	}, nil
+}
+
+// Reload validates the new config, the returned function applies it.
+// The buckets of the rules left unchanged are kept, so that a reload doesn't refill them.
+func (l *RateLimiter) Reload(cfg *config.RateLimitConfig) (func(), error) {
+	rules, err := parseRateLimitRules(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return func() {
+		l.lk.Lock()
+		defer l.lk.Unlock()
+		if sameRules(l.rules, rules) {
+			return
+		}
+		buckets := make(map[*rateLimitRule]map[string]*rate.Limiter)
+		size := 0
+		for _, rule := range rules {
+			for _, old := range l.rules {
+				if old.next != nil || !old.same(rule) {
+					continue
+				}
+				old.next = rule
+				if b, ok := l.buckets[old]; ok {
+					buckets[rule] = b
+					size += len(b)
+				}
+				break
+			}
+		}
+		for _, old := range l.rules {
+			old.retired = true
+		}
+		l.rules, l.buckets, l.size = rules, buckets, size
+	}, nil
+}
+
+func (l *RateLimiter) currentRules() []*rateLimitRule {
+	l.lk.Lock()
+	defer l.lk.Unlock()
+	return l.rules
+}
+
+// live the rule of the current config a rule taken before a reload stands for, nil if it was dropped
+func live(rule *rateLimitRule) *rateLimitRule {
+	for rule != nil && rule.retired {
+		rule = rule.next
+	}
+	return rule
+}
+
+// bucket the bucket of the request, created if there is none yet.
+// A request racing a reload draws from the bucket its rule handed over, nil if the reload dropped the rule.
+func (l *RateLimiter) bucket(rule *rateLimitRule, key string) *rate.Limiter {
+	l.lk.Lock()
+	defer l.lk.Unlock()
+	if rule = live(rule); rule == nil {
+		return nil
+	}
+	if b, ok := l.buckets[rule][key]; ok {
+		return b
+	}
+	if l.size >= l.sweepAt {
+		l.sweep()
+	}
+	buckets, ok := l.buckets[rule]
+	if !ok {
+		buckets = make(map[string]*rate.Limiter)
+		l.buckets[rule] = buckets
+	}
+	b := newBucket(rule)
+	buckets[key] = b
+	l.size++
+	return b
+}
+
+// peek the bucket of the request, a full one if there is none yet
+func (l *RateLimiter) peek(rule *rateLimitRule, key string) *rate.Limiter {
+	l.lk.Lock()
+	defer l.lk.Unlock()
+	if b, ok := l.buckets[live(rule)][key]; ok {
+		return b
+	}
+	return newBucket(rule)
+}
+
+// sweep drops the buckets refilled to the burst, a new bucket would be the same.
+// The next sweep waits for the buckets to double, so that a sweep finding nothing idle stays rare.
+func (l *RateLimiter) sweep() {
+	now := time.Now()
+	for rule, buckets := range l.buckets {
+		for key, b := range buckets {
+			if b.TokensAt(now) >= float64(b.Burst()) {
+				delete(buckets, key)
+				l.size--
+			}
+		}
+		if len(buckets) == 0 {
+			delete(l.buckets, rule)
+		}
+	}
+	l.sweepAt = 2 * l.size
+	if l.sweepAt < minBucketSweep {
+		l.sweepAt = minBucketSweep
+	}
+}
+
+func newBucket(rule *rateLimitRule) *rate.Limiter {
+	return rate.NewLimiter(rate.Every(rule.interval/time.Duration(rule.limit)), rule.limit)
+}
+
+// Allow takes a token from every bucket the request falls into, it fails if any of them is empty
+func (l *RateLimiter) Allow(ctx context.Context, signer address.Address, msgType types.MsgType) error {
+	rules := l.currentRules()
+	if len(rules) == 0 {
+		return nil
+	}
+	tokenID := callerTokenID(ctx)
+
+	now := time.Now()
+	reserved := make([]*rate.Reservation, 0, len(rules))
+	for _, rule := range rules {
+		if !rule.match(signer, tokenID, msgType) {
+			continue
+		}
+		b := l.bucket(rule, rule.bucketKey(signer, tokenID))
+		if b == nil {
+			continue
+		}
+		r := b.ReserveN(now, 1)
+		if !r.OK() || r.DelayFrom(now) > 0 {
+			// give back the tokens taken from the other buckets
+			r.CancelAt(now)
+			for _, prev := range reserved {
+				prev.CancelAt(now)
+			}
+			_ = stats.RecordWithTags(ctx, []tag.Mutator{
+				tag.Upsert(middleware.Signer, signer.String()),
+				tag.Upsert(middleware.MsgType, string(msgType)),
+			}, middleware.SignRateLimited.M(1))
+			return fmt.Errorf("%w: %s", ErrRateLimited, rule)
+		}
+		reserved = append(reserved, r)
+	}
+	return nil
+}
+
+// Check reports whether a request sent with the token would hit a limit, without taking any token.
+// Without token the rules of a token, or by token, can't be evaluated, they are returned instead.
+func (l *RateLimiter) Check(signer address.Address, tokenID string, msgType types.MsgType) ([]string, error) {
+	var skipped []string
+	for _, rule := range l.currentRules() {
+		if tokenID == "" && (rule.tokenID != "" || rule.scope == scopeToken) {
+			skipped = append(skipped, rule.String())
+			continue
+		}
+		if !rule.match(signer, tokenID, msgType) {
+			continue
+		}
+		if l.peek(rule, rule.bucketKey(signer, tokenID)).Tokens() < 1 {
+			return skipped, fmt.Errorf("%w: %s", ErrRateLimited, rule)
+		}
+	}
+	return skipped, nil
+}
+
+func callerTokenID(ctx context.Context) string {
+	if caller := middleware.CallerFromContext(ctx); caller != nil {
+		return caller.TokenID
+	}
+	return ""
+}
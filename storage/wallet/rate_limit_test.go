@@ -0,0 +1,163 @@
+package wallet
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/venus/venus-shared/types"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/filecoin-project/venus-wallet/config"
+	"github.com/filecoin-project/venus-wallet/middleware"
+)
+
+func TestRateLimiter_Allow(t *testing.T) {
+	ctx := context.Background()
+	addr1, err := address.NewIDAddress(1001)
+	assert.NoError(t, err)
+	addr2, err := address.NewIDAddress(1002)
+	assert.NoError(t, err)
+
+	t.Run("disabled", func(t *testing.T) {
+		l, err := NewRateLimiter(&config.RateLimitConfig{})
+		assert.NoError(t, err)
+		for i := 0; i < 10; i++ {
+			assert.NoError(t, l.Allow(ctx, addr1, types.MTChainMsg))
+		}
+	})
+
+	t.Run("per signer", func(t *testing.T) {
+		l, err := NewRateLimiter(&config.RateLimitConfig{
+			Enable: true,
+			Rules: []config.RateLimitRule{
+				{MsgType: string(types.MTChainMsg), Scope: "signer", Limit: 2, Interval: "1h"},
+			},
+		})
+		assert.NoError(t, err)
+		assert.NoError(t, l.Allow(ctx, addr1, types.MTChainMsg))
+		assert.NoError(t, l.Allow(ctx, addr1, types.MTChainMsg))
+		assert.ErrorIs(t, l.Allow(ctx, addr1, types.MTChainMsg), ErrRateLimited)
+		// other address and other message type are not affected
+		assert.NoError(t, l.Allow(ctx, addr2, types.MTChainMsg))
+		assert.NoError(t, l.Allow(ctx, addr1, types.MTBlock))
+	})
+
+	t.Run("per token", func(t *testing.T) {
+		l, err := NewRateLimiter(&config.RateLimitConfig{
+			Enable: true,
+			Rules: []config.RateLimitRule{
+				{Token: "market-token", MsgType: string(types.MTDealProposal), Limit: 1, Interval: "1h"},
+			},
+		})
+		assert.NoError(t, err)
+		market := middleware.WithCaller(ctx, &middleware.Caller{TokenID: middleware.TokenID("market-token")})
+		other := middleware.WithCaller(ctx, &middleware.Caller{TokenID: middleware.TokenID("other-token")})
+		assert.NoError(t, l.Allow(market, addr1, types.MTDealProposal))
+		assert.ErrorIs(t, l.Allow(market, addr2, types.MTDealProposal), ErrRateLimited)
+		assert.NoError(t, l.Allow(other, addr1, types.MTDealProposal))
+	})
+
+	t.Run("invalid rule", func(t *testing.T) {
+		_, err := NewRateLimiter(&config.RateLimitConfig{
+			Enable: true,
+			Rules:  []config.RateLimitRule{{Limit: 0, Interval: "1m"}},
+		})
+		assert.Error(t, err)
+		_, err = NewRateLimiter(&config.RateLimitConfig{
+			Enable: true,
+			Rules:  []config.RateLimitRule{{Limit: 1, Interval: "1m", Scope: "foo"}},
+		})
+		assert.Error(t, err)
+	})
+}
+
+func TestRateLimiter_Reload(t *testing.T) {
+	ctx := context.Background()
+	addr, err := address.NewIDAddress(1001)
+	assert.NoError(t, err)
+
+	chainRule := config.RateLimitRule{MsgType: string(types.MTChainMsg), Scope: "signer", Limit: 1, Interval: "1h"}
+	blockRule := config.RateLimitRule{MsgType: string(types.MTBlock), Limit: 1, Interval: "1h"}
+	cfg := &config.RateLimitConfig{Enable: true, Rules: []config.RateLimitRule{chainRule, blockRule}}
+	l, err := NewRateLimiter(cfg)
+	assert.NoError(t, err)
+	assert.NoError(t, l.Allow(ctx, addr, types.MTChainMsg))
+	assert.NoError(t, l.Allow(ctx, addr, types.MTBlock))
+
+	reload := func(cfg *config.RateLimitConfig) {
+		apply, err := l.Reload(cfg)
+		assert.NoError(t, err)
+		apply()
+	}
+	// an unchanged section doesn't refill the buckets
+	reload(cfg)
+	assert.ErrorIs(t, l.Allow(ctx, addr, types.MTChainMsg), ErrRateLimited)
+	assert.ErrorIs(t, l.Allow(ctx, addr, types.MTBlock), ErrRateLimited)
+
+	// the unchanged rule keeps its bucket when moved, the changed one starts full
+	blockRule.Limit = 2
+	reload(&config.RateLimitConfig{Enable: true, Rules: []config.RateLimitRule{blockRule, chainRule}})
+	assert.ErrorIs(t, l.Allow(ctx, addr, types.MTChainMsg), ErrRateLimited)
+	assert.NoError(t, l.Allow(ctx, addr, types.MTBlock))
+}
+
+func TestRateLimiter_Sweep(t *testing.T) {
+	ctx := context.Background()
+	busy, err := address.NewIDAddress(1000)
+	assert.NoError(t, err)
+	l, err := NewRateLimiter(&config.RateLimitConfig{
+		Enable: true,
+		Rules: []config.RateLimitRule{
+			{Scope: "signer", Limit: 1, Interval: "10ms"},
+			{Signer: busy.String(), Limit: 1, Interval: "1h"},
+		},
+	})
+	assert.NoError(t, err)
+
+	assert.NoError(t, l.Allow(ctx, busy, types.MTChainMsg))
+	for i := 1; i <= 10; i++ {
+		addr, err := address.NewIDAddress(uint64(1000 + i))
+		assert.NoError(t, err)
+		assert.NoError(t, l.Allow(ctx, addr, types.MTChainMsg))
+	}
+	assert.Equal(t, 12, l.size)
+
+	// the refilled buckets are dropped, the one still limiting is kept
+	time.Sleep(20 * time.Millisecond)
+	l.sweepAt = l.size
+	addr, err := address.NewIDAddress(2000)
+	assert.NoError(t, err)
+	assert.NoError(t, l.Allow(ctx, addr, types.MTChainMsg))
+	assert.Equal(t, 2, l.size)
+	assert.Equal(t, minBucketSweep, l.sweepAt)
+	assert.ErrorIs(t, l.Allow(ctx, busy, types.MTChainMsg), ErrRateLimited)
+}
+
+func TestRateLimiter_ReloadRace(t *testing.T) {
+	ctx := context.Background()
+	addr, err := address.NewIDAddress(1001)
+	assert.NoError(t, err)
+
+	chainRule := config.RateLimitRule{MsgType: string(types.MTChainMsg), Limit: 1, Interval: "1h"}
+	blockRule := config.RateLimitRule{MsgType: string(types.MTBlock), Limit: 1, Interval: "1h"}
+	l, err := NewRateLimiter(&config.RateLimitConfig{Enable: true, Rules: []config.RateLimitRule{chainRule, blockRule}})
+	assert.NoError(t, err)
+	// the rules of a request started before the reload
+	rules := l.currentRules()
+
+	blockRule.Limit = 2
+	apply, err := l.Reload(&config.RateLimitConfig{Enable: true, Rules: []config.RateLimitRule{blockRule, chainRule}})
+	assert.NoError(t, err)
+	apply()
+
+	// the moved rule draws from the bucket it handed over, the dropped one from none
+	assert.True(t, l.bucket(rules[0], "").Allow())
+	assert.Nil(t, l.bucket(rules[1], ""))
+	assert.ErrorIs(t, l.Allow(ctx, addr, types.MTChainMsg), ErrRateLimited)
+	// the changed rule's bucket is untouched
+	assert.NoError(t, l.Allow(ctx, addr, types.MTBlock))
+	assert.NoError(t, l.Allow(ctx, addr, types.MTBlock))
+	assert.Equal(t, 2, l.size)
+}
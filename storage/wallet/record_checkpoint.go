@@ -0,0 +1,157 @@
+package wallet
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/filecoin-project/go-address"
+	"go.uber.org/fx"
+
+	"github.com/filecoin-project/venus-wallet/config"
+	"github.com/filecoin-project/venus-wallet/crypto"
+	"github.com/filecoin-project/venus-wallet/storage"
+)
+
+const defaultCheckpointInterval = time.Hour
+
+// RecordCheckpointer signs the head of the sign record chain with the audit key periodically,
+// so that removing the latest records or rewriting the whole chain is noticed by `record verify`
+type RecordCheckpointer struct {
+	w        *wallet
+	recorder storage.IRecorder
+	auditKey address.Address
+	interval time.Duration
+	// last the head signed last time
+	last *storage.RecordCheckpoint
+}
+
+func parseCheckpointConfig(cfg *config.SignRecorderConfig) (address.Address, time.Duration, error) {
+	if cfg == nil || cfg.AuditKey == "" {
+		return address.Undef, 0, nil
+	}
+	auditKey, err := address.NewFromString(cfg.AuditKey)
+	if err != nil {
+		return address.Undef, 0, fmt.Errorf("parse audit key: %w", err)
+	}
+	interval := defaultCheckpointInterval
+	if cfg.CheckpointInterval != "" {
+		interval, err = time.ParseDuration(cfg.CheckpointInterval)
+		if err != nil {
+			return address.Undef, 0, fmt.Errorf("parse checkpoint interval: %w", err)
+		}
+		if interval <= 0 {
+			return address.Undef, 0, fmt.Errorf("checkpoint interval must be positive")
+		}
+	}
+	return auditKey, interval, nil
+}
+
+// StartRecordCheckpoint starts the checkpoints when an audit key is configured
+func StartRecordCheckpoint(lc fx.Lifecycle, cfg *config.SignRecorderConfig, recorder storage.IRecorder, lw ILocalWallet) error {
+	auditKey, interval, err := parseCheckpointConfig(cfg)
+	if err != nil {
+		return err
+	}
+	if auditKey == address.Undef {
+		return nil
+	}
+	w, ok := lw.(*wallet)
+	if !ok {
+		return fmt.Errorf("record checkpoint needs the local wallet")
+	}
+	cp := &RecordCheckpointer{w: w, recorder: recorder, auditKey: auditKey, interval: interval}
+	// `record verify` rejects the unsigned prune checkpoints once an audit key is set
+	recorder.SetPruneSigner(cp.SignPrune)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	lc.Append(fx.Hook{
+		OnStart: func(_ context.Context) error {
+			go cp.run(ctx)
+			return nil
+		},
+		OnStop: func(_ context.Context) error {
+			cancel()
+			return nil
+		},
+	})
+	return nil
+}
+
+func (cp *RecordCheckpointer) run(ctx context.Context) {
+	ticker := time.NewTicker(cp.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := cp.Checkpoint(); err != nil {
+				log.Warnf("sign record checkpoint: %v", err)
+			}
+		}
+	}
+}
+
+// Checkpoint signs the current chain head, nothing is done if it was signed already
+func (cp *RecordCheckpointer) Checkpoint() error {
+	head, err := cp.recorder.ChainHead()
+	if err != nil {
+		return err
+	}
+	if head == nil {
+		return nil
+	}
+	if cp.last != nil && cp.last.Seq == head.Seq && bytes.Equal(cp.last.Hash, head.Hash) {
+		return nil
+	}
+
+	prvKey, err := cp.key()
+	if err != nil {
+		return err
+	}
+	checkpoint := &storage.RecordCheckpoint{
+		Kind:     storage.CheckpointSigned,
+		Seq:      head.Seq,
+		Hash:     head.Hash,
+		CreateAt: time.Now().Truncate(storage.TimePrecision),
+		Signer:   cp.auditKey,
+	}
+	checkpoint.Signature, err = prvKey.Sign(checkpoint.SigningBytes())
+	if err != nil {
+		return fmt.Errorf("sign checkpoint: %w", err)
+	}
+	if err := cp.recorder.PutCheckpoint(checkpoint); err != nil {
+		return err
+	}
+	cp.last = head
+	return nil
+}
+
+// SignPrune signs the prune checkpoints of the records deleted by the retention
+func (cp *RecordCheckpointer) SignPrune(cps []*storage.RecordCheckpoint) error {
+	prvKey, err := cp.key()
+	if err != nil {
+		return err
+	}
+	for _, c := range cps {
+		c.Signer = cp.auditKey
+		if c.Signature, err = prvKey.Sign(c.SigningBytes()); err != nil {
+			return fmt.Errorf("sign prune checkpoint %d: %w", c.Seq, err)
+		}
+	}
+	return nil
+}
+
+func (cp *RecordCheckpointer) key() (crypto.PrivateKey, error) {
+	// the wallet must be unlocked
+	if err := cp.w.mw.Next(); err != nil {
+		return nil, err
+	}
+	prvKey, err := cp.w.privateKey(cp.auditKey)
+	if err != nil {
+		return nil, fmt.Errorf("audit key %s: %w", cp.auditKey, err)
+	}
+	return prvKey, nil
+}
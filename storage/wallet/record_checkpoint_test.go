@@ -0,0 +1,80 @@
+package wallet
+
+import (
+	"testing"
+	"time"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/venus/venus-shared/types"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/filecoin-project/venus-wallet/config"
+	"github.com/filecoin-project/venus-wallet/crypto"
+	"github.com/filecoin-project/venus-wallet/storage"
+)
+
+func TestRecordCheckpointer(t *testing.T) {
+	w, ctx := newTestWallet(t)
+	setTestRecorder(t, w)
+
+	auditKey, err := w.WalletNew(ctx, types.KTSecp256k1)
+	assert.NoError(t, err)
+	signer, err := w.WalletNew(ctx, types.KTSecp256k1)
+	assert.NoError(t, err)
+	cp := &RecordCheckpointer{w: w, recorder: w.recorder, auditKey: auditKey, interval: time.Hour}
+
+	// nothing to sign yet
+	assert.NoError(t, cp.Checkpoint())
+
+	msg := &types.Message{From: signer, To: signer, Value: abi.NewTokenAmount(0)}
+	extra, err := msg.Serialize()
+	assert.NoError(t, err)
+	_, err = w.WalletSign(ctx, signer, msg.Cid().Bytes(), types.MsgMeta{Type: types.MTChainMsg, Extra: extra})
+	assert.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		head, err := w.recorder.ChainHead()
+		return err == nil && head != nil
+	}, time.Second, 10*time.Millisecond)
+
+	assert.NoError(t, cp.Checkpoint())
+	// the head didn't move, no new checkpoint
+	assert.NoError(t, cp.Checkpoint())
+
+	res, err := w.recorder.VerifyChain(auditKey)
+	assert.NoError(t, err)
+	assert.Empty(t, res.Problems)
+	assert.Equal(t, 1, res.Checkpoints)
+	assert.Equal(t, uint64(1), res.LastCheckpoint)
+
+	prune := []*storage.RecordCheckpoint{{Kind: storage.CheckpointPrune, Seq: 1, Hash: []byte("hash"),
+		CreateAt: time.Now(), RecordAt: time.Now().Add(-time.Hour), RecordType: types.MTChainMsg}}
+	assert.NoError(t, cp.SignPrune(prune))
+	assert.Equal(t, auditKey, prune[0].Signer)
+	assert.NoError(t, crypto.Verify(prune[0].Signature, auditKey, prune[0].SigningBytes()))
+
+	// the checkpoints need the wallet unlocked
+	assert.NoError(t, w.Lock(ctx, "password"))
+	_, err = w.WalletSign(ctx, signer, msg.Cid().Bytes(), types.MsgMeta{Type: types.MTChainMsg, Extra: extra})
+	assert.Error(t, err)
+	cp.last = nil
+	assert.Error(t, cp.Checkpoint())
+	assert.Error(t, cp.SignPrune(prune))
+
+}
+
+func TestParseCheckpointConfig(t *testing.T) {
+	auditKey, _, err := parseCheckpointConfig(&config.SignRecorderConfig{})
+	assert.NoError(t, err)
+	assert.Equal(t, address.Undef, auditKey)
+
+	auditKey, interval, err := parseCheckpointConfig(&config.SignRecorderConfig{AuditKey: "f01000"})
+	assert.NoError(t, err)
+	assert.Equal(t, "f01000", auditKey.String())
+	assert.Equal(t, defaultCheckpointInterval, interval)
+
+	_, _, err = parseCheckpointConfig(&config.SignRecorderConfig{AuditKey: "f01000", CheckpointInterval: "-1h"})
+	assert.Error(t, err)
+	_, _, err = parseCheckpointConfig(&config.SignRecorderConfig{AuditKey: "bad"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,185 @@
+package wallet
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.opencensus.io/stats"
+	"go.opencensus.io/tag"
+	"go.uber.org/fx"
+
+	"github.com/filecoin-project/venus-wallet/config"
+	"github.com/filecoin-project/venus-wallet/middleware"
+	"github.com/filecoin-project/venus-wallet/storage"
+)
+
+const (
+	defaultRecordQueueSize     = 1024
+	defaultRecordBatchSize     = 100
+	defaultRecordFlushInterval = 500 * time.Millisecond
+)
+
+// RecordQueue the sign records wait in it to be written in batches, so that signing doesn't wait for the database.
+// When it's full, signing waits for room in it, or the records are dropped if DropWhenFull is set.
+type RecordQueue struct {
+	recorder  storage.IRecorder
+	records   chan *storage.SignRecord
+	batchSize int
+	interval  time.Duration
+	drop      bool
+	// lk guards closed, the pushes hold it for read so that the channel isn't closed under them
+	lk     sync.RWMutex
+	closed bool
+	done   chan struct{}
+}
+
+type recordQueueConfig struct {
+	size      int
+	batchSize int
+	interval  time.Duration
+	drop      bool
+}
+
+func parseRecordQueueConfig(cfg *config.SignRecorderConfig) (*recordQueueConfig, error) {
+	ret := &recordQueueConfig{size: defaultRecordQueueSize, batchSize: defaultRecordBatchSize, interval: defaultRecordFlushInterval}
+	if cfg == nil || cfg.Queue == nil {
+		return ret, nil
+	}
+	if cfg.Queue.Size < 0 || cfg.Queue.BatchSize < 0 {
+		return nil, fmt.Errorf("record queue size and batch size can't be negative")
+	}
+	if cfg.Queue.Size > 0 {
+		ret.size = cfg.Queue.Size
+	}
+	if cfg.Queue.BatchSize > 0 {
+		ret.batchSize = cfg.Queue.BatchSize
+	}
+	if cfg.Queue.FlushInterval != "" {
+		interval, err := time.ParseDuration(cfg.Queue.FlushInterval)
+		if err != nil {
+			return nil, fmt.Errorf("parse record flush interval: %w", err)
+		}
+		if interval <= 0 {
+			return nil, fmt.Errorf("record flush interval must be positive")
+		}
+		ret.interval = interval
+	}
+	ret.drop = cfg.Queue.DropWhenFull
+	return ret, nil
+}
+
+// NewRecordQueue the queue is drained when the wallet stops
+func NewRecordQueue(lc fx.Lifecycle, cfg *config.SignRecorderConfig, recorder storage.IRecorder) (*RecordQueue, error) {
+	qcfg, err := parseRecordQueueConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	q := newRecordQueue(recorder, qcfg)
+	lc.Append(fx.Hook{
+		OnStop: q.Close,
+	})
+	return q, nil
+}
+
+func newRecordQueue(recorder storage.IRecorder, cfg *recordQueueConfig) *RecordQueue {
+	q := &RecordQueue{
+		recorder:  recorder,
+		records:   make(chan *storage.SignRecord, cfg.size),
+		batchSize: cfg.batchSize,
+		interval:  cfg.interval,
+		drop:      cfg.drop,
+		done:      make(chan struct{}),
+	}
+	go q.run()
+	return q
+}
+
+// Push queues the record, it's written directly once the queue is closed
+func (q *RecordQueue) Push(ctx context.Context, record *storage.SignRecord) {
+	q.lk.RLock()
+	defer q.lk.RUnlock()
+
+	if q.closed {
+		if err := q.recorder.Record(record); err != nil {
+			log.Errorf("record sign failed: %v", err)
+		}
+		return
+	}
+	if !q.drop {
+		// the signature is produced already, the record is kept even if the caller is gone
+		q.records <- record
+		return
+	}
+	select {
+	case q.records <- record:
+	default:
+		_ = stats.RecordWithTags(ctx, []tag.Mutator{
+			tag.Upsert(middleware.Signer, record.Signer.String()),
+			tag.Upsert(middleware.MsgType, string(record.Type)),
+		}, middleware.RecordDropped.M(1))
+		log.Warnf("record queue full, sign record of %s dropped", record.Signer)
+	}
+}
+
+// Close writes the records left, the records pushed later are written directly
+func (q *RecordQueue) Close(ctx context.Context) error {
+	q.lk.Lock()
+	if !q.closed {
+		q.closed = true
+		close(q.records)
+	}
+	q.lk.Unlock()
+
+	select {
+	case <-q.done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("write the queued sign records, %d left: %w", len(q.records), ctx.Err())
+	}
+}
+
+func (q *RecordQueue) run() {
+	defer close(q.done)
+	ticker := time.NewTicker(q.interval)
+	defer ticker.Stop()
+
+	batch := make([]*storage.SignRecord, 0, q.batchSize)
+	flush := func() {
+		q.write(batch)
+		batch = batch[:0]
+	}
+	for {
+		select {
+		case record, ok := <-q.records:
+			if !ok {
+				flush()
+				return
+			}
+			batch = append(batch, record)
+			if len(batch) >= q.batchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
+
+// write a failed batch is written again record by record, so that a bad record doesn't lose the others
+func (q *RecordQueue) write(batch []*storage.SignRecord) {
+	if len(batch) == 0 {
+		return
+	}
+	err := q.recorder.RecordBatch(batch)
+	if err == nil {
+		return
+	}
+	log.Errorf("record %d signs failed, writing them one by one: %v", len(batch), err)
+	for _, record := range batch {
+		if err := q.recorder.Record(record); err != nil {
+			log.Errorf("record sign %s failed: %v", record.ID, err)
+		}
+	}
+}
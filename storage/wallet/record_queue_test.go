@@ -0,0 +1,201 @@
+package wallet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/venus/venus-shared/types"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opencensus.io/stats/view"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/filecoin-project/venus-wallet/config"
+	"github.com/filecoin-project/venus-wallet/middleware"
+	"github.com/filecoin-project/venus-wallet/storage"
+	walletsqlite "github.com/filecoin-project/venus-wallet/storage/sqlite"
+)
+
+// setTestRecorder the sign and admin records of the wallet go to an in-memory database
+func setTestRecorder(t *testing.T, w *wallet) {
+	db, err := gorm.Open(sqlite.Open(fmt.Sprintf("file:%s-record?mode=memory&cache=shared", t.Name())), &gorm.Config{})
+	require.NoError(t, err)
+	w.recorder, err = walletsqlite.NewSqliteRecorder(db, nil)
+	require.NoError(t, err)
+	require.NoError(t, w.records.Close(context.Background()))
+	records := newRecordQueue(w.recorder, &recordQueueConfig{size: 16, batchSize: 4, interval: time.Millisecond})
+	w.records = records
+	t.Cleanup(func() { assert.NoError(t, records.Close(context.Background())) })
+}
+
+// slowRecorder counts the batches, and blocks the writes until it's released
+type slowRecorder struct {
+	storage.IRecorder
+	release chan struct{}
+	lk      sync.Mutex
+	batches [][]*storage.SignRecord
+	fail    bool
+}
+
+func (r *slowRecorder) RecordBatch(records []*storage.SignRecord) error {
+	<-r.release
+	r.lk.Lock()
+	defer r.lk.Unlock()
+	if r.fail {
+		return errors.New("database is locked")
+	}
+	r.batches = append(r.batches, append([]*storage.SignRecord(nil), records...))
+	return nil
+}
+
+func (r *slowRecorder) Record(record *storage.SignRecord) error {
+	r.lk.Lock()
+	defer r.lk.Unlock()
+	r.batches = append(r.batches, []*storage.SignRecord{record})
+	return nil
+}
+
+func (r *slowRecorder) count() int {
+	r.lk.Lock()
+	defer r.lk.Unlock()
+	n := 0
+	for _, b := range r.batches {
+		n += len(b)
+	}
+	return n
+}
+
+func newTestSignRecord() *storage.SignRecord {
+	signer, _ := address.NewIDAddress(1000)
+	return &storage.SignRecord{ID: uuid.New().String(), Type: types.MTChainMsg, Signer: signer}
+}
+
+func TestRecordQueue_Batch(t *testing.T) {
+	recorder := &slowRecorder{release: make(chan struct{})}
+	close(recorder.release)
+	q := newRecordQueue(recorder, &recordQueueConfig{size: 100, batchSize: 10, interval: time.Hour})
+
+	// a full batch is written at once, without waiting for the interval
+	for i := 0; i < 25; i++ {
+		q.Push(context.Background(), newTestSignRecord())
+	}
+	assert.Eventually(t, func() bool { return recorder.count() == 20 }, time.Second, time.Millisecond)
+
+	// the records left are written on close, in order
+	require.NoError(t, q.Close(context.Background()))
+	assert.Equal(t, 25, recorder.count())
+	assert.Len(t, recorder.batches, 3)
+	assert.Len(t, recorder.batches[2], 5)
+
+	// written directly after close
+	q.Push(context.Background(), newTestSignRecord())
+	assert.Equal(t, 26, recorder.count())
+}
+
+func TestRecordQueue_Interval(t *testing.T) {
+	recorder := &slowRecorder{release: make(chan struct{})}
+	close(recorder.release)
+	q := newRecordQueue(recorder, &recordQueueConfig{size: 100, batchSize: 10, interval: 10 * time.Millisecond})
+	defer q.Close(context.Background()) //nolint:errcheck
+
+	q.Push(context.Background(), newTestSignRecord())
+	assert.Eventually(t, func() bool { return recorder.count() == 1 }, time.Second, time.Millisecond)
+}
+
+func TestRecordQueue_Full(t *testing.T) {
+	t.Run("block", func(t *testing.T) {
+		recorder := &slowRecorder{release: make(chan struct{})}
+		q := newRecordQueue(recorder, &recordQueueConfig{size: 2, batchSize: 1, interval: time.Hour})
+
+		// one record held by the writer, two in the queue
+		for i := 0; i < 3; i++ {
+			q.Push(context.Background(), newTestSignRecord())
+		}
+		pushed := make(chan struct{})
+		go func() {
+			q.Push(context.Background(), newTestSignRecord())
+			close(pushed)
+		}()
+		select {
+		case <-pushed:
+			t.Fatal("push didn't wait for room in the queue")
+		case <-time.After(50 * time.Millisecond):
+		}
+		close(recorder.release)
+		<-pushed
+		require.NoError(t, q.Close(context.Background()))
+		assert.Equal(t, 4, recorder.count())
+	})
+
+	t.Run("drop", func(t *testing.T) {
+		require.NoError(t, view.Register(middleware.RecordDroppedView))
+		defer view.Unregister(middleware.RecordDroppedView)
+
+		recorder := &slowRecorder{release: make(chan struct{})}
+		q := newRecordQueue(recorder, &recordQueueConfig{size: 2, batchSize: 1, interval: time.Hour, drop: true})
+		// one record held by the writer, two in the queue, the next one is dropped
+		q.Push(context.Background(), newTestSignRecord())
+		assert.Eventually(t, func() bool { return len(q.records) == 0 }, time.Second, time.Millisecond)
+		for i := 0; i < 3; i++ {
+			q.Push(context.Background(), newTestSignRecord())
+		}
+
+		rows, err := view.RetrieveData(middleware.RecordDroppedView.Name)
+		require.NoError(t, err)
+		require.Len(t, rows, 1)
+		assert.Equal(t, int64(1), rows[0].Data.(*view.CountData).Value)
+
+		close(recorder.release)
+		require.NoError(t, q.Close(context.Background()))
+		assert.Equal(t, 3, recorder.count())
+	})
+}
+
+func TestRecordQueue_BatchFailed(t *testing.T) {
+	recorder := &slowRecorder{release: make(chan struct{}), fail: true}
+	close(recorder.release)
+	q := newRecordQueue(recorder, &recordQueueConfig{size: 10, batchSize: 3, interval: time.Hour})
+	for i := 0; i < 3; i++ {
+		q.Push(context.Background(), newTestSignRecord())
+	}
+	require.NoError(t, q.Close(context.Background()))
+	// written one by one
+	assert.Equal(t, 3, recorder.count())
+	assert.Len(t, recorder.batches, 3)
+}
+
+func TestRecordQueue_CloseTimeout(t *testing.T) {
+	recorder := &slowRecorder{release: make(chan struct{})}
+	q := newRecordQueue(recorder, &recordQueueConfig{size: 10, batchSize: 1, interval: time.Hour})
+	q.Push(context.Background(), newTestSignRecord())
+	q.Push(context.Background(), newTestSignRecord())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, q.Close(ctx), context.DeadlineExceeded)
+	close(recorder.release)
+	<-q.done
+	assert.Equal(t, 2, recorder.count())
+}
+
+func TestParseRecordQueueConfig(t *testing.T) {
+	cfg, err := parseRecordQueueConfig(nil)
+	require.NoError(t, err)
+	assert.Equal(t, &recordQueueConfig{size: defaultRecordQueueSize, batchSize: defaultRecordBatchSize, interval: defaultRecordFlushInterval}, cfg)
+
+	cfg, err = parseRecordQueueConfig(&config.SignRecorderConfig{Queue: &config.RecordQueueConfig{Size: 10, FlushInterval: "1s", DropWhenFull: true}})
+	require.NoError(t, err)
+	assert.Equal(t, &recordQueueConfig{size: 10, batchSize: defaultRecordBatchSize, interval: time.Second, drop: true}, cfg)
+
+	_, err = parseRecordQueueConfig(&config.SignRecorderConfig{Queue: &config.RecordQueueConfig{FlushInterval: "-1s"}})
+	assert.Error(t, err)
+	_, err = parseRecordQueueConfig(&config.SignRecorderConfig{Queue: &config.RecordQueueConfig{Size: -1}})
+	assert.Error(t, err)
+}
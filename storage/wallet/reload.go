@@ -0,0 +1,332 @@
+package wallet
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"go.uber.org/fx"
+
+	"github.com/filecoin-project/venus-wallet/config"
+	"github.com/filecoin-project/venus-wallet/filemgr"
+	"github.com/filecoin-project/venus-wallet/storage"
+)
+
+const (
+	ReloadTriggerAPI    = "api"
+	ReloadTriggerSignal = "signal"
+	ReloadTriggerFile   = "file"
+)
+
+// reloadDebounce editors usually emit several events for one save
+const reloadDebounce = 500 * time.Millisecond
+
+// ReloadResult the outcome of a config reload
+type ReloadResult struct {
+	Time    time.Time
+	Trigger string
+	Success bool
+	Err     string
+	// Changed the reloadable sections that were changed
+	Changed []string
+	// RestartRequired the changed sections that only take effect after a restart
+	RestartRequired []string
+}
+
+// IConfigReload reload of the sign filter, policies and recorder retention without restart
+type IConfigReload interface {
+	// ConfigReload reads the config file, validates it and applies the reloadable sections
+	ConfigReload(ctx context.Context) (*ReloadResult, error)
+	// ConfigReloadResult returns the result of the last reload, nil if there was none
+	ConfigReloadResult(ctx context.Context) (*ReloadResult, error)
+}
+
+var _ IConfigReload = &ConfigReloader{}
+
+type recorderReloader interface {
+	Reload(cfg *config.SignRecorderConfig) (func(), error)
+}
+
+// ConfigReloader reloads the config on api call, SIGHUP or change of the config file.
+// All the sections are validated before any of them is applied, so a bad config changes nothing.
+type ConfigReloader struct {
+	repo       filemgr.Repo
+	signFilter *SignFilter
+	signPlugin *SignPlugin
+	dealPolicy *DealPolicy
+	blindSign  *BlindSignPolicy
+	limiter    *RateLimiter
+	approval   *ApprovalQueue
+	quorum     *QuorumQueue
+	recorder   storage.IRecorder
+
+	lk   sync.Mutex
+	last *ReloadResult
+}
+
+func NewConfigReloader(lc fx.Lifecycle,
+	repo filemgr.Repo,
+	signFilter *SignFilter,
+	signPlugin *SignPlugin,
+	dealPolicy *DealPolicy,
+	blindSign *BlindSignPolicy,
+	limiter *RateLimiter,
+	approval *ApprovalQueue,
+	quorum *QuorumQueue,
+	recorder storage.IRecorder,
+) (*ConfigReloader, error) {
+	r := &ConfigReloader{
+		repo:       repo,
+		signFilter: signFilter,
+		signPlugin: signPlugin,
+		dealPolicy: dealPolicy,
+		blindSign:  blindSign,
+		limiter:    limiter,
+		approval:   approval,
+		quorum:     quorum,
+		recorder:   recorder,
+	}
+	if lc == nil {
+		return r, nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	lc.Append(fx.Hook{
+		OnStart: func(_ context.Context) error {
+			return r.watch(ctx)
+		},
+		OnStop: func(_ context.Context) error {
+			cancel()
+			return nil
+		},
+	})
+	return r, nil
+}
+
+func (r *ConfigReloader) ConfigReload(ctx context.Context) (*ReloadResult, error) {
+	res := r.reload(ReloadTriggerAPI)
+	if !res.Success {
+		return res, fmt.Errorf("reload config: %s", res.Err)
+	}
+	return res, nil
+}
+
+func (r *ConfigReloader) ConfigReloadResult(ctx context.Context) (*ReloadResult, error) {
+	r.lk.Lock()
+	defer r.lk.Unlock()
+	return r.last, nil
+}
+
+func (r *ConfigReloader) reload(trigger string) *ReloadResult {
+	r.lk.Lock()
+	defer r.lk.Unlock()
+
+	res := &ReloadResult{Time: time.Now(), Trigger: trigger}
+	r.last = res
+	if err := r.apply(res); err != nil {
+		res.Err = err.Error()
+		log.Errorf("reload config (%s): %s", trigger, err)
+		return res
+	}
+	res.Success = true
+	log.Infof("reload config (%s): changed %v, restart required %v", trigger, res.Changed, res.RestartRequired)
+	return res
+}
+
+func (r *ConfigReloader) apply(res *ReloadResult) error {
+	cnf, err := r.repo.ReadConfig()
+	if err != nil {
+		return err
+	}
+	cur := r.repo.Config()
+
+	var applies []func()
+	add := func(name string, apply func(), err error) error {
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		applies = append(applies, apply)
+		return nil
+	}
+	apply, err := r.signFilter.Reload(cnf.SignFilter)
+	if err := add("SignFilter", apply, err); err != nil {
+		return err
+	}
+	apply, err = r.signPlugin.Reload(cnf.SignPlugin)
+	if err := add("SignPlugin", apply, err); err != nil {
+		return err
+	}
+	apply, err = r.dealPolicy.Reload(cnf.DealPolicy)
+	if err := add("DealPolicy", apply, err); err != nil {
+		return err
+	}
+	apply, err = r.blindSign.Reload(cnf.BlindSign)
+	if err := add("BlindSign", apply, err); err != nil {
+		return err
+	}
+	apply, err = r.limiter.Reload(cnf.RateLimit)
+	if err := add("RateLimit", apply, err); err != nil {
+		return err
+	}
+	apply, err = r.approval.Reload(cnf.Approval)
+	if err := add("Approval", apply, err); err != nil {
+		return err
+	}
+	apply, err = r.quorum.Reload(cnf.Quorum)
+	if err := add("Quorum", apply, err); err != nil {
+		return err
+	}
+	if recorder, ok := r.recorder.(recorderReloader); ok {
+		apply, err = recorder.Reload(cnf.SignRecorder)
+		if err := add("SignRecorder", apply, err); err != nil {
+			return err
+		}
+	}
+
+	for _, apply := range applies {
+		apply()
+	}
+
+	sections := []struct {
+		name       string
+		old, new   interface{}
+		reloadable bool
+	}{
+		{"SignFilter", cur.SignFilter, cnf.SignFilter, true},
+		{"SignPlugin", cur.SignPlugin, cnf.SignPlugin, true},
+		{"DealPolicy", cur.DealPolicy, cnf.DealPolicy, true},
+		{"BlindSign", cur.BlindSign, cnf.BlindSign, true},
+		{"RateLimit", cur.RateLimit, cnf.RateLimit, true},
+		{"Approval", cur.Approval, cnf.Approval, true},
+		{"Quorum", cur.Quorum, cnf.Quorum, true},
+		{"SignRecorder.Retention", recorderRetention(cur.SignRecorder), recorderRetention(cnf.SignRecorder), true},
+		{"SignRecorder.Enable", recorderEnable(cur.SignRecorder), recorderEnable(cnf.SignRecorder), false},
+		{"SignRecorder.AuditKey", recorderAudit(cur.SignRecorder), recorderAudit(cnf.SignRecorder), false},
+		{"SignRecorder.DB", recorderDB(cur.SignRecorder), recorderDB(cnf.SignRecorder), false},
+		{"SignRecorder.Queue", recorderQueue(cur.SignRecorder), recorderQueue(cnf.SignRecorder), false},
+		{"API", cur.API, cnf.API, false},
+		{"DB", cur.DB, cnf.DB, false},
+		{"JWT", cur.JWT, cnf.JWT, false},
+		{"Factor", cur.Factor, cnf.Factor, false},
+		{"Metrics", cur.Metrics, cnf.Metrics, false},
+		{"APIRegisterHub", cur.APIRegisterHub, cnf.APIRegisterHub, false},
+		{"KeyExport", cur.KeyExport, cnf.KeyExport, false},
+	}
+	for _, s := range sections {
+		if reflect.DeepEqual(s.old, s.new) {
+			continue
+		}
+		if s.reloadable {
+			res.Changed = append(res.Changed, s.name)
+		} else {
+			res.RestartRequired = append(res.RestartRequired, s.name)
+		}
+	}
+
+	r.repo.UpdateConfig(func(c *config.Config) {
+		c.SignFilter = cnf.SignFilter
+		c.SignPlugin = cnf.SignPlugin
+		c.DealPolicy = cnf.DealPolicy
+		c.BlindSign = cnf.BlindSign
+		c.RateLimit = cnf.RateLimit
+		c.Approval = cnf.Approval
+		c.Quorum = cnf.Quorum
+		if c.SignRecorder != nil && cnf.SignRecorder != nil {
+			// the section in use is read by the recorder, only the copy is changed
+			recorder := *c.SignRecorder
+			recorder.KeepDuration = cnf.SignRecorder.KeepDuration
+			recorder.KeepDurations = cnf.SignRecorder.KeepDurations
+			recorder.Archive = cnf.SignRecorder.Archive
+			c.SignRecorder = &recorder
+		}
+	})
+	return nil
+}
+
+// recorderRetention the reloadable part of the recorder config
+func recorderRetention(cfg *config.SignRecorderConfig) interface{} {
+	if cfg == nil {
+		return nil
+	}
+	return []interface{}{cfg.KeepDuration, cfg.KeepDurations, cfg.Archive}
+}
+
+func recorderEnable(cfg *config.SignRecorderConfig) bool {
+	return cfg == nil || cfg.Enable
+}
+
+func recorderAudit(cfg *config.SignRecorderConfig) [2]string {
+	if cfg == nil {
+		return [2]string{}
+	}
+	return [2]string{cfg.AuditKey, cfg.CheckpointInterval}
+}
+
+func recorderDB(cfg *config.SignRecorderConfig) *config.DBConfig {
+	if cfg == nil {
+		return nil
+	}
+	return cfg.DB
+}
+
+func recorderQueue(cfg *config.SignRecorderConfig) *config.RecordQueueConfig {
+	if cfg == nil {
+		return nil
+	}
+	return cfg.Queue
+}
+
+// watch reloads on SIGHUP and on write of the config file
+func (r *ConfigReloader) watch(ctx context.Context) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("watch config: %w", err)
+	}
+	// watch the directory, editors replace the file on save
+	path := filepath.Clean(r.repo.ConfigPath())
+	if err := watcher.Add(filepath.Dir(path)); err != nil {
+		_ = watcher.Close()
+		return fmt.Errorf("watch config: %w", err)
+	}
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGHUP)
+
+	go func() {
+		defer signal.Stop(sigCh)
+		defer watcher.Close() // nolint:errcheck
+
+		var debounce <-chan time.Time
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-sigCh:
+				r.reload(ReloadTriggerSignal)
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if filepath.Clean(event.Name) == path && event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename) != 0 {
+					debounce = time.After(reloadDebounce)
+				}
+			case <-debounce:
+				debounce = nil
+				r.reload(ReloadTriggerFile)
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				log.Warnf("watch config: %s", err)
+			}
+		}
+	}()
+	return nil
+}
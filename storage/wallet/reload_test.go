@@ -0,0 +1,85 @@
+package wallet
+
+import (
+	"context"
+	"testing"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/venus/venus-shared/types"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/filecoin-project/venus-wallet/config"
+	"github.com/filecoin-project/venus-wallet/filemgr"
+)
+
+func TestConfigReloader(t *testing.T) {
+	ctx := context.Background()
+	repo, err := filemgr.NewFS(t.TempDir(), nil)
+	assert.NoError(t, err)
+	cnf := repo.Config()
+
+	signer, err := address.NewIDAddress(1000)
+	assert.NoError(t, err)
+	signMsg := SignMsg{SignType: types.MTUnknown, Signer: signer, Data: []byte("hello")}
+
+	signFilter := NewSignFilter(cnf.SignFilter)
+	signPlugin, err := NewSignPlugin(cnf.SignPlugin)
+	assert.NoError(t, err)
+	dealPolicy, err := NewDealPolicy(cnf.DealPolicy)
+	assert.NoError(t, err)
+	blind, err := NewBlindSignPolicy(cnf.BlindSign)
+	assert.NoError(t, err)
+	limiter, err := NewRateLimiter(cnf.RateLimit)
+	assert.NoError(t, err)
+	r, err := NewConfigReloader(nil, repo, signFilter, signPlugin, dealPolicy, blind, limiter,
+		newTestApprovalQueue(t, cnf.Approval), newTestQuorumQueue(t, cnf.Quorum), nil)
+	assert.NoError(t, err)
+
+	res, err := r.ConfigReloadResult(ctx)
+	assert.NoError(t, err)
+	assert.Nil(t, res)
+	assert.ErrorIs(t, blind.CheckSignMsg(ctx, signMsg), ErrBlindSignNotAllowed)
+
+	// a bad section fails the whole reload
+	newCnf, err := repo.ReadConfig()
+	assert.NoError(t, err)
+	newCnf.BlindSign.Addresses = []string{signer.String()}
+	newCnf.RateLimit = &config.RateLimitConfig{Enable: true, Rules: []config.RateLimitRule{{Limit: 1, Interval: "bad"}}}
+	assert.NoError(t, config.CoverConfig(repo.ConfigPath(), newCnf))
+
+	res, err = r.ConfigReload(ctx)
+	assert.Error(t, err)
+	assert.False(t, res.Success)
+	assert.Contains(t, res.Err, "RateLimit")
+	assert.ErrorIs(t, blind.CheckSignMsg(ctx, signMsg), ErrBlindSignNotAllowed)
+	assert.Empty(t, repo.Config().BlindSign.Addresses)
+
+	newCnf.RateLimit.Rules[0].Interval = "1m"
+	assert.NoError(t, config.CoverConfig(repo.ConfigPath(), newCnf))
+
+	res, err = r.ConfigReload(ctx)
+	assert.NoError(t, err)
+	assert.True(t, res.Success)
+	assert.Equal(t, ReloadTriggerAPI, res.Trigger)
+	assert.ElementsMatch(t, []string{"BlindSign", "RateLimit"}, res.Changed)
+	assert.Empty(t, res.RestartRequired)
+	assert.NoError(t, blind.CheckSignMsg(ctx, signMsg))
+	assert.NoError(t, limiter.Allow(ctx, signer, types.MTUnknown))
+	assert.ErrorIs(t, limiter.Allow(ctx, signer, types.MTUnknown), ErrRateLimited)
+	assert.Equal(t, []string{signer.String()}, repo.Config().BlindSign.Addresses)
+
+	last, err := r.ConfigReloadResult(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, res, last)
+
+	// the recorder section in use is replaced, never changed in place
+	inUse := &config.SignRecorderConfig{Enable: true, KeepDuration: "24h"}
+	repo.UpdateConfig(func(c *config.Config) { c.SignRecorder = inUse })
+	newCnf.SignRecorder = &config.SignRecorderConfig{Enable: true, KeepDuration: "48h"}
+	assert.NoError(t, config.CoverConfig(repo.ConfigPath(), newCnf))
+	res, err = r.ConfigReload(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"SignRecorder.Retention"}, res.Changed)
+	assert.Equal(t, "24h", inUse.KeepDuration)
+	assert.Equal(t, "48h", repo.Config().SignRecorder.KeepDuration)
+}
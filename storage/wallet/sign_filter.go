@@ -6,8 +6,11 @@ import (
 	"encoding/json"
 	"fmt"
 	"os/exec"
+	"sync/atomic"
 
+	"github.com/filecoin-project/go-address"
 	"github.com/filecoin-project/venus-wallet/config"
+	"github.com/filecoin-project/venus-wallet/middleware"
 
 	"github.com/filecoin-project/venus/venus-shared/types"
 )
@@ -16,21 +19,50 @@ type ISignMsgFilter interface {
 	CheckSignMsg(ctx context.Context, signMsg SignMsg) error
 }
 
+// FilterChain runs the filters in order and stops at the first rejection
+type FilterChain []ISignMsgFilter
+
+func (chain FilterChain) CheckSignMsg(ctx context.Context, signMsg SignMsg) error {
+	for _, filter := range chain {
+		if err := filter.CheckSignMsg(ctx, signMsg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 type SignMsg struct {
 	SignType types.MsgType
+	Signer   address.Address
 	Data     interface{}
+	// Caller who asks for the signature, nil for internal calls
+	Caller *middleware.Caller
+	// DryRun the request comes from a policy test and is never signed, the filters mustn't act on it
+	DryRun bool `json:",omitempty"`
 }
 
 type SignFilter struct {
-	cfg *config.SignFilter
+	cfg atomic.Pointer[config.SignFilter]
 }
 
 func NewSignFilter(cfg *config.SignFilter) *SignFilter {
-	return &SignFilter{cfg}
+	filter := &SignFilter{}
+	filter.cfg.Store(cfg)
+	return filter
+}
+
+// Reload the returned function applies the new config
+func (filter *SignFilter) Reload(cfg *config.SignFilter) (func(), error) {
+	return func() { filter.cfg.Store(cfg) }, nil
+}
+
+func (filter *SignFilter) Name() string {
+	return "sign_filter"
 }
 
 func (filter *SignFilter) CheckSignMsg(ctx context.Context, signMsg SignMsg) error {
-	if len(filter.cfg.Expr) == 0 {
+	cfg := filter.cfg.Load()
+	if cfg == nil || len(cfg.Expr) == 0 {
 		return nil
 	}
 
@@ -41,7 +73,7 @@ func (filter *SignFilter) CheckSignMsg(ctx context.Context, signMsg SignMsg) err
 
 	var out bytes.Buffer
 
-	c := exec.Command("sh", "-c", filter.cfg.Expr)
+	c := exec.Command("sh", "-c", cfg.Expr)
 	c.Stdin = bytes.NewReader(j)
 	c.Stdout = &out
 	c.Stderr = &out
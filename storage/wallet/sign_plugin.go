@@ -0,0 +1,307 @@
+package wallet
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/venus/venus-shared/types"
+
+	"github.com/filecoin-project/venus-wallet/config"
+	"github.com/filecoin-project/venus-wallet/middleware"
+)
+
+var (
+	ErrSignPluginRejected    = errors.New("sign request rejected by sign plugin")
+	ErrSignPluginUnavailable = errors.New("sign plugin unavailable")
+
+	// errPluginConnClosed the plugin closed the connection before answering, eg. it was restarted
+	errPluginConnClosed = errors.New("connection closed by the plugin")
+)
+
+// SignPluginVersion the version of the request schema sent to the plugin,
+// a plugin not supporting it should answer with an error
+const SignPluginVersion = 1
+
+// SignPluginMethod the json-rpc method called on the plugin
+const SignPluginMethod = "SignFilter.Check"
+
+// maxIdlePluginConns connections kept open to the plugin between requests
+const maxIdlePluginConns = 4
+
+// SignPluginRequest the params of SignPluginMethod
+type SignPluginRequest struct {
+	Version  int
+	SignType types.MsgType
+	Signer   address.Address
+	Data     interface{}
+	Caller   *middleware.Caller
+	// DryRun the request comes from a policy test and is never signed, the plugin mustn't act on it
+	DryRun bool `json:",omitempty"`
+}
+
+// SignPluginResponse the result of SignPluginMethod
+type SignPluginResponse struct {
+	Allow  bool
+	Reason string
+}
+
+// the plugin speaks json-rpc 2.0, one json object per line
+type pluginRPCRequest struct {
+	JSONRPC string        `json:"jsonrpc"`
+	ID      uint64        `json:"id"`
+	Method  string        `json:"method"`
+	Params  []interface{} `json:"params"`
+}
+
+type pluginRPCError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type pluginRPCResponse struct {
+	ID     uint64              `json:"id"`
+	Result *SignPluginResponse `json:"result"`
+	Error  *pluginRPCError     `json:"error"`
+}
+
+type signPluginRules struct {
+	enable   bool
+	network  string
+	address  string
+	timeout  time.Duration
+	failOpen bool
+}
+
+func parseSignPluginRules(cfg *config.SignPluginConfig) (*signPluginRules, error) {
+	rules := &signPluginRules{timeout: 5 * time.Second}
+	if cfg == nil || !cfg.Enable {
+		return rules, nil
+	}
+	rules.enable = true
+	rules.failOpen = cfg.FailOpen
+	switch {
+	case cfg.Endpoint == "":
+		return nil, fmt.Errorf("sign plugin endpoint is empty")
+	case strings.HasPrefix(cfg.Endpoint, "tcp://"):
+		rules.network, rules.address = "tcp", strings.TrimPrefix(cfg.Endpoint, "tcp://")
+	default:
+		rules.network, rules.address = "unix", strings.TrimPrefix(cfg.Endpoint, "unix://")
+	}
+	if cfg.Timeout != "" {
+		d, err := time.ParseDuration(cfg.Timeout)
+		if err != nil {
+			return nil, fmt.Errorf("parse sign plugin timeout: %w", err)
+		}
+		if d <= 0 {
+			return nil, fmt.Errorf("sign plugin timeout must be positive")
+		}
+		rules.timeout = d
+	}
+	return rules, nil
+}
+
+type pluginConn struct {
+	net.Conn
+	reader *bufio.Reader
+}
+
+var _ ISignMsgFilter = &SignPlugin{}
+
+// SignPlugin checks the sign requests with a long-running external process listening on a local socket.
+// Connections are kept open and reused between requests.
+type SignPlugin struct {
+	rules atomic.Pointer[signPluginRules]
+	id    atomic.Uint64
+
+	lk   sync.Mutex
+	idle []*pluginConn
+}
+
+func NewSignPlugin(cfg *config.SignPluginConfig) (*SignPlugin, error) {
+	rules, err := parseSignPluginRules(cfg)
+	if err != nil {
+		return nil, err
+	}
+	p := &SignPlugin{}
+	p.rules.Store(rules)
+	return p, nil
+}
+
+// Reload validates the new config, the returned function applies it.
+// Open connections are dropped, as the endpoint may have changed.
+func (p *SignPlugin) Reload(cfg *config.SignPluginConfig) (func(), error) {
+	rules, err := parseSignPluginRules(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return func() {
+		p.lk.Lock()
+		defer p.lk.Unlock()
+		p.rules.Store(rules)
+		for _, conn := range p.idle {
+			_ = conn.Close()
+		}
+		p.idle = nil
+	}, nil
+}
+
+func (p *SignPlugin) Name() string {
+	return "sign_plugin"
+}
+
+func (p *SignPlugin) CheckSignMsg(ctx context.Context, signMsg SignMsg) error {
+	rules := p.rules.Load()
+	if !rules.enable {
+		return nil
+	}
+
+	res, err := p.call(ctx, rules, &SignPluginRequest{
+		Version:  SignPluginVersion,
+		SignType: signMsg.SignType,
+		Signer:   signMsg.Signer,
+		Data:     signMsg.Data,
+		Caller:   signMsg.Caller,
+		DryRun:   signMsg.DryRun,
+	})
+	if err != nil {
+		if rules.failOpen {
+			log.Warnf("sign plugin failed, allow %s from %s: %s", signMsg.SignType, signMsg.Signer, err)
+			return nil
+		}
+		return fmt.Errorf("%w: %s", ErrSignPluginUnavailable, err)
+	}
+	if !res.Allow {
+		return fmt.Errorf("%w: %s", ErrSignPluginRejected, res.Reason)
+	}
+	return nil
+}
+
+func (p *SignPlugin) call(ctx context.Context, rules *signPluginRules, req *SignPluginRequest) (*SignPluginResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, rules.timeout)
+	defer cancel()
+
+	conn, pooled, err := p.conn(ctx, rules)
+	if err != nil {
+		return nil, err
+	}
+	res, err := p.roundTrip(ctx, conn, req)
+	if err != nil && pooled && errors.Is(err, errPluginConnClosed) {
+		// the plugin restarted since the connection was pooled, the other idle ones are gone too
+		_ = conn.Close()
+		p.closeIdle()
+		if conn, err = p.dial(ctx, rules); err != nil {
+			return nil, err
+		}
+		res, err = p.roundTrip(ctx, conn, req)
+	}
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	p.release(rules, conn)
+	return res, nil
+}
+
+func (p *SignPlugin) roundTrip(ctx context.Context, conn *pluginConn, req *SignPluginRequest) (*SignPluginResponse, error) {
+	deadline, _ := ctx.Deadline()
+	if err := conn.SetDeadline(deadline); err != nil {
+		return nil, err
+	}
+	// unblock the read when the caller goes away
+	stop := context.AfterFunc(ctx, func() { _ = conn.SetDeadline(time.Now()) })
+	defer stop()
+
+	id := p.id.Add(1)
+	data, err := json.Marshal(&pluginRPCRequest{
+		JSONRPC: "2.0",
+		ID:      id,
+		Method:  SignPluginMethod,
+		Params:  []interface{}{req},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if _, err := conn.Write(append(data, '\n')); err != nil {
+		if ctx.Err() == nil {
+			err = fmt.Errorf("%w: %w", errPluginConnClosed, err)
+		}
+		return nil, fmt.Errorf("write request: %w", err)
+	}
+
+	line, err := conn.reader.ReadBytes('\n')
+	if err != nil {
+		if len(line) == 0 && (errors.Is(err, io.EOF) || errors.Is(err, syscall.ECONNRESET)) {
+			err = fmt.Errorf("%w: %w", errPluginConnClosed, err)
+		}
+		return nil, fmt.Errorf("read response: %w", err)
+	}
+	var res pluginRPCResponse
+	if err := json.Unmarshal(line, &res); err != nil {
+		return nil, fmt.Errorf("decode response: %w", err)
+	}
+	if res.ID != id {
+		return nil, fmt.Errorf("unexpected response id %d, expect %d", res.ID, id)
+	}
+	if res.Error != nil {
+		return nil, fmt.Errorf("plugin error %d: %s", res.Error.Code, res.Error.Message)
+	}
+	if res.Result == nil {
+		return nil, fmt.Errorf("empty result")
+	}
+	return res.Result, nil
+}
+
+// conn an idle connection if there is one, pooled tells which
+func (p *SignPlugin) conn(ctx context.Context, rules *signPluginRules) (conn *pluginConn, pooled bool, err error) {
+	p.lk.Lock()
+	if n := len(p.idle); n > 0 {
+		conn := p.idle[n-1]
+		p.idle = p.idle[:n-1]
+		p.lk.Unlock()
+		return conn, true, nil
+	}
+	p.lk.Unlock()
+
+	conn, err = p.dial(ctx, rules)
+	return conn, false, err
+}
+
+func (p *SignPlugin) dial(ctx context.Context, rules *signPluginRules) (*pluginConn, error) {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, rules.network, rules.address)
+	if err != nil {
+		return nil, fmt.Errorf("dial %s: %w", rules.address, err)
+	}
+	return &pluginConn{Conn: conn, reader: bufio.NewReader(conn)}, nil
+}
+
+func (p *SignPlugin) closeIdle() {
+	p.lk.Lock()
+	defer p.lk.Unlock()
+	for _, conn := range p.idle {
+		_ = conn.Close()
+	}
+	p.idle = nil
+}
+
+func (p *SignPlugin) release(rules *signPluginRules, conn *pluginConn) {
+	p.lk.Lock()
+	defer p.lk.Unlock()
+	// drop the connections opened before a reload
+	if p.rules.Load() != rules || len(p.idle) >= maxIdlePluginConns {
+		_ = conn.Close()
+		return
+	}
+	p.idle = append(p.idle, conn)
+}
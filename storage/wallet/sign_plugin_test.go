@@ -0,0 +1,144 @@
+package wallet
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/venus/venus-shared/types"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/filecoin-project/venus-wallet/config"
+)
+
+// servePlugin allows the messages sent to method 0, and stalls on method 99. The rejections of a dry run say so.
+// restart closes the open connections, as a restart of the plugin would.
+func servePlugin(t *testing.T, path string) (dials *atomic.Int32, restart func()) {
+	lst, err := net.Listen("unix", path)
+	assert.NoError(t, err)
+	t.Cleanup(func() { _ = lst.Close() })
+
+	dials = new(atomic.Int32)
+	var (
+		lk    sync.Mutex
+		conns []net.Conn
+	)
+	restart = func() {
+		lk.Lock()
+		defer lk.Unlock()
+		for _, conn := range conns {
+			_ = conn.Close()
+		}
+		conns = nil
+	}
+	go func() {
+		for {
+			conn, err := lst.Accept()
+			if err != nil {
+				return
+			}
+			dials.Add(1)
+			lk.Lock()
+			conns = append(conns, conn)
+			lk.Unlock()
+			go func() {
+				defer conn.Close() // nolint:errcheck
+				scanner := bufio.NewScanner(conn)
+				for scanner.Scan() {
+					var req struct {
+						ID     uint64
+						Params []struct {
+							Version int
+							Data    types.Message
+							DryRun  bool
+						}
+					}
+					if err := json.Unmarshal(scanner.Bytes(), &req); err != nil {
+						return
+					}
+					var res interface{}
+					switch params := req.Params[0]; {
+					case params.Version != SignPluginVersion:
+						res = map[string]interface{}{"id": req.ID, "error": map[string]interface{}{"code": 1, "message": "unsupported version"}}
+					case params.Data.Method == 99:
+						time.Sleep(time.Second)
+						continue
+					default:
+						reason := "only sends are allowed"
+						if params.DryRun {
+							reason += " (dry run)"
+						}
+						res = map[string]interface{}{"id": req.ID, "result": SignPluginResponse{
+							Allow:  params.Data.Method == 0,
+							Reason: reason,
+						}}
+					}
+					data, _ := json.Marshal(res)
+					if _, err := conn.Write(append(data, '\n')); err != nil {
+						return
+					}
+				}
+			}()
+		}
+	}()
+	return dials, restart
+}
+
+func TestSignPlugin(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "plugin.sock")
+	dials, restart := servePlugin(t, path)
+
+	from, err := address.NewIDAddress(1000)
+	assert.NoError(t, err)
+	signMsg := func(method uint64) SignMsg {
+		return SignMsg{
+			SignType: types.MTChainMsg,
+			Signer:   from,
+			Data:     &types.Message{From: from, To: from, Method: abi.MethodNum(method)},
+		}
+	}
+
+	plugin, err := NewSignPlugin(&config.SignPluginConfig{Enable: true, Endpoint: path, Timeout: "100ms"})
+	assert.NoError(t, err)
+
+	assert.NoError(t, plugin.CheckSignMsg(ctx, signMsg(0)))
+	assert.NoError(t, plugin.CheckSignMsg(ctx, signMsg(0)))
+	assert.EqualValues(t, 1, dials.Load(), "connection is reused")
+	err = plugin.CheckSignMsg(ctx, signMsg(2))
+	assert.ErrorIs(t, err, ErrSignPluginRejected)
+	assert.NotContains(t, err.Error(), "dry run")
+	dryRun := signMsg(2)
+	dryRun.DryRun = true
+	assert.ErrorContains(t, plugin.CheckSignMsg(ctx, dryRun), "dry run")
+
+	// the pooled connection closed by a restart is retried on a new one
+	restart()
+	assert.NoError(t, plugin.CheckSignMsg(ctx, signMsg(0)))
+	assert.EqualValues(t, 2, dials.Load())
+
+	assert.ErrorIs(t, plugin.CheckSignMsg(ctx, signMsg(99)), ErrSignPluginUnavailable)
+
+	// fail open only covers the plugin failures, not its decisions
+	apply, err := plugin.Reload(&config.SignPluginConfig{Enable: true, Endpoint: path, Timeout: "100ms", FailOpen: true})
+	assert.NoError(t, err)
+	apply()
+	assert.NoError(t, plugin.CheckSignMsg(ctx, signMsg(99)))
+	assert.ErrorIs(t, plugin.CheckSignMsg(ctx, signMsg(2)), ErrSignPluginRejected)
+
+	apply, err = plugin.Reload(&config.SignPluginConfig{Enable: true, Endpoint: path + ".missing"})
+	assert.NoError(t, err)
+	apply()
+	assert.ErrorIs(t, plugin.CheckSignMsg(ctx, signMsg(0)), ErrSignPluginUnavailable)
+
+	_, err = plugin.Reload(&config.SignPluginConfig{Enable: true})
+	assert.Error(t, err)
+}
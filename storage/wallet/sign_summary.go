@@ -0,0 +1,37 @@
+package wallet
+
+import (
+	"fmt"
+
+	"github.com/filecoin-project/specs-actors/v2/actors/builtin/market"
+	"github.com/filecoin-project/specs-actors/v2/actors/builtin/paych"
+	"github.com/filecoin-project/venus/venus-shared/types"
+)
+
+// signSummary a short human readable description of the sign object, and its cid where one applies
+func signSummary(obj interface{}) (summary string, msgCID string) {
+	switch o := obj.(type) {
+	case *types.Message:
+		return fmt.Sprintf("to %s value %s method %d nonce %d gas limit %d fee cap %s premium %s",
+			o.To, types.FIL(o.Value), o.Method, o.Nonce, o.GasLimit, types.FIL(o.GasFeeCap), types.FIL(o.GasPremium)), o.Cid().String()
+	case *types.BlockHeader:
+		return fmt.Sprintf("miner %s height %d parents %d", o.Miner, o.Height, len(o.Parents)), o.Cid().String()
+	case *market.ClientDealProposal:
+		return dealSummary(&o.Proposal), ""
+	case *market.DealProposal:
+		return dealSummary(o), ""
+	case *paych.SignedVoucher:
+		return fmt.Sprintf("channel %s lane %d nonce %d amount %s", o.ChannelAddr, o.Lane, o.Nonce, types.FIL(o.Amount)), ""
+	case []byte:
+		return fmt.Sprintf("%d bytes", len(o)), ""
+	case nil:
+		return "", ""
+	default:
+		return fmt.Sprintf("%T", obj), ""
+	}
+}
+
+func dealSummary(p *market.DealProposal) string {
+	return fmt.Sprintf("provider %s client %s piece %s size %d price per epoch %s epochs %d-%d verified %t",
+		p.Provider, p.Client, p.PieceCID, p.PieceSize, types.FIL(p.StoragePricePerEpoch), p.StartEpoch, p.EndEpoch, p.VerifiedDeal)
+}
@@ -0,0 +1,104 @@
+package wallet
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/venus/venus-shared/types"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/filecoin-project/venus-wallet/crypto"
+	"github.com/filecoin-project/venus-wallet/storage"
+)
+
+func TestWallet_SignRecordComplete(t *testing.T) {
+	w, ctx := newTestWallet(t)
+	setTestRecorder(t, w)
+
+	addr, err := w.WalletNew(ctx, types.KTSecp256k1)
+	assert.NoError(t, err)
+
+	msg := &types.Message{From: addr, To: addr, Value: abi.NewTokenAmount(1), Method: 2, Nonce: 7}
+	extra, err := msg.Serialize()
+	assert.NoError(t, err)
+	sig, err := w.WalletSign(ctx, addr, msg.Cid().Bytes(), types.MsgMeta{Type: types.MTChainMsg, Extra: extra})
+	assert.NoError(t, err)
+
+	var records []storage.SignRecord
+	assert.Eventually(t, func() bool {
+		records, err = w.recorder.QueryRecord(&storage.RecordQueryParams{QueryParams: storage.QueryParams{Signer: addr}})
+		return err == nil && len(records) == 1
+	}, time.Second, 10*time.Millisecond)
+
+	r := records[0]
+	assert.Equal(t, sig, r.Signature)
+	assert.Equal(t, msg.Cid().String(), r.MsgCID)
+	assert.Equal(t, extra, r.Extra)
+	assert.Equal(t, msg.Cid().Bytes(), r.ToSign)
+	assert.Positive(t, r.Duration)
+	assert.Contains(t, r.Summary, "method 2 nonce 7")
+	assert.NoError(t, crypto.Verify(r.Signature, addr, r.ToSign))
+}
+
+// rejectFilter rejects the messages of the signer
+type rejectFilter struct {
+	signer address.Address
+	err    error
+}
+
+func (f *rejectFilter) CheckSignMsg(_ context.Context, signMsg SignMsg) error {
+	if signMsg.Signer == f.signer {
+		return f.err
+	}
+	return nil
+}
+
+func TestWallet_SignRecordRejected(t *testing.T) {
+	errRejected := errors.New("rejected by the plugin")
+	filter := &rejectFilter{err: errRejected}
+	w, ctx := newTestWallet(t)
+	setTestRecorder(t, w)
+	w.filter = filter
+
+	addr, err := w.WalletNew(ctx, types.KTSecp256k1)
+	assert.NoError(t, err)
+	filter.signer = addr
+	msg := &types.Message{From: addr, To: addr, Value: abi.NewTokenAmount(1)}
+	extra, err := msg.Serialize()
+	assert.NoError(t, err)
+	_, err = w.WalletSign(ctx, addr, msg.Cid().Bytes(), types.MsgMeta{Type: types.MTChainMsg, Extra: extra})
+	assert.ErrorIs(t, err, errRejected)
+
+	// a key missing from the keystore
+	other, err := address.NewSecp256k1Address([]byte("other"))
+	assert.NoError(t, err)
+	_, err = w.WalletSign(ctx, other, []byte("data"), types.MsgMeta{Type: types.MTUnknown})
+	assert.ErrorIs(t, err, storage.ErrKeyInfoNotFound)
+
+	var records []storage.SignRecord
+	assert.Eventually(t, func() bool {
+		records, err = w.recorder.QueryRecord(&storage.RecordQueryParams{QueryParams: storage.QueryParams{IsError: true}})
+		return err == nil && len(records) == 2
+	}, time.Second, 10*time.Millisecond)
+	errs := map[address.Address]string{}
+	for _, r := range records {
+		assert.Nil(t, r.Signature)
+		errs[r.Signer] = r.Err
+	}
+	assert.Equal(t, errRejected.Error(), errs[addr])
+	assert.NotEmpty(t, errs[other])
+}
+
+func TestSignSummary(t *testing.T) {
+	summary, msgCID := signSummary([]byte("hello"))
+	assert.Equal(t, "5 bytes", summary)
+	assert.Empty(t, msgCID)
+
+	summary, msgCID = signSummary(nil)
+	assert.Empty(t, summary)
+	assert.Empty(t, msgCID)
+}
@@ -0,0 +1,56 @@
+package wallet
+
+import (
+	"testing"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	c "github.com/filecoin-project/go-state-types/crypto"
+	"github.com/filecoin-project/venus/venus-shared/types"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/filecoin-project/venus-wallet/crypto"
+)
+
+// faultyKey flips a bit of every signature, like a hardware fault would
+type faultyKey struct {
+	crypto.PrivateKey
+}
+
+func (k *faultyKey) Sign(msg []byte) (*c.Signature, error) {
+	sig, err := k.PrivateKey.Sign(msg)
+	if err != nil {
+		return nil, err
+	}
+	sig.Data[len(sig.Data)/2] ^= 0x01
+	return sig, nil
+}
+
+func TestWallet_SignVerify(t *testing.T) {
+	w, ctx := newTestWallet(t)
+
+	for _, kt := range []types.KeyType{types.KTSecp256k1, types.KTBLS, types.KTDelegated} {
+		t.Run(string(kt), func(t *testing.T) {
+			addr, err := w.WalletNew(ctx, kt)
+			assert.NoError(t, err)
+
+			msg := &types.Message{From: addr, To: addr, Value: abi.NewTokenAmount(0)}
+			extra, err := msg.Serialize()
+			assert.NoError(t, err)
+			toSign := msg.Cid().Bytes()
+			meta := types.MsgMeta{Type: types.MTChainMsg, Extra: extra}
+
+			sig, err := w.WalletSign(ctx, addr, toSign, meta)
+			assert.NoError(t, err)
+			assert.NoError(t, crypto.Verify(sig, addr, toSign))
+
+			w.pushCache(addr, &faultyKey{PrivateKey: w.cacheKey(addr)})
+			sig, err = w.WalletSign(ctx, addr, toSign, meta)
+			assert.ErrorIs(t, err, ErrSignatureMismatch)
+			assert.Nil(t, sig)
+			// the faulty key is dropped, the next signature is decrypted from the keystore again
+			assert.Nil(t, w.cacheKey(addr))
+			_, err = w.WalletSign(ctx, addr, toSign, meta)
+			assert.NoError(t, err)
+		})
+	}
+}
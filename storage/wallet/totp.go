@@ -0,0 +1,302 @@
+package wallet
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1" // nolint:gosec // RFC 6238 default, what the authenticator apps support
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/filecoin-project/venus-wallet/middleware"
+	"github.com/filecoin-project/venus-wallet/storage"
+)
+
+var (
+	ErrTOTPRequired = errors.New("one-time code required")
+	ErrTOTPInvalid  = errors.New("invalid one-time code")
+	ErrTOTPLocked   = errors.New("too many invalid one-time codes")
+)
+
+const (
+	totpPeriod        = 30 // seconds
+	totpDigits        = 6
+	totpSecretSize    = 20
+	totpSkew          = 1 // time steps accepted before and after the current one
+	totpRecoveryCodes = 10
+	totpIssuer        = "venus-wallet"
+	// totpMaxFailures invalid codes in a row lock the codes out for totpLockout,
+	// doubled by each lockout in a row up to totpMaxLockout
+	totpMaxFailures = 5
+	totpLockout     = 5 * time.Minute
+	totpMaxLockout  = 24 * time.Hour
+)
+
+// TOTPEnrollment returned once by TOTPEnroll, the secret and the recovery codes can't be read again
+type TOTPEnrollment struct {
+	// Secret base32 encoded, to be entered in the authenticator app
+	Secret string
+	// URI otpauth uri, usually shown as a QR code
+	URI           string
+	RecoveryCodes []string
+}
+
+type TOTPStatus struct {
+	// Enrolled the one-time code is required
+	Enrolled bool
+	// Pending the enrollment waits for TOTPConfirm
+	Pending           bool
+	RecoveryCodesLeft int
+	// LockedUntil no code is accepted before, after too many invalid ones
+	LockedUntil time.Time
+}
+
+// ITOTP RFC 6238 one-time codes required on top of the password by Unlock, WalletExport, WalletDelete and SetPassword.
+// The code is sent in the middleware.OTPHeader header, a recovery code may be used instead.
+type ITOTP interface {
+	// TOTPEnroll generates the secret and the recovery codes, the code is required after TOTPConfirm
+	TOTPEnroll(ctx context.Context, password string) (*TOTPEnrollment, error)
+	// TOTPConfirm activates the enrollment with a code from the authenticator app
+	TOTPConfirm(ctx context.Context, code string) error
+	// TOTPDisable removes the second factor, the code is required as for the other operations
+	TOTPDisable(ctx context.Context, password string) error
+	TOTPStatus(ctx context.Context) (*TOTPStatus, error)
+}
+
+func (w *wallet) TOTPEnroll(ctx context.Context, password string) (*TOTPEnrollment, error) {
+	if err := w.mw.Next(); err != nil {
+		return nil, err
+	}
+	if err := w.mw.CheckToken(ctx); err != nil {
+		return nil, err
+	}
+	if err := w.mw.VerifyPassword(ctx, password); err != nil {
+		return nil, err
+	}
+
+	w.totpLk.Lock()
+	defer w.totpLk.Unlock()
+	rec, err := w.totp.GetTOTP()
+	if err != nil {
+		return nil, err
+	}
+	if rec != nil && rec.Active {
+		return nil, fmt.Errorf("totp already enrolled, disable it first")
+	}
+
+	secret := make([]byte, totpSecretSize)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, err
+	}
+	encrypted, err := w.mw.EncryptData(nil, secret)
+	if err != nil {
+		return nil, fmt.Errorf("encrypt totp secret: %w", err)
+	}
+	enrollment := &TOTPEnrollment{
+		Secret: base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(secret),
+	}
+	enrollment.URI = fmt.Sprintf("otpauth://totp/%s?%s", totpIssuer, url.Values{
+		"secret":    {enrollment.Secret},
+		"issuer":    {totpIssuer},
+		"algorithm": {"SHA1"},
+		"digits":    {fmt.Sprint(totpDigits)},
+		"period":    {fmt.Sprint(totpPeriod)},
+	}.Encode())
+
+	rec = &storage.TOTPRecord{Secret: encrypted, CreateAt: time.Now()}
+	for i := 0; i < totpRecoveryCodes; i++ {
+		b := make([]byte, 5)
+		if _, err := rand.Read(b); err != nil {
+			return nil, err
+		}
+		code := hex.EncodeToString(b)
+		code = code[:5] + "-" + code[5:]
+		enrollment.RecoveryCodes = append(enrollment.RecoveryCodes, code)
+		rec.RecoveryCodes = append(rec.RecoveryCodes, hashRecoveryCode(code))
+	}
+	if err := w.totp.PutTOTP(rec); err != nil {
+		return nil, err
+	}
+	log.Infof("totp enrollment started")
+	return enrollment, nil
+}
+
+func (w *wallet) TOTPConfirm(ctx context.Context, code string) error {
+	if err := w.mw.Next(); err != nil {
+		return err
+	}
+	if err := w.mw.CheckToken(ctx); err != nil {
+		return err
+	}
+
+	w.totpLk.Lock()
+	defer w.totpLk.Unlock()
+	rec, err := w.totp.GetTOTP()
+	if err != nil {
+		return err
+	}
+	if rec == nil || rec.Active {
+		return fmt.Errorf("no pending totp enrollment")
+	}
+	secret, err := w.mw.DecryptData(nil, rec.Secret)
+	if err != nil {
+		return fmt.Errorf("decrypt totp secret: %w", err)
+	}
+	counter, ok := matchTOTP(secret, code, time.Now(), rec.LastCounter)
+	if !ok {
+		return ErrTOTPInvalid
+	}
+	rec.Active = true
+	rec.LastCounter = counter
+	if err := w.totp.PutTOTP(rec); err != nil {
+		return err
+	}
+	log.Infof("totp enrolled")
+	return nil
+}
+
+func (w *wallet) TOTPDisable(ctx context.Context, password string) error {
+	if err := w.mw.Next(); err != nil {
+		return err
+	}
+	if err := w.mw.CheckToken(ctx); err != nil {
+		return err
+	}
+	if err := w.mw.VerifyPassword(ctx, password); err != nil {
+		return err
+	}
+	if err := w.checkTOTP(ctx, nil); err != nil {
+		return err
+	}
+	if err := w.totp.DeleteTOTP(); err != nil {
+		return err
+	}
+	log.Warnf("totp disabled by %s", callerName(middleware.CallerFromContext(ctx)))
+	return nil
+}
+
+func (w *wallet) TOTPStatus(ctx context.Context) (*TOTPStatus, error) {
+	rec, err := w.totp.GetTOTP()
+	if err != nil {
+		return nil, err
+	}
+	if rec == nil {
+		return &TOTPStatus{}, nil
+	}
+	return &TOTPStatus{
+		Enrolled:          rec.Active,
+		Pending:           !rec.Active,
+		RecoveryCodesLeft: len(rec.RecoveryCodes),
+		LockedUntil:       rec.LockedUntil,
+	}, nil
+}
+
+// checkTOTP verifies the one-time code sent with the request, password decrypts the secret, the password in use if nil.
+// Requests without caller come from within the process, eg. the password given on the command line, they need no code.
+func (w *wallet) checkTOTP(ctx context.Context, password []byte) error {
+	if middleware.CallerFromContext(ctx) == nil {
+		return nil
+	}
+
+	w.totpLk.Lock()
+	defer w.totpLk.Unlock()
+	rec, err := w.totp.GetTOTP()
+	if err != nil {
+		return fmt.Errorf("get totp: %w", err)
+	}
+	if rec == nil || !rec.Active {
+		return nil
+	}
+	code := strings.TrimSpace(middleware.OTPFromContext(ctx))
+	if code == "" {
+		return ErrTOTPRequired
+	}
+	now := time.Now()
+	if now.Before(rec.LockedUntil) {
+		return fmt.Errorf("%w, retry after %s", ErrTOTPLocked, rec.LockedUntil.Format(time.RFC3339))
+	}
+
+	if len(code) == totpDigits {
+		secret, err := w.mw.DecryptData(password, rec.Secret)
+		if err != nil {
+			return fmt.Errorf("decrypt totp secret: %w", err)
+		}
+		counter, ok := matchTOTP(secret, code, now, rec.LastCounter)
+		if !ok {
+			return w.totpFailed(rec, now)
+		}
+		rec.LastCounter = counter
+		rec.Failures = 0
+		return w.totp.PutTOTP(rec)
+	}
+
+	hash := hashRecoveryCode(code)
+	for i, h := range rec.RecoveryCodes {
+		if hmac.Equal([]byte(h), []byte(hash)) {
+			rec.RecoveryCodes = append(rec.RecoveryCodes[:i:i], rec.RecoveryCodes[i+1:]...)
+			rec.Failures = 0
+			log.Warnf("recovery code used, %d left", len(rec.RecoveryCodes))
+			return w.totp.PutTOTP(rec)
+		}
+	}
+	return w.totpFailed(rec, now)
+}
+
+// totpFailed counts the invalid code, and locks the codes out after totpMaxFailures in a row
+func (w *wallet) totpFailed(rec *storage.TOTPRecord, now time.Time) error {
+	rec.Failures++
+	if rec.Failures%totpMaxFailures == 0 {
+		lockout := totpLockout
+		for i := 1; i < rec.Failures/totpMaxFailures && lockout < totpMaxLockout; i++ {
+			lockout *= 2
+		}
+		if lockout > totpMaxLockout {
+			lockout = totpMaxLockout
+		}
+		rec.LockedUntil = now.Add(lockout)
+		log.Warnf("%d invalid one-time codes in a row, locked out for %s", rec.Failures, lockout)
+	}
+	if err := w.totp.PutTOTP(rec); err != nil {
+		return err
+	}
+	return ErrTOTPInvalid
+}
+
+func hashRecoveryCode(code string) string {
+	code = strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+	h := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(h[:])
+}
+
+// matchTOTP returns the time step the code matches, a step up to lastCounter is never accepted again
+func matchTOTP(secret []byte, code string, now time.Time, lastCounter int64) (int64, bool) {
+	cur := now.Unix() / totpPeriod
+	for c := cur - totpSkew; c <= cur+totpSkew; c++ {
+		if c <= lastCounter {
+			continue
+		}
+		if hmac.Equal([]byte(totpCode(secret, c)), []byte(code)) {
+			return c, true
+		}
+	}
+	return 0, false
+}
+
+// totpCode RFC 4226 HOTP of the time step
+func totpCode(secret []byte, counter int64) string {
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], uint64(counter))
+	mac := hmac.New(sha1.New, secret)
+	mac.Write(msg[:]) // nolint:errcheck
+	sum := mac.Sum(nil)
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	return fmt.Sprintf("%0*d", totpDigits, value%1000000)
+}
@@ -0,0 +1,162 @@
+package wallet
+
+import (
+	"encoding/base32"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/filecoin-project/venus/venus-shared/types"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/filecoin-project/venus-wallet/middleware"
+	"github.com/filecoin-project/venus-wallet/storage"
+	walletsqlite "github.com/filecoin-project/venus-wallet/storage/sqlite"
+)
+
+func newTestTOTPStore(t *testing.T) storage.ITOTPStore {
+	db, err := gorm.Open(sqlite.Open(fmt.Sprintf("file:%s-totp?mode=memory&cache=shared", t.Name())), &gorm.Config{})
+	assert.NoError(t, err)
+	store, err := walletsqlite.NewTOTPStore(db)
+	assert.NoError(t, err)
+	return store
+}
+
+func TestTOTPCode(t *testing.T) {
+	// RFC 6238 appendix B, SHA1, truncated to 6 digits
+	secret := []byte("12345678901234567890")
+	for unix, code := range map[int64]string{
+		59:         "287082",
+		1111111109: "081804",
+		1234567890: "005924",
+		2000000000: "279037",
+	} {
+		assert.Equal(t, code, totpCode(secret, unix/totpPeriod))
+	}
+
+	now := time.Unix(1111111109, 0)
+	counter, ok := matchTOTP(secret, "081804", now, 0)
+	assert.True(t, ok)
+	// replay of the same step
+	_, ok = matchTOTP(secret, "081804", now, counter)
+	assert.False(t, ok)
+	// one step late is accepted
+	_, ok = matchTOTP(secret, "081804", now.Add(totpPeriod*time.Second), 0)
+	assert.True(t, ok)
+	_, ok = matchTOTP(secret, "081804", now.Add(3*totpPeriod*time.Second), 0)
+	assert.False(t, ok)
+}
+
+func TestWallet_TOTP(t *testing.T) {
+	w, adminCtx := newTestWallet(t)
+	addr, err := w.WalletNew(adminCtx, types.KTSecp256k1)
+	assert.NoError(t, err)
+
+	ctx := middleware.WithCaller(adminCtx, &middleware.Caller{TokenID: "admin"})
+	_, err = w.TOTPEnroll(ctx, "wrong")
+	assert.Error(t, err)
+	enrollment, err := w.TOTPEnroll(ctx, "password")
+	assert.NoError(t, err)
+	assert.Len(t, enrollment.RecoveryCodes, totpRecoveryCodes)
+	assert.Contains(t, enrollment.URI, "otpauth://totp/")
+	secret, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(enrollment.Secret)
+	assert.NoError(t, err)
+
+	status, err := w.TOTPStatus(ctx)
+	assert.NoError(t, err)
+	assert.True(t, status.Pending)
+	assert.False(t, status.Enrolled)
+
+	// a pending enrollment requires nothing yet
+	_, err = w.WalletExport(ctx, addr)
+	assert.NoError(t, err)
+
+	now := time.Now().Unix() / totpPeriod
+	assert.ErrorIs(t, w.TOTPConfirm(ctx, "000000x"), ErrTOTPInvalid)
+	assert.NoError(t, w.TOTPConfirm(ctx, totpCode(secret, now-1)))
+
+	_, err = w.WalletExport(ctx, addr)
+	assert.ErrorIs(t, err, ErrTOTPRequired)
+	// the step used to confirm can't be used again
+	_, err = w.WalletExport(middleware.WithOTP(ctx, totpCode(secret, now-1)), addr)
+	assert.ErrorIs(t, err, ErrTOTPInvalid)
+	_, err = w.WalletExport(middleware.WithOTP(ctx, totpCode(secret, now)), addr)
+	assert.NoError(t, err)
+
+	assert.NoError(t, w.Lock(ctx, "password"))
+	assert.ErrorIs(t, w.Unlock(ctx, "password"), ErrTOTPRequired)
+	assert.NoError(t, w.Unlock(middleware.WithOTP(ctx, enrollment.RecoveryCodes[0]), "password"))
+	assert.NoError(t, w.Lock(ctx, "password"))
+	// recovery codes work once
+	assert.ErrorIs(t, w.Unlock(middleware.WithOTP(ctx, enrollment.RecoveryCodes[0]), "password"), ErrTOTPInvalid)
+	assert.NoError(t, w.Unlock(middleware.WithOTP(ctx, enrollment.RecoveryCodes[1]), "password"))
+
+	status, err = w.TOTPStatus(ctx)
+	assert.NoError(t, err)
+	assert.True(t, status.Enrolled)
+	assert.Equal(t, totpRecoveryCodes-2, status.RecoveryCodesLeft)
+
+	// in-process calls need no code
+	_, err = w.WalletExport(adminCtx, addr)
+	assert.NoError(t, err)
+
+	assert.ErrorIs(t, w.TOTPDisable(ctx, "password"), ErrTOTPRequired)
+	assert.NoError(t, w.TOTPDisable(middleware.WithOTP(ctx, enrollment.RecoveryCodes[2]), "password"))
+	_, err = w.WalletExport(ctx, addr)
+	assert.NoError(t, err)
+}
+
+func TestWallet_TOTPLockout(t *testing.T) {
+	w, adminCtx := newTestWallet(t)
+	addr, err := w.WalletNew(adminCtx, types.KTSecp256k1)
+	assert.NoError(t, err)
+
+	ctx := middleware.WithCaller(adminCtx, &middleware.Caller{TokenID: "admin"})
+	enrollment, err := w.TOTPEnroll(ctx, "password")
+	assert.NoError(t, err)
+	secret, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(enrollment.Secret)
+	assert.NoError(t, err)
+	now := time.Now().Unix() / totpPeriod
+	assert.NoError(t, w.TOTPConfirm(ctx, totpCode(secret, now-1)))
+
+	wrong := middleware.WithOTP(ctx, "000000")
+	if totpCode(secret, now) == "000000" || totpCode(secret, now+1) == "000000" {
+		wrong = middleware.WithOTP(ctx, "111111")
+	}
+	for i := 0; i < totpMaxFailures; i++ {
+		_, err = w.WalletExport(wrong, addr)
+		assert.ErrorIs(t, err, ErrTOTPInvalid)
+	}
+	// even the valid code is refused while locked out
+	_, err = w.WalletExport(middleware.WithOTP(ctx, totpCode(secret, now)), addr)
+	assert.ErrorIs(t, err, ErrTOTPLocked)
+	status, err := w.TOTPStatus(ctx)
+	assert.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(totpLockout), status.LockedUntil, time.Minute)
+
+	// the next lockout in a row lasts twice as long
+	rec, err := w.totp.GetTOTP()
+	assert.NoError(t, err)
+	rec.LockedUntil = time.Now().Add(-time.Second)
+	assert.NoError(t, w.totp.PutTOTP(rec))
+	for i := 0; i < totpMaxFailures; i++ {
+		_, err = w.WalletExport(wrong, addr)
+		assert.ErrorIs(t, err, ErrTOTPInvalid)
+	}
+	status, err = w.TOTPStatus(ctx)
+	assert.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(2*totpLockout), status.LockedUntil, time.Minute)
+
+	// a valid code after the lockout resets the count
+	rec, err = w.totp.GetTOTP()
+	assert.NoError(t, err)
+	rec.LockedUntil = time.Now().Add(-time.Second)
+	assert.NoError(t, w.totp.PutTOTP(rec))
+	_, err = w.WalletExport(middleware.WithOTP(ctx, totpCode(secret, now)), addr)
+	assert.NoError(t, err)
+	rec, err = w.totp.GetTOTP()
+	assert.NoError(t, err)
+	assert.Zero(t, rec.Failures)
+}
@@ -2,8 +2,11 @@ package wallet
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/filecoin-project/go-address"
 	cborutil "github.com/filecoin-project/go-cbor-util"
@@ -14,18 +17,37 @@ import (
 	"github.com/filecoin-project/venus/venus-shared/types"
 	w_types "github.com/filecoin-project/venus/venus-shared/types/wallet"
 	logging "github.com/ipfs/go-log/v2"
+	"go.opencensus.io/stats"
+	"go.opencensus.io/tag"
+	"go.uber.org/fx"
 
 	c "github.com/filecoin-project/go-state-types/crypto"
+	"github.com/filecoin-project/venus-wallet/config"
 	"github.com/filecoin-project/venus-wallet/crypto"
 	"github.com/filecoin-project/venus-wallet/crypto/aes"
+	"github.com/filecoin-project/venus-wallet/middleware"
 	"github.com/filecoin-project/venus-wallet/storage"
 )
 
 var log = logging.Logger("wallet")
 
+var ErrSignatureMismatch = errors.New("produced signature failed verification")
+
 type GetPwdFunc func() string
 
-var _ wallet_api.IWallet = &wallet{}
+var _ ILocalWallet = &wallet{}
+
+// ILocalWallet the venus wallet api with the local extensions
+type ILocalWallet interface {
+	wallet_api.ILocalWallet
+	IPolicy
+	IKeyRole
+	IKeySuspend
+	IPanic
+	ITOTP
+	IKeyExport
+	IEvents
+}
 
 // wallet implementation
 type wallet struct {
@@ -36,19 +58,63 @@ type wallet struct {
 	filter   ISignMsgFilter
 	m        sync.RWMutex
 	recorder storage.IRecorder
+	records  *RecordQueue
+	approval *ApprovalQueue
+	quorum   *QuorumQueue
+	limiter  *RateLimiter
+	blind    *BlindSignPolicy
+	meta     storage.IKeyMetaStore
+	panics   storage.IPanicStore
+	panicked atomic.Bool // panic locked, and not unlocked yet
+	totp     storage.ITOTPStore
+	totpLk   sync.Mutex
+	events   *eventHub
+	// exportCfg only read at startup
+	exportCfg *config.KeyExportConfig
 }
 
-func NewWallet(ks storage.KeyStore, rd storage.IRecorder, mw storage.KeyMiddleware, filter ISignMsgFilter, bus EventBus.Bus, getPwd GetPwdFunc) wallet_api.ILocalWallet {
+// WalletParams the dependencies of the wallet
+type WalletParams struct {
+	fx.In
+	KeyStore   storage.KeyStore
+	Recorder   storage.IRecorder
+	Records    *RecordQueue
+	Middleware storage.KeyMiddleware
+	Filter     ISignMsgFilter
+	Approval   *ApprovalQueue
+	Quorum     *QuorumQueue
+	Limiter    *RateLimiter
+	BlindSign  *BlindSignPolicy
+	KeyMeta    storage.IKeyMetaStore
+	Panics     storage.IPanicStore
+	TOTP       storage.ITOTPStore
+	// ExportCfg only read at startup
+	ExportCfg *config.KeyExportConfig
+	Bus       EventBus.Bus
+	GetPwd    GetPwdFunc `optional:"true"`
+}
+
+func NewWallet(p WalletParams) ILocalWallet {
 	w := &wallet{
-		ws:       ks,
-		recorder: rd,
-		mw:       mw,
-		bus:      bus,
-		filter:   filter,
-		keyCache: make(map[string]crypto.PrivateKey),
-	}
-	if getPwd != nil {
-		if pwd := getPwd(); len(pwd) != 0 {
+		ws:        p.KeyStore,
+		recorder:  p.Recorder,
+		records:   p.Records,
+		mw:        p.Middleware,
+		bus:       p.Bus,
+		filter:    p.Filter,
+		approval:  p.Approval,
+		quorum:    p.Quorum,
+		limiter:   p.Limiter,
+		blind:     p.BlindSign,
+		meta:      p.KeyMeta,
+		panics:    p.Panics,
+		totp:      p.TOTP,
+		exportCfg: p.ExportCfg,
+		keyCache:  make(map[string]crypto.PrivateKey),
+		events:    newEventHub(),
+	}
+	if p.GetPwd != nil {
+		if pwd := p.GetPwd(); len(pwd) != 0 {
 			if err := w.SetPassword(context.Background(), pwd); err != nil {
 				log.Fatalf("set password(%s) failed %v", pwd, err)
 			}
@@ -58,11 +124,21 @@ func NewWallet(ks storage.KeyStore, rd storage.IRecorder, mw storage.KeyMiddlewa
 	return w
 }
 
-func (w *wallet) SetPassword(ctx context.Context, password string) error {
+func (w *wallet) SetPassword(ctx context.Context, password string) (err error) {
+	defer func() { w.audit(ctx, storage.AdminSetPassword, "", err) }()
 	if err := w.checkPassword(ctx, password); err != nil {
 		return err
 	}
-	return w.mw.SetPassword(ctx, password)
+	if err := w.checkTOTP(ctx, aes.Keccak256([]byte(password))); err != nil {
+		return err
+	}
+	finish, err := w.quorum.Wait(ctx, storage.QuorumSetPassword, "")
+	if err != nil {
+		return err
+	}
+	err = w.mw.SetPassword(ctx, password)
+	finish(err)
+	return err
 }
 
 func (w *wallet) checkPassword(ctx context.Context, password string) error {
@@ -85,14 +161,37 @@ func (w *wallet) checkPassword(ctx context.Context, password string) error {
 	return nil
 }
 
-func (w *wallet) Unlock(ctx context.Context, password string) error {
+func (w *wallet) Unlock(ctx context.Context, password string) (err error) {
+	panicked := w.panicked.Load()
+	defer func() {
+		w.audit(ctx, storage.AdminUnlock, "", err)
+		if panicked {
+			w.audit(ctx, storage.AdminPanicUnlock, "", err)
+		}
+		if err == nil {
+			w.event(ctx, EventUnlock, address.Undef, nil)
+		}
+	}()
 	if err := w.checkPassword(ctx, password); err != nil {
 		return err
 	}
-	return w.mw.Unlock(ctx, password)
+	if err := w.checkTOTP(ctx, aes.Keccak256([]byte(password))); err != nil {
+		return err
+	}
+	if err := w.mw.Unlock(ctx, password); err != nil {
+		return err
+	}
+	w.panicRecovered()
+	return nil
 }
 
-func (w *wallet) Lock(ctx context.Context, password string) error {
+func (w *wallet) Lock(ctx context.Context, password string) (err error) {
+	defer func() {
+		w.audit(ctx, storage.AdminLock, "", err)
+		if err == nil {
+			w.event(ctx, EventLock, address.Undef, nil)
+		}
+	}()
 	return w.mw.Lock(ctx, password)
 }
 
@@ -101,10 +200,15 @@ func (w *wallet) LockState(ctx context.Context) bool {
 }
 
 func (w *wallet) WalletNew(ctx context.Context, kt types.KeyType) (address.Address, error) {
+	return w.WalletNewWithOptions(ctx, kt, KeyOptions{})
+}
+
+func (w *wallet) WalletNewWithOptions(ctx context.Context, kt types.KeyType, opts KeyOptions) (addr address.Address, err error) {
+	defer func() { w.audit(ctx, storage.AdminWalletNew, addrTarget(addr), err) }()
 	if err := w.mw.Next(); err != nil {
 		return address.Undef, err
 	}
-	err := w.mw.CheckToken(ctx)
+	err = w.mw.CheckToken(ctx)
 	if err != nil {
 		return address.Undef, err
 	}
@@ -112,7 +216,7 @@ func (w *wallet) WalletNew(ctx context.Context, kt types.KeyType) (address.Addre
 	if err != nil {
 		return address.Undef, err
 	}
-	addr, err := prv.Address()
+	addr, err = prv.Address()
 	if err != nil {
 		return address.Undef, err
 	}
@@ -120,10 +224,14 @@ func (w *wallet) WalletNew(ctx context.Context, kt types.KeyType) (address.Addre
 	if err != nil {
 		return address.Undef, err
 	}
+	if err := w.applyKeyOptions(addr, opts); err != nil {
+		return address.Undef, err
+	}
 	err = w.ws.Put(ckey)
 	if err != nil {
 		return address.Undef, err
 	}
+	w.event(ctx, EventKeyAdd, addr, nil)
 	// notify
 	w.bus.Publish("wallet:add_address", addr)
 	return addr, nil
@@ -142,76 +250,252 @@ func (w *wallet) WalletList(ctx context.Context) ([]address.Address, error) {
 }
 
 func (w *wallet) WalletSign(ctx context.Context, signer address.Address, data []byte, meta types.MsgMeta) (*c.Signature, error) {
+	req := &signRequest{start: time.Now(), signer: signer, meta: meta}
 	if err := w.mw.Next(); err != nil {
+		w.signEvent(ctx, EventSignFailure, req, err)
 		return nil, err
 	}
 
-	// parse msg
-	signObj, toSign, err := w_types.GetSignBytesAndObj(data, meta)
+	signObj, toSign, err := w.parseSignMsg(signer, data, meta)
 	if err != nil {
-		return nil, fmt.Errorf("get sign bytes: %w", err)
+		w.signEvent(ctx, EventSignFailure, req, err)
+		return nil, err
 	}
+	req.obj, req.toSign = signObj, toSign
 
-	// check owner
-	if meta.Type == types.MTChainMsg {
-		if signer != signObj.(*types.Message).From {
-			return nil, fmt.Errorf("signer(%s) is not msg sender(%s)", signer, signObj.(*types.Message).From)
-		}
+	// unknown signers are refused before the rate limiter keeps a bucket for them
+	if err := w.checkKeyExists(signer); err != nil {
+		w.record(ctx, req, nil, err)
+		w.signEvent(ctx, EventSignFailure, req, err)
+		return nil, err
+	}
 
-		// Use the data passed directly, because the message of f4 address is not signed for cid.
-		// https://github.com/filecoin-project/venus/blob/master/venus-shared/actors/types/message.go#L228
-		toSign = data
+	if err := w.checkDisabled(signer); err != nil {
+		w.record(ctx, req, nil, err)
+		w.signEvent(ctx, EventSignRejected, req, err)
+		return nil, err
+	}
+
+	// check rate limit
+	if err := w.limiter.Allow(ctx, signer, meta.Type); err != nil {
+		w.record(ctx, req, nil, err)
+		w.signEvent(ctx, EventSignRejected, req, err)
+		return nil, err
 	}
 
 	// check filter
 	if meta.Type != types.MTVerifyAddress {
-		err = w.filter.CheckSignMsg(ctx, SignMsg{
+		signMsg := SignMsg{
 			SignType: meta.Type,
+			Signer:   signer,
 			Data:     signObj,
-		})
+			Caller:   middleware.CallerFromContext(ctx),
+		}
+		err = w.filter.CheckSignMsg(ctx, signMsg)
 		if err != nil {
+			w.record(ctx, req, nil, err)
+			w.signEvent(ctx, EventSignRejected, req, err)
 			return nil, err
 		}
+
+		// wait for approval
+		if reason := w.approval.Match(signer, signMsg); reason != "" {
+			msg, err := cborutil.Dump(signObj)
+			if err != nil {
+				err = fmt.Errorf("dump signObj: %w", err)
+				w.record(ctx, req, nil, err)
+				w.signEvent(ctx, EventSignFailure, req, err)
+				return nil, err
+			}
+			if err := w.approval.Wait(ctx, signer, meta.Type, msg, reason); err != nil {
+				w.record(ctx, req, nil, err)
+				w.signEvent(ctx, EventSignRejected, req, err)
+				return nil, err
+			}
+			// the wallet may be locked while waiting
+			if err := w.mw.Next(); err != nil {
+				w.record(ctx, req, nil, err)
+				w.signEvent(ctx, EventSignFailure, req, err)
+				return nil, err
+			}
+		}
 	}
 
 	// sign
-	prvKey := w.cacheKey(signer)
-	if prvKey == nil {
-		key, err := w.ws.Get(signer)
-		if err != nil {
-			return nil, err
-		}
-		prvKey, err = w.mw.Decrypt(storage.EmptyPassword, key)
-		if err != nil {
-			return nil, err
-		}
-		w.pushCache(signer, prvKey)
+	prvKey, err := w.privateKey(signer)
+	if err != nil {
+		w.record(ctx, req, nil, err)
+		w.signEvent(ctx, EventSignFailure, req, err)
+		return nil, err
 	}
 	signature, signErr := prvKey.Sign(toSign)
+	if signErr == nil {
+		signErr = w.verifySignature(ctx, signer, meta.Type, signature, toSign)
+	}
 
-	// record
-	go func() {
-		msg, err := cborutil.Dump(signObj)
-		if err != nil {
-			log.Errorf("dump signObj failed %v", err)
-		}
+	w.record(ctx, req, signature, signErr)
+	if signErr != nil {
+		w.signEvent(ctx, EventSignFailure, req, signErr)
+		return nil, signErr
+	}
+	w.signEvent(ctx, EventSignSuccess, req, nil)
+	return signature, nil
+}
 
-		err = w.recorder.Record(&storage.SignRecord{
-			ID:     uuid.New().String(),
-			Type:   meta.Type,
-			Signer: signer,
-			RawMsg: msg,
-			Err:    signErr,
-		})
-		if err != nil {
-			log.Errorf("record sign failed: %v", err)
+// checkKeyExists the wallet holds the key of addr, without decrypting it
+func (w *wallet) checkKeyExists(addr address.Address) error {
+	if w.cacheKey(addr) != nil {
+		return nil
+	}
+	has, err := w.ws.Has(addr)
+	if err != nil {
+		return err
+	}
+	if !has {
+		return fmt.Errorf("%w: %s", storage.ErrKeyInfoNotFound, addr)
+	}
+	return nil
+}
+
+// privateKey the decrypted key, from the cache if it's there
+func (w *wallet) privateKey(addr address.Address) (crypto.PrivateKey, error) {
+	if prvKey := w.cacheKey(addr); prvKey != nil {
+		return prvKey, nil
+	}
+	key, err := w.ws.Get(addr)
+	if err != nil {
+		return nil, err
+	}
+	prvKey, err := w.mw.Decrypt(storage.EmptyPassword, key)
+	if err != nil {
+		return nil, err
+	}
+	w.pushCache(addr, prvKey)
+	return prvKey, nil
+}
+
+// verifySignature checks the produced signature, hardware faults or bugs in the crypto libraries
+// may produce bad signatures, a bad block signature costs the block reward
+func (w *wallet) verifySignature(ctx context.Context, signer address.Address, msgType types.MsgType, signature *c.Signature, toSign []byte) error {
+	err := crypto.Verify(signature, signer, toSign)
+	if err == nil {
+		return nil
+	}
+	_ = stats.RecordWithTags(ctx, []tag.Mutator{
+		tag.Upsert(middleware.Signer, signer.String()),
+		tag.Upsert(middleware.MsgType, string(msgType)),
+	}, middleware.SignVerifyFailed.M(1))
+	log.Errorf("!!! SIGNATURE VERIFICATION FAILED: signer %s, type %s: %v. The signature is discarded, "+
+		"check the hardware and the crypto libraries !!!", signer, msgType, err)
+	// the cached key may be corrupted in memory, decrypt it again next time
+	w.pullCache(signer)
+	return fmt.Errorf("%w: %s: %v", ErrSignatureMismatch, signer, err)
+}
+
+// parseSignMsg returns the object to sign and the bytes actually signed
+func (w *wallet) parseSignMsg(signer address.Address, data []byte, meta types.MsgMeta) (interface{}, []byte, error) {
+	// parse msg
+	signObj, toSign, err := w_types.GetSignBytesAndObj(data, meta)
+	if err != nil {
+		return nil, nil, fmt.Errorf("get sign bytes: %w", err)
+	}
+
+	// check owner
+	if meta.Type == types.MTChainMsg {
+		if signer != signObj.(*types.Message).From {
+			return nil, nil, fmt.Errorf("signer(%s) is not msg sender(%s)", signer, signObj.(*types.Message).From)
 		}
-	}()
 
-	return signature, signErr
+		// Use the data passed directly, because the message of f4 address is not signed for cid.
+		// https://github.com/filecoin-project/venus/blob/master/venus-shared/actors/types/message.go#L228
+		toSign = data
+	}
+
+	// separate arbitrary data from the filecoin objects
+	if meta.Type == types.MTUnknown {
+		toSign = w.blind.SignBytes(toSign)
+	}
+	return signObj, toSign, nil
+}
+
+// signRequest what is known about a sign request, kept in the sign record
+type signRequest struct {
+	start  time.Time
+	signer address.Address
+	meta   types.MsgMeta
+	obj    interface{}
+	toSign []byte
+}
+
+// record queues the sign record, it's written in the background
+func (w *wallet) record(ctx context.Context, req *signRequest, signature *c.Signature, signErr error) {
+	record := &storage.SignRecord{
+		ID:        uuid.New().String(),
+		CreateAt:  time.Now(),
+		Type:      req.meta.Type,
+		Signer:    req.signer,
+		Signature: signature,
+		Caller:    middleware.CallerFromContext(ctx),
+		Extra:     req.meta.Extra,
+		ToSign:    req.toSign,
+		Duration:  time.Since(req.start),
+	}
+	record.Summary, record.MsgCID = signSummary(req.obj)
+	// the bytes signed are kept anyway, so the record still proves what was signed
+	msg, err := cborutil.Dump(req.obj)
+	if err != nil {
+		log.Errorf("dump signObj failed %v", err)
+		record.Summary = fmt.Sprintf("%s (dump sign object: %v)", record.Summary, err)
+	} else {
+		record.RawMsg = msg
+	}
+	if signErr != nil {
+		record.Err = signErr.Error()
+	}
+	w.records.Push(ctx, record)
+}
+
+// audit records an administrative operation, whether it succeeded or not
+func (w *wallet) audit(ctx context.Context, op storage.AdminOp, target string, opErr error) {
+	w.auditDetail(ctx, op, target, "", opErr)
 }
 
-func (w *wallet) WalletExport(ctx context.Context, addr address.Address) (*types.KeyInfo, error) {
+func (w *wallet) auditDetail(ctx context.Context, op storage.AdminOp, target, detail string, opErr error) {
+	if err := w.recorder.RecordAdmin(storage.NewAdminRecord(ctx, op, target, detail, opErr)); err != nil {
+		log.Errorf("record %s failed: %v", op, err)
+	}
+}
+
+// addrTarget the address as the target of an admin record, empty if the operation failed before it was known
+func addrTarget(addr address.Address) string {
+	if addr == address.Undef {
+		return ""
+	}
+	return addr.String()
+}
+
+func (w *wallet) WalletExport(ctx context.Context, addr address.Address) (_ *types.KeyInfo, err error) {
+	defer func() { w.audit(ctx, storage.AdminWalletExport, addr.String(), err) }()
+	if err := w.mw.Next(); err != nil {
+		return nil, err
+	}
+	if err := w.checkExport(addr); err != nil {
+		return nil, err
+	}
+	if err := w.checkTOTP(ctx, nil); err != nil {
+		return nil, err
+	}
+	finish, err := w.quorum.Wait(ctx, storage.QuorumExport, addr.String())
+	if err != nil {
+		return nil, err
+	}
+	ki, err := w.walletExport(addr)
+	finish(err)
+	return ki, err
+}
+
+func (w *wallet) walletExport(addr address.Address) (*types.KeyInfo, error) {
+	// the wallet may be locked while waiting
 	if err := w.mw.Next(); err != nil {
 		return nil, err
 	}
@@ -227,10 +511,15 @@ func (w *wallet) WalletExport(ctx context.Context, addr address.Address) (*types
 }
 
 func (w *wallet) WalletImport(ctx context.Context, ki *types.KeyInfo) (address.Address, error) {
+	return w.WalletImportWithOptions(ctx, ki, KeyOptions{})
+}
+
+func (w *wallet) WalletImportWithOptions(ctx context.Context, ki *types.KeyInfo, opts KeyOptions) (addr address.Address, err error) {
+	defer func() { w.audit(ctx, storage.AdminWalletImport, addrTarget(addr), err) }()
 	if err := w.mw.Next(); err != nil {
 		return address.Undef, err
 	}
-	err := w.mw.CheckToken(ctx)
+	err = w.mw.CheckToken(ctx)
 	if err != nil {
 		return address.Undef, err
 	}
@@ -238,7 +527,7 @@ func (w *wallet) WalletImport(ctx context.Context, ki *types.KeyInfo) (address.A
 	if err != nil {
 		return address.Undef, err
 	}
-	addr, err := pk.Address()
+	addr, err = pk.Address()
 	if err != nil {
 		return address.Undef, err
 	}
@@ -247,33 +536,60 @@ func (w *wallet) WalletImport(ctx context.Context, ki *types.KeyInfo) (address.A
 		return address.Undef, err
 	}
 	if exist {
-		return addr, nil
+		return addr, w.applyKeyOptions(addr, opts)
 	}
 	key, err := w.mw.Encrypt(storage.EmptyPassword, pk)
 	if err != nil {
 		return address.Undef, err
 	}
+	if err := w.applyKeyOptions(addr, opts); err != nil {
+		return address.Undef, err
+	}
 	err = w.ws.Put(key)
 	if err != nil {
 		return address.Undef, err
 	}
+	w.event(ctx, EventKeyAdd, addr, nil)
 	// notify
 	w.bus.Publish("wallet:add_address", addr)
 	return addr, nil
 }
 
-func (w *wallet) WalletDelete(ctx context.Context, addr address.Address) error {
+func (w *wallet) WalletDelete(ctx context.Context, addr address.Address) (err error) {
+	defer func() { w.audit(ctx, storage.AdminWalletDelete, addr.String(), err) }()
 	if err := w.mw.Next(); err != nil {
 		return err
 	}
-	err := w.mw.CheckToken(ctx)
+	err = w.mw.CheckToken(ctx)
 	if err != nil {
 		return err
 	}
-	err = w.ws.Delete(addr)
+	if err := w.checkTOTP(ctx, nil); err != nil {
+		return err
+	}
+	finish, err := w.quorum.Wait(ctx, storage.QuorumDelete, addr.String())
 	if err != nil {
 		return err
 	}
+	err = w.walletDelete(addr)
+	finish(err)
+	if err == nil {
+		w.event(ctx, EventKeyRemove, addr, nil)
+	}
+	return err
+}
+
+func (w *wallet) walletDelete(addr address.Address) error {
+	if err := w.mw.Next(); err != nil {
+		return err
+	}
+	err := w.ws.Delete(addr)
+	if err != nil {
+		return err
+	}
+	if err := w.meta.DeleteMeta(addr); err != nil {
+		log.Warnf("delete meta of %s: %v", addr, err)
+	}
 	w.pullCache(addr)
 	w.bus.Publish("wallet:remove_address", addr)
 	return nil
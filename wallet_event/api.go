@@ -7,6 +7,7 @@ import (
 	"github.com/filecoin-project/go-address"
 	"github.com/filecoin-project/venus-wallet/config"
 	"github.com/filecoin-project/venus-wallet/filemgr"
+	"github.com/filecoin-project/venus-wallet/storage"
 	"github.com/filecoin-project/venus/venus-shared/api/wallet"
 )
 
@@ -14,24 +15,32 @@ type WalletEventAPI struct {
 	cfg            *config.APIRegisterHubConfig
 	apiRegisterHub IAPIRegisterHub
 	fsr            filemgr.Repo
+	recorder       storage.IRecorder
 }
 
-func NewWalletEventAPI(fsr filemgr.Repo, cfg *config.APIRegisterHubConfig, apiRegisterHub IAPIRegisterHub) wallet.IWalletEvent {
+func NewWalletEventAPI(fsr filemgr.Repo, cfg *config.APIRegisterHubConfig, apiRegisterHub IAPIRegisterHub, recorder storage.IRecorder) wallet.IWalletEvent {
 	return &WalletEventAPI{
 		fsr:            fsr,
 		cfg:            cfg,
 		apiRegisterHub: apiRegisterHub,
+		recorder:       recorder,
 	}
 }
 
-func (walletEventAPI *WalletEventAPI) AddSupportAccount(ctx context.Context, supportAccount string) error {
+func (walletEventAPI *WalletEventAPI) AddSupportAccount(ctx context.Context, supportAccount string) (err error) {
+	defer func() {
+		record := storage.NewAdminRecord(ctx, storage.AdminSupportAccount, supportAccount, "", err)
+		if rerr := walletEventAPI.recorder.RecordAdmin(record); rerr != nil {
+			log.Errorf("record support account failed: %v", rerr)
+		}
+	}()
 	for _, account := range walletEventAPI.cfg.SupportAccounts {
 		if account == supportAccount {
 			return fmt.Errorf("account %s has exit", supportAccount)
 		}
 	}
 
-	err := walletEventAPI.apiRegisterHub.SupportNewAccount(ctx, supportAccount)
+	err = walletEventAPI.apiRegisterHub.SupportNewAccount(ctx, supportAccount)
 	if err != nil {
 		return err
 	}
@@ -12,6 +12,8 @@ import (
 	"go.uber.org/fx"
 
 	"github.com/filecoin-project/venus-wallet/config"
+	"github.com/filecoin-project/venus-wallet/middleware"
+	"github.com/filecoin-project/venus-wallet/storage"
 	"github.com/ipfs-force-community/sophon-gateway/types"
 	"github.com/ipfs-force-community/sophon-gateway/walletevent"
 )
@@ -31,7 +33,7 @@ type APIRegisterHub struct {
 	supportAccounts []string
 }
 
-func NewAPIRegisterHub(lc fx.Lifecycle, signer types.IWalletHandler, bus EventBus.Bus, cfg *config.APIRegisterHubConfig) (*APIRegisterHub, error) {
+func NewAPIRegisterHub(lc fx.Lifecycle, signer types.IWalletHandler, meta storage.IKeyMetaStore, bus EventBus.Bus, cfg *config.APIRegisterHubConfig) (*APIRegisterHub, error) {
 	apiRegister := &APIRegisterHub{
 		weClient:        make(map[string]*walletevent.WalletEventClient),
 		bus:             bus,
@@ -55,7 +57,14 @@ func NewAPIRegisterHub(lc fx.Lifecycle, signer types.IWalletHandler, bus EventBu
 			return nil, err
 		}
 		mLog := log.With("api hub", apiHub)
-		walletEvent := walletevent.NewWalletEventClient(ctx, signer, walletEventClient, mLog, func() []string {
+		hubSigner := &gatewaySigner{
+			IWalletHandler:  signer,
+			meta:            meta,
+			gateway:         apiHub,
+			tokenID:         middleware.TokenID(cfg.Token),
+			supportAccounts: apiRegister.getSupportAccounts,
+		}
+		walletEvent := walletevent.NewWalletEventClient(ctx, hubSigner, walletEventClient, mLog, func() []string {
 			return apiRegister.supportAccounts
 		})
 		go walletEvent.ListenWalletRequest(ctx)
@@ -89,6 +98,12 @@ func NewAPIRegisterHub(lc fx.Lifecycle, signer types.IWalletHandler, bus EventBu
 	return apiRegister, nil
 }
 
+func (h *APIRegisterHub) getSupportAccounts() []string {
+	h.lk.Lock()
+	defer h.lk.Unlock()
+	return append([]string(nil), h.supportAccounts...)
+}
+
 func (h *APIRegisterHub) SupportNewAccount(ctx context.Context, supportAccount string) error {
 	h.lk.Lock()
 	defer h.lk.Unlock()
@@ -0,0 +1,51 @@
+package wallet_event
+
+import (
+	"context"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/crypto"
+	sharedTypes "github.com/filecoin-project/venus/venus-shared/types"
+	"github.com/ipfs-force-community/sophon-gateway/types"
+
+	"github.com/filecoin-project/venus-wallet/middleware"
+	"github.com/filecoin-project/venus-wallet/storage"
+)
+
+// gatewaySigner marks the sign requests arriving from a gateway with the gateway as the caller,
+// as the requests carry no identity of their own
+type gatewaySigner struct {
+	types.IWalletHandler
+	meta            storage.IKeyMetaStore
+	gateway         string
+	tokenID         string
+	supportAccounts func() []string
+}
+
+// WalletList hides the disabled keys from the gateway
+func (s *gatewaySigner) WalletList(ctx context.Context) ([]address.Address, error) {
+	addrs, err := s.IWalletHandler.WalletList(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]address.Address, 0, len(addrs))
+	for _, addr := range addrs {
+		meta, err := s.meta.GetMeta(addr)
+		if err != nil {
+			return nil, err
+		}
+		if !meta.Disabled {
+			ret = append(ret, addr)
+		}
+	}
+	return ret, nil
+}
+
+func (s *gatewaySigner) WalletSign(ctx context.Context, signer address.Address, toSign []byte, meta sharedTypes.MsgMeta) (*crypto.Signature, error) {
+	ctx = middleware.WithCaller(ctx, &middleware.Caller{
+		TokenID:         s.tokenID,
+		Gateway:         s.gateway,
+		SupportAccounts: s.supportAccounts(),
+	})
+	return s.IWalletHandler.WalletSign(ctx, signer, toSign, meta)
+}